@@ -0,0 +1,664 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mailgun/errors"
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultListPageSize = 100
+
+func (m *RateLimitKey) HashKey() string {
+	return m.Name + "_" + m.UniqueKey
+}
+
+// InspectRateLimits returns the current state of the requested rate limits from their owning
+// peers without consuming any hits.
+func (s *V1Instance) InspectRateLimits(ctx context.Context, r *InspectRateLimitsReq) (*InspectRateLimitsResp, error) {
+	defer prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("V1Instance.InspectRateLimits")).ObserveDuration()
+
+	if len(r.Keys) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"Requests.Keys list too large; max size is '%d'", maxBatchSize)
+	}
+
+	states := make([]*RateLimitState, len(r.Keys))
+	for peer, idx := range s.groupByOwner(ctx, r.Keys, states) {
+		if peer.Info().IsOwner {
+			for _, i := range idx {
+				states[i] = s.inspectLocal(ctx, r.Keys[i])
+			}
+			continue
+		}
+
+		req := &InspectRateLimitsReq{Keys: subsetOf(r.Keys, idx)}
+		resp, err := peer.InspectPeerRateLimits(ctx, req)
+		if err != nil {
+			err = errors.Wrapf(err, "while inspecting rate limits on peer '%s'", peer.Info().GRPCAddress)
+			setPeerStates(states, idx, r.Keys, nil, err)
+			continue
+		}
+		setPeerStates(states, idx, r.Keys, resp.States, nil)
+	}
+	return &InspectRateLimitsResp{States: states}, nil
+}
+
+// DeleteRateLimits removes the state of the requested rate limits from their owning peers and
+// the persistent store. Returns the state of each rate limit before it was deleted.
+func (s *V1Instance) DeleteRateLimits(ctx context.Context, r *DeleteRateLimitsReq) (*DeleteRateLimitsResp, error) {
+	defer prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("V1Instance.DeleteRateLimits")).ObserveDuration()
+
+	if len(r.Keys) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"Requests.Keys list too large; max size is '%d'", maxBatchSize)
+	}
+
+	states := make([]*RateLimitState, len(r.Keys))
+	for peer, idx := range s.groupByOwner(ctx, r.Keys, states) {
+		if peer.Info().IsOwner {
+			for _, i := range idx {
+				states[i] = s.deleteLocal(ctx, r.Keys[i])
+			}
+			continue
+		}
+
+		req := &DeleteRateLimitsReq{Keys: subsetOf(r.Keys, idx)}
+		resp, err := peer.DeletePeerRateLimits(ctx, req)
+		if err != nil {
+			err = errors.Wrapf(err, "while deleting rate limits on peer '%s'", peer.Info().GRPCAddress)
+			setPeerStates(states, idx, r.Keys, nil, err)
+			continue
+		}
+		setPeerStates(states, idx, r.Keys, resp.States, nil)
+	}
+	return &DeleteRateLimitsResp{States: states}, nil
+}
+
+// SetRateLimits overwrites the state of the requested rate limits on their owning peers and in
+// the persistent store. Returns the state of each rate limit after it was overwritten.
+func (s *V1Instance) SetRateLimits(ctx context.Context, r *SetRateLimitsReq) (*SetRateLimitsResp, error) {
+	defer prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("V1Instance.SetRateLimits")).ObserveDuration()
+
+	if len(r.States) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"Requests.States list too large; max size is '%d'", maxBatchSize)
+	}
+
+	keys := make([]*RateLimitKey, len(r.States))
+	for i, state := range r.States {
+		keys[i] = &RateLimitKey{Name: state.Name, UniqueKey: state.UniqueKey}
+	}
+
+	states := make([]*RateLimitState, len(r.States))
+	for peer, idx := range s.groupByOwner(ctx, keys, states) {
+		if peer.Info().IsOwner {
+			for _, i := range idx {
+				states[i] = s.setLocal(ctx, r.States[i])
+			}
+			continue
+		}
+
+		req := &SetRateLimitsReq{States: subsetOf(r.States, idx)}
+		resp, err := peer.SetPeerRateLimits(ctx, req)
+		if err != nil {
+			err = errors.Wrapf(err, "while setting rate limits on peer '%s'", peer.Info().GRPCAddress)
+			setPeerStates(states, idx, keys, nil, err)
+			continue
+		}
+		setPeerStates(states, idx, keys, resp.States, nil)
+	}
+	return &SetRateLimitsResp{States: states}, nil
+}
+
+// ListRateLimits returns a page of the rate limits held by every peer in the local cluster which match
+// the provided filters. Each peer returns a page of its own rate limits ordered by key, which are merged
+// such that the page returned holds the first rate limits across the cluster which come after the page token.
+func (s *V1Instance) ListRateLimits(ctx context.Context, r *ListRateLimitsReq) (*ListRateLimitsResp, error) {
+	defer prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("V1Instance.ListRateLimits")).ObserveDuration()
+
+	if r.PageSize > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'ListRateLimitsReq.page_size' too large; max size is '%d'", maxBatchSize)
+	}
+	if _, err := decodePageToken(r.PageToken); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	peers := s.GetPeerList()
+
+	pages := make([]*ListRateLimitsResp, len(peers))
+	errs := make([]error, len(peers))
+	fan := syncutil.NewFanOut(s.conf.Behaviors.GlobalPeerRequestsConcurrency)
+	for i := range peers {
+		fan.Run(func(in interface{}) error {
+			i := in.(int)
+			if peers[i].Info().IsOwner {
+				pages[i], errs[i] = s.listLocal(ctx, r)
+				return nil
+			}
+			pages[i], errs[i] = peers[i].ListPeerRateLimits(ctx, r)
+			return nil
+		}, i)
+	}
+	fan.Wait()
+
+	var states []*RateLimitState
+	var more bool
+	for i, page := range pages {
+		if errs[i] != nil {
+			return nil, errors.Wrapf(errs[i], "while listing rate limits on peer '%s'", peers[i].Info().GRPCAddress)
+		}
+		states = append(states, page.States...)
+		if page.NextPageToken != "" {
+			more = true
+		}
+	}
+	return newListPage(states, listPageSize(r), more), nil
+}
+
+// ListPeerRateLimits is called by other peers to list the rate limits held by this peer.
+func (s *V1Instance) ListPeerRateLimits(ctx context.Context, r *ListRateLimitsReq) (*ListRateLimitsResp, error) {
+	if r.PageSize > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'ListRateLimitsReq.page_size' too large; max size is '%d'", maxBatchSize)
+	}
+	resp, err := s.listLocal(ctx, r)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return resp, nil
+}
+
+// listLocal returns a page of the rate limits owned by this instance. Copies of GLOBAL rate limits
+// and replicas owned by other peers are skipped, as their owner lists them.
+func (s *V1Instance) listLocal(ctx context.Context, r *ListRateLimitsReq) (*ListRateLimitsResp, error) {
+	after, err := decodePageToken(r.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	s.peerMutex.RLock()
+	picker := s.conf.LocalPicker
+	s.peerMutex.RUnlock()
+
+	// Only keep the states which may be on the page, plus one more to know if there is a next page
+	size := listPageSize(r)
+	states := make(listHeap, 0, size+1)
+	now := MillisecondNow()
+	err = s.workerPool.EachCacheItem(ctx, func(item *CacheItem) {
+		if !strings.HasPrefix(item.Key, r.Prefix) || item.Key <= after || item.ExpireAt < now {
+			return
+		}
+		if len(states) > size && item.Key >= states[0].Key {
+			return
+		}
+		if owner, err := picker.Get(item.Key); err != nil || !owner.Info().IsOwner {
+			return
+		}
+		state := &RateLimitState{Key: item.Key}
+		setCacheItemState(state, item, nil, now)
+		if r.OverLimit && state.Status != Status_OVER_LIMIT {
+			return
+		}
+		heap.Push(&states, state)
+		if len(states) > size+1 {
+			heap.Pop(&states)
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error in workerPool.EachCacheItem")
+	}
+	return newListPage(states, size, false), nil
+}
+
+// listHeap holds the states with the smallest keys seen so far, with the largest key on top
+// such that it is the first state dropped when a smaller key is found.
+type listHeap []*RateLimitState
+
+func (h listHeap) Len() int            { return len(h) }
+func (h listHeap) Less(i, j int) bool  { return h[i].Key > h[j].Key }
+func (h listHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *listHeap) Push(x interface{}) { *h = append(*h, x.(*RateLimitState)) }
+func (h *listHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// newListPage sorts the states by key and returns the first page of them. If several peers
+// returned the same key, only the first is kept. The page has a next page token if there are
+// more states than fit on the page, or if `more` is true.
+func newListPage(states []*RateLimitState, size int, more bool) *ListRateLimitsResp {
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].Key < states[j].Key
+	})
+	unique := states[:0]
+	for _, state := range states {
+		if len(unique) == 0 || state.Key != unique[len(unique)-1].Key {
+			unique = append(unique, state)
+		}
+	}
+	states = unique
+
+	if len(states) > size {
+		states = states[:size]
+		more = true
+	}
+
+	resp := &ListRateLimitsResp{States: states}
+	if more && len(states) != 0 {
+		resp.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(states[len(states)-1].Key))
+	}
+	return resp
+}
+
+// decodePageToken returns the key of the last rate limit of the previous page
+func decodePageToken(token string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", errors.New("field 'page_token' is invalid")
+	}
+	return string(b), nil
+}
+
+func listPageSize(r *ListRateLimitsReq) int {
+	if r.PageSize <= 0 {
+		return defaultListPageSize
+	}
+	return int(r.PageSize)
+}
+
+// InspectPeerRateLimits is called by other peers to inspect the rate limits owned by this peer.
+func (s *V1Instance) InspectPeerRateLimits(ctx context.Context, r *InspectRateLimitsReq) (*InspectRateLimitsResp, error) {
+	if len(r.Keys) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'InspectRateLimitsReq.keys' list too large; max size is '%d'", maxBatchSize)
+	}
+
+	resp := &InspectRateLimitsResp{States: make([]*RateLimitState, len(r.Keys))}
+	for i, key := range r.Keys {
+		resp.States[i] = s.inspectLocal(ctx, key)
+	}
+	return resp, nil
+}
+
+// DeletePeerRateLimits is called by other peers to delete the rate limits owned by this peer.
+func (s *V1Instance) DeletePeerRateLimits(ctx context.Context, r *DeleteRateLimitsReq) (*DeleteRateLimitsResp, error) {
+	if len(r.Keys) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'DeleteRateLimitsReq.keys' list too large; max size is '%d'", maxBatchSize)
+	}
+
+	resp := &DeleteRateLimitsResp{States: make([]*RateLimitState, len(r.Keys))}
+	for i, key := range r.Keys {
+		resp.States[i] = s.deleteLocal(ctx, key)
+	}
+	return resp, nil
+}
+
+// SetPeerRateLimits is called by other peers to overwrite the rate limits owned by this peer.
+func (s *V1Instance) SetPeerRateLimits(ctx context.Context, r *SetRateLimitsReq) (*SetRateLimitsResp, error) {
+	if len(r.States) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'SetRateLimitsReq.states' list too large; max size is '%d'", maxBatchSize)
+	}
+
+	resp := &SetRateLimitsResp{States: make([]*RateLimitState, len(r.States))}
+	for i, state := range r.States {
+		resp.States[i] = s.setLocal(ctx, state)
+	}
+	return resp, nil
+}
+
+// groupByOwner returns the indexes of the keys grouped by the peer which owns them. Keys which
+// are invalid or whose owner could not be found have an error state assigned in `states`.
+func (s *V1Instance) groupByOwner(ctx context.Context, keys []*RateLimitKey, states []*RateLimitState) map[*PeerClient][]int {
+	owners := make(map[*PeerClient][]int)
+	for i, key := range keys {
+		if len(key.UniqueKey) == 0 {
+			states[i] = &RateLimitState{Name: key.Name, Error: "field 'unique_key' cannot be empty"}
+			continue
+		}
+		if len(key.Name) == 0 {
+			states[i] = &RateLimitState{UniqueKey: key.UniqueKey, Error: "field 'namespace' cannot be empty"}
+			continue
+		}
+
+		peer, err := s.GetPeer(ctx, key.HashKey())
+		if err != nil {
+			err = errors.Wrapf(err, "Error in GetPeer, looking up peer that owns rate limit '%s'", key.HashKey())
+			states[i] = &RateLimitState{Name: key.Name, UniqueKey: key.UniqueKey, Error: err.Error()}
+			continue
+		}
+		owners[peer] = append(owners[peer], i)
+	}
+	return owners
+}
+
+// inspectLocal returns the state of a rate limit owned by this instance
+func (s *V1Instance) inspectLocal(ctx context.Context, key *RateLimitKey) *RateLimitState {
+	state := &RateLimitState{Name: key.Name, UniqueKey: key.UniqueKey}
+
+	item, ok, err := s.workerPool.GetCacheItem(ctx, key.HashKey())
+	if err != nil {
+		state.Error = errors.Wrap(err, "Error in workerPool.GetCacheItem").Error()
+		return state
+	}
+
+	now := MillisecondNow()
+	if !ok && s.conf.StoreV2 != nil {
+		item, ok, err = s.conf.StoreV2.Get(ctx, &RateLimitReq{Name: key.Name, UniqueKey: key.UniqueKey})
+		if err != nil {
+			state.Error = errors.Wrap(err, "Error in Store.Get").Error()
+			return state
+		}
+		if ok && item.ExpireAt < now {
+			ok = false
+		}
+	}
+
+	if !ok {
+		return state
+	}
+
+	config := &RateLimitReq{
+		Name:      key.Name,
+		UniqueKey: key.UniqueKey,
+		Limit:     key.Limit,
+		Duration:  key.Duration,
+		Burst:     key.Burst,
+	}
+	if s.conf.Policies != nil {
+		s.conf.Policies.Apply(config)
+	}
+	setCacheItemState(state, item, config, now)
+	return state
+}
+
+// deleteLocal removes a rate limit owned by this instance, returning its state before it was removed
+func (s *V1Instance) deleteLocal(ctx context.Context, key *RateLimitKey) *RateLimitState {
+	state := s.inspectLocal(ctx, key)
+	if state.Error != "" {
+		return state
+	}
+
+	if err := s.workerPool.RemoveCacheItem(ctx, key.HashKey()); err != nil {
+		state.Error = errors.Wrap(err, "Error in workerPool.RemoveCacheItem").Error()
+		return state
+	}
+
+	if s.conf.StoreV2 != nil {
+		if err := s.conf.StoreV2.Remove(ctx, key.HashKey()); err != nil {
+			state.Error = errors.Wrap(err, "Error in Store.Remove").Error()
+		}
+	}
+	return state
+}
+
+// setLocal overwrites a rate limit owned by this instance, returning its new state
+func (s *V1Instance) setLocal(ctx context.Context, in *RateLimitState) *RateLimitState {
+	state := &RateLimitState{Name: in.Name, UniqueKey: in.UniqueKey}
+	now := MillisecondNow()
+
+	item, err := newCacheItemFromState(in, now)
+	if err != nil {
+		state.Error = err.Error()
+		return state
+	}
+
+	if err := s.workerPool.AddCacheItem(ctx, item.Key, item); err != nil {
+		state.Error = errors.Wrap(err, "Error in workerPool.AddCacheItem").Error()
+		return state
+	}
+
+	if s.conf.StoreV2 != nil {
+		err = s.conf.StoreV2.OnChange(ctx, &RateLimitReq{
+			Name:      in.Name,
+			UniqueKey: in.UniqueKey,
+			Algorithm: in.Algorithm,
+			Limit:     in.Limit,
+			Duration:  in.Duration,
+			Burst:     in.Burst,
+		}, item)
+		if err != nil {
+			state.Error = errors.Wrap(err, "Error in Store.OnChange").Error()
+		}
+	}
+
+	setCacheItemState(state, item, &RateLimitReq{
+		Limit:    in.Limit,
+		Duration: in.Duration,
+		Burst:    in.Burst,
+	}, now)
+	return state
+}
+
+// setCacheItemState fills in the state from the cache item without modifying the item. The limit,
+// duration and burst of GCRA rate limits are taken from `config`, as the item only holds the
+// theoretical arrival time; if `config` is nil only the reset time of a GCRA rate limit is known.
+func setCacheItemState(state *RateLimitState, item *CacheItem, config *RateLimitReq, now int64) {
+	state.Found = true
+	state.Algorithm = item.Algorithm
+	state.ResetTime = item.ExpireAt
+
+	switch v := item.Value.(type) {
+	case *TokenBucketItem:
+		state.Limit = v.Limit
+		state.Duration = v.Duration
+		state.Remaining = v.Remaining
+	case *LeakyBucketItem:
+		state.Limit = v.Limit
+		state.Duration = v.Duration
+		state.Burst = v.Burst
+		state.Remaining = int64(v.Remaining)
+		if v.Limit > 0 && v.Duration > 0 {
+			// Include the hits which leaked out of the bucket since it was updated, as leakyBucket() does
+			rate := float64(v.Duration) / float64(v.Limit)
+			remaining := v.Remaining
+			if leak := float64(now-v.UpdatedAt) / rate; int64(leak) > 0 {
+				remaining += leak
+			}
+			if int64(remaining) > v.Burst {
+				remaining = float64(v.Burst)
+			}
+			state.Remaining = int64(remaining)
+			state.ResetTime = now + (v.Limit-state.Remaining)*int64(rate)
+		}
+	case *SlidingWindowItem:
+		state.Limit = v.Limit
+		state.Duration = v.Duration
+		state.Remaining = v.remaining(now)
+		state.ResetTime = v.resetTime()
+	case *GCRAItem:
+		if config == nil || config.Limit <= 0 || config.Duration <= 0 {
+			return
+		}
+		state.Limit = config.Limit
+		state.Duration = config.Duration
+		state.Burst = config.Burst
+		if state.Burst == 0 {
+			state.Burst = config.Limit
+		}
+		interval, tolerance := gcraInterval(state.Limit, state.Duration, state.Burst)
+		state.Remaining = gcraRemaining(v.TAT, clock.Now().UnixNano(), interval, tolerance)
+	case *ContinuousTokenBucketItem:
+		state.Limit = v.Limit
+		state.Duration = v.Duration
+		state.Burst = v.Burst
+		state.Remaining = int64(v.tokensAt(now, v.Burst))
+	case *ConcurrencyItem:
+		state.Limit = v.Limit
+		state.Remaining = v.Limit
+		state.ResetTime = now
+		for _, l := range v.Leases {
+			if l.ExpireAt <= now {
+				continue
+			}
+			state.Remaining -= l.Count
+			if state.ResetTime == now || l.ExpireAt < state.ResetTime {
+				state.ResetTime = l.ExpireAt
+			}
+		}
+		if state.Remaining < 0 {
+			state.Remaining = 0
+		}
+	default:
+		state.Error = fmt.Sprintf("unknown cache item value type '%T'", item.Value)
+	}
+
+	if state.Remaining == 0 {
+		state.Status = Status_OVER_LIMIT
+	}
+	if t, ok := item.Value.(*TokenBucketItem); ok && t.Status == Status_OVER_LIMIT {
+		state.Status = Status_OVER_LIMIT
+	}
+}
+
+// newCacheItemFromState creates a cache item which holds the provided state
+func newCacheItemFromState(in *RateLimitState, now int64) (*CacheItem, error) {
+	if len(in.UniqueKey) == 0 {
+		return nil, errors.New("field 'unique_key' cannot be empty")
+	}
+	if len(in.Name) == 0 {
+		return nil, errors.New("field 'namespace' cannot be empty")
+	}
+	if in.Limit <= 0 {
+		return nil, errors.New("field 'limit' must be greater than zero")
+	}
+	if in.Duration <= 0 {
+		return nil, errors.New("field 'duration' must be greater than zero")
+	}
+
+	burst := in.Burst
+	if burst == 0 {
+		burst = in.Limit
+	}
+
+	max := in.Limit
+	if in.Algorithm == Algorithm_LEAKY_BUCKET || in.Algorithm == Algorithm_GCRA ||
+		in.Algorithm == Algorithm_CONTINUOUS_TOKEN_BUCKET {
+		max = burst
+	}
+	if in.Remaining < 0 || in.Remaining > max {
+		return nil, fmt.Errorf("field 'remaining' must be between 0 and '%d'", max)
+	}
+
+	item := &CacheItem{
+		Algorithm: in.Algorithm,
+		Key:       in.Name + "_" + in.UniqueKey,
+		ExpireAt:  now + in.Duration,
+	}
+
+	switch in.Algorithm {
+	case Algorithm_TOKEN_BUCKET:
+		if in.ResetTime != 0 {
+			item.ExpireAt = in.ResetTime
+		}
+		t := &TokenBucketItem{
+			Status:    Status_UNDER_LIMIT,
+			Limit:     in.Limit,
+			Duration:  in.Duration,
+			Remaining: in.Remaining,
+			CreatedAt: item.ExpireAt - in.Duration,
+		}
+		if t.Remaining == 0 {
+			t.Status = Status_OVER_LIMIT
+		}
+		item.Value = t
+	case Algorithm_LEAKY_BUCKET:
+		item.Value = &LeakyBucketItem{
+			Limit:     in.Limit,
+			Duration:  in.Duration,
+			Remaining: float64(in.Remaining),
+			UpdatedAt: now,
+			Burst:     burst,
+		}
+	case Algorithm_SLIDING_WINDOW:
+		w := &SlidingWindowItem{
+			Limit:       in.Limit,
+			Duration:    in.Duration,
+			WindowStart: now,
+			Current:     in.Limit - in.Remaining,
+		}
+		item.ExpireAt = w.WindowStart + w.Duration*2
+		item.Value = w
+	case Algorithm_GCRA:
+		interval, tolerance := gcraInterval(in.Limit, in.Duration, burst)
+		g := &GCRAItem{}
+		n := clock.Now().UnixNano()
+		g.TAT = n + tolerance - in.Remaining*interval
+		if g.TAT < n {
+			g.TAT = n
+		}
+		item.ExpireAt = (g.TAT + int64(clock.Millisecond) - 1) / int64(clock.Millisecond)
+		item.Value = g
+	case Algorithm_CONTINUOUS_TOKEN_BUCKET:
+		b := &ContinuousTokenBucketItem{
+			Limit:     in.Limit,
+			Duration:  in.Duration,
+			Burst:     burst,
+			Tokens:    float64(in.Remaining),
+			UpdatedAt: now,
+		}
+		// The bucket is full again at the expiration
+		item.ExpireAt = now + int64(math.Ceil(float64(burst-in.Remaining)*float64(in.Duration)/float64(in.Limit)))
+		item.Value = b
+	case Algorithm_CONCURRENCY:
+		ci := &ConcurrencyItem{Limit: in.Limit}
+		if in.Remaining < in.Limit {
+			ci.Leases = []ConcurrencyLease{{ExpireAt: now + in.Duration, Count: in.Limit - in.Remaining}}
+		}
+		item.Value = ci
+	default:
+		return nil, fmt.Errorf("invalid rate limit algorithm '%d'", in.Algorithm)
+	}
+	return item, nil
+}
+
+// subsetOf returns the items at the provided indexes
+func subsetOf[T any](items []T, idx []int) []T {
+	subset := make([]T, len(idx))
+	for j, i := range idx {
+		subset[j] = items[i]
+	}
+	return subset
+}
+
+// setPeerStates assigns the states returned by a peer to the indexes of the keys they were requested for
+func setPeerStates(states []*RateLimitState, idx []int, keys []*RateLimitKey, peerStates []*RateLimitState, err error) {
+	for j, i := range idx {
+		if err != nil {
+			states[i] = &RateLimitState{Name: keys[i].Name, UniqueKey: keys[i].UniqueKey, Error: err.Error()}
+			continue
+		}
+		states[i] = peerStates[j]
+	}
+}
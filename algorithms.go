@@ -18,12 +18,16 @@ package gubernator
 
 import (
 	"context"
+	"math"
+	"sort"
 
 	"github.com/mailgun/holster/v4/clock"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 // ### NOTE ###
@@ -33,6 +37,25 @@ import (
 // limit, but we do not set the remainder to 0 in the cache. The client can retry within the same window
 // with 100 emails and the request will succeed. You can override this default behavior with `DRAIN_OVER_LIMIT`
 
+// countOverLimit counts an over limit decision. Decisions of SHADOW rate limits are not enforced,
+// and are counted per name by shadowResponse instead.
+func countOverLimit(r *RateLimitReq) {
+	if !HasBehavior(r.Behavior, Behavior_SHADOW) {
+		metricOverLimitCounter.Add(1)
+	}
+}
+
+// withoutRefund returns the request without any refunded hits. Items are created full, and a refund
+// never raises the remaining hits above the limit, so there is nothing to refund to a new item.
+func withoutRefund(r *RateLimitReq) *RateLimitReq {
+	if r.Hits >= 0 {
+		return r
+	}
+	cpy := proto.Clone(r).(*RateLimitReq)
+	cpy.Hits = 0
+	return cpy
+}
+
 // Implements token bucket algorithm for rate limiting. https://en.wikipedia.org/wiki/Token_bucket
 func tokenBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
 
@@ -126,7 +149,7 @@ func tokenBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 			span.AddEvent("Duration changed")
 			expire := t.CreatedAt + r.Duration
 			if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
-				expire, err = GregorianExpiration(clock.Now(), r.Duration)
+				expire, err = gregorianExpiration(clock.Now(), r)
 				if err != nil {
 					return nil, err
 				}
@@ -159,11 +182,24 @@ func tokenBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 			return rl, nil
 		}
 
+		// Client is refunding hits, which never fill the bucket beyond the limit.
+		if r.Hits < 0 {
+			t.Remaining -= r.Hits
+			if t.Remaining > t.Limit {
+				t.Remaining = t.Limit
+			}
+			t.Status = Status_UNDER_LIMIT
+			rl.Status = t.Status
+			rl.Remaining = t.Remaining
+			return rl, nil
+		}
+
 		// If we are already at the limit.
 		if rl.Remaining == 0 && r.Hits > 0 {
 			trace.SpanFromContext(ctx).AddEvent("Already over the limit")
-			metricOverLimitCounter.Add(1)
+			countOverLimit(r)
 			rl.Status = Status_OVER_LIMIT
+			rl.RetryAfter = tokenBucketRetryAfter(r, rl)
 			t.Status = rl.Status
 			return rl, nil
 		}
@@ -180,8 +216,9 @@ func tokenBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 		// without updating the cache.
 		if r.Hits > t.Remaining {
 			trace.SpanFromContext(ctx).AddEvent("Over the limit")
-			metricOverLimitCounter.Add(1)
+			countOverLimit(r)
 			rl.Status = Status_OVER_LIMIT
+			rl.RetryAfter = tokenBucketRetryAfter(r, rl)
 			if HasBehavior(r.Behavior, Behavior_DRAIN_OVER_LIMIT) {
 				// DRAIN_OVER_LIMIT behavior drains the remaining counter.
 				t.Remaining = 0
@@ -201,6 +238,7 @@ func tokenBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 
 // Called by tokenBucket() when adding a new item in the store.
 func tokenBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	r = withoutRefund(r)
 	now := MillisecondNow()
 	expire := now + r.Duration
 
@@ -213,7 +251,7 @@ func tokenBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq)
 
 	// Add a new rate limit to the cache.
 	if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
-		expire, err = GregorianExpiration(clock.Now(), r.Duration)
+		expire, err = gregorianExpiration(clock.Now(), r)
 		if err != nil {
 			return nil, err
 		}
@@ -236,7 +274,7 @@ func tokenBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq)
 	// Client could be requesting that we always return OVER_LIMIT.
 	if r.Hits > r.Limit {
 		trace.SpanFromContext(ctx).AddEvent("Over the limit")
-		metricOverLimitCounter.Add(1)
+		countOverLimit(r)
 		rl.Status = Status_OVER_LIMIT
 		rl.Remaining = r.Limit
 		t.Remaining = r.Limit
@@ -251,6 +289,18 @@ func tokenBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq)
 	return rl, nil
 }
 
+// tokenBucketRetryAfter returns how long to wait until the bucket is refilled at the end of the window
+func tokenBucketRetryAfter(r *RateLimitReq, rl *RateLimitResp) int64 {
+	// The hits will never be accepted
+	if r.Hits > r.Limit {
+		return 0
+	}
+	if wait := rl.ResetTime - MillisecondNow(); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
 // Implements leaky bucket algorithm for rate limiting https://en.wikipedia.org/wiki/Leaky_bucket
 func leakyBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
 	leakyBucketTimer := prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("V1Instance.getRateLimit_leakyBucket"))
@@ -331,12 +381,12 @@ func leakyBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 		rate := float64(duration) / float64(r.Limit)
 
 		if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
-			d, err := GregorianDuration(clock.Now(), r.Duration)
+			d, err := gregorianDuration(clock.Now(), r)
 			if err != nil {
 				return nil, err
 			}
 			n := clock.Now()
-			expire, err := GregorianExpiration(n, r.Duration)
+			expire, err := gregorianExpiration(n, r)
 			if err != nil {
 				return nil, err
 			}
@@ -380,10 +430,22 @@ func leakyBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 			}()
 		}
 
+		// Client is refunding hits, which never fill the bucket beyond the burst.
+		if r.Hits < 0 {
+			b.Remaining -= float64(r.Hits)
+			if b.Remaining > float64(b.Burst) {
+				b.Remaining = float64(b.Burst)
+			}
+			rl.Remaining = int64(b.Remaining)
+			rl.ResetTime = now + (rl.Limit-rl.Remaining)*int64(rate)
+			return rl, nil
+		}
+
 		// If we are already at the limit
 		if int64(b.Remaining) == 0 && r.Hits > 0 {
-			metricOverLimitCounter.Add(1)
+			countOverLimit(r)
 			rl.Status = Status_OVER_LIMIT
+			rl.RetryAfter = leakyBucketRetryAfter(r, b, now, rate)
 			return rl, nil
 		}
 
@@ -398,7 +460,7 @@ func leakyBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 		// If requested is more than available, then return over the limit
 		// without updating the bucket, unless `DRAIN_OVER_LIMIT` is set.
 		if r.Hits > int64(b.Remaining) {
-			metricOverLimitCounter.Add(1)
+			countOverLimit(r)
 			rl.Status = Status_OVER_LIMIT
 
 			// DRAIN_OVER_LIMIT behavior drains the remaining counter.
@@ -406,6 +468,7 @@ func leakyBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 				b.Remaining = 0
 				rl.Remaining = 0
 			}
+			rl.RetryAfter = leakyBucketRetryAfter(r, b, now, rate)
 
 			return rl, nil
 		}
@@ -424,14 +487,29 @@ func leakyBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *
 	return leakyBucketNewItem(ctx, s, c, r)
 }
 
+// leakyBucketRetryAfter returns how long to wait until enough hits have leaked out of the bucket
+func leakyBucketRetryAfter(r *RateLimitReq, b *LeakyBucketItem, now int64, rate float64) int64 {
+	// The hits will never be accepted
+	if r.Hits > b.Burst {
+		return 0
+	}
+	// Hits which leaked since `UpdatedAt` are not yet included in `Remaining`
+	wait := int64(math.Ceil(float64(r.Hits)*rate-b.Remaining*rate)) - (now - b.UpdatedAt)
+	if wait > 0 {
+		return wait
+	}
+	return 0
+}
+
 // Called by leakyBucket() when adding a new item in the store.
 func leakyBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	r = withoutRefund(r)
 	now := MillisecondNow()
 	duration := r.Duration
 	rate := float64(duration) / float64(r.Limit)
 	if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
 		n := clock.Now()
-		expire, err := GregorianExpiration(n, r.Duration)
+		expire, err := gregorianExpiration(n, r)
 		if err != nil {
 			return nil, err
 		}
@@ -458,7 +536,7 @@ func leakyBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq)
 
 	// Client could be requesting that we start with the bucket OVER_LIMIT
 	if r.Hits > r.Burst {
-		metricOverLimitCounter.Add(1)
+		countOverLimit(r)
 		rl.Status = Status_OVER_LIMIT
 		rl.Remaining = 0
 		rl.ResetTime = now + (rl.Limit-rl.Remaining)*int64(rate)
@@ -480,3 +558,824 @@ func leakyBucketNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq)
 
 	return &rl, nil
 }
+
+// Implements the generic cell rate algorithm for rate limiting https://en.wikipedia.org/wiki/Generic_cell_rate_algorithm
+// Each hit pushes the theoretical arrival time (TAT) forward by the emission interval (Duration / Limit). A hit is
+// accepted as long as the TAT does not get further ahead of now than the burst allows.
+func gcra(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	gcraTimer := prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("gcra"))
+	defer gcraTimer.ObserveDuration()
+
+	burst := r.Burst
+	if burst == 0 {
+		burst = r.Limit
+	}
+
+	duration := r.Duration
+	if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
+		// Calculate the rate using the entire duration of the gregorian interval
+		duration, err = gregorianDuration(clock.Now(), r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Limit <= 0 || duration <= 0 {
+		return nil, errors.New("`Limit` and `Duration` must be greater than zero when using GCRA")
+	}
+
+	interval, tolerance := gcraInterval(r.Limit, duration, burst)
+	now := clock.Now().UnixNano()
+
+	// Get rate limit from cache.
+	hashKey := r.HashKey()
+	item, ok := c.GetItem(hashKey)
+
+	if s != nil && !ok {
+		// Cache miss.
+		// Check our store for the item.
+		if item, ok = s.Get(ctx, r); ok {
+			c.Add(item)
+		}
+	}
+
+	// Sanity checks.
+	if ok {
+		if item.Value == nil {
+			msgPart := "gcra: Invalid cache item; Value is nil"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("hashKey", hashKey),
+				attribute.String("key", r.UniqueKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		} else if item.Key != hashKey {
+			msgPart := "gcra: Invalid cache item; key mismatch"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("itemKey", item.Key),
+				attribute.String("hashKey", hashKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		}
+	}
+
+	var g *GCRAItem
+	isNew := !ok
+	if ok {
+		// Item found in cache or store.
+		g, ok = item.Value.(*GCRAItem)
+		if !ok {
+			// Client switched algorithms; perhaps due to a migration?
+			trace.SpanFromContext(ctx).AddEvent("Client switched algorithms; perhaps due to a migration?")
+
+			c.Remove(hashKey)
+
+			if s != nil {
+				s.Remove(ctx, hashKey)
+			}
+			isNew = true
+		}
+	}
+
+	if isNew {
+		// Item is not found in cache or store, create new.
+		g = &GCRAItem{TAT: now}
+		item = &CacheItem{
+			Algorithm: Algorithm_GCRA,
+			Key:       hashKey,
+			Value:     g,
+		}
+	}
+
+	if HasBehavior(r.Behavior, Behavior_RESET_REMAINING) || g.TAT < now {
+		g.TAT = now
+	}
+
+	if s != nil {
+		defer func() {
+			s.OnChange(ctx, r, item)
+		}()
+	}
+
+	rl := &RateLimitResp{
+		Status:    Status_UNDER_LIMIT,
+		Limit:     r.Limit,
+		Remaining: gcraRemaining(g.TAT, now, interval, tolerance),
+	}
+
+	tat := g.TAT + r.Hits*interval
+	if tat < now {
+		tat = now
+	}
+
+	switch {
+	// Client is only interested in retrieving the current status.
+	case r.Hits == 0:
+
+	// If requested is more than available, then return over the limit
+	// without updating the TAT, unless `DRAIN_OVER_LIMIT` is set.
+	case tat-now > tolerance:
+		trace.SpanFromContext(ctx).AddEvent("Over the limit")
+		countOverLimit(r)
+		rl.Status = Status_OVER_LIMIT
+		if HasBehavior(r.Behavior, Behavior_DRAIN_OVER_LIMIT) {
+			// DRAIN_OVER_LIMIT behavior drains the remaining counter.
+			g.TAT = now + tolerance
+			rl.Remaining = 0
+		}
+		if r.Hits <= burst {
+			// The hits are accepted once they no longer push the TAT further ahead of now than the burst allows
+			wait := g.TAT + r.Hits*interval - tolerance - now
+			rl.RetryAfter = (wait + int64(clock.Millisecond) - 1) / int64(clock.Millisecond)
+		}
+
+	default:
+		g.TAT = tat
+		rl.Remaining = gcraRemaining(g.TAT, now, interval, tolerance)
+	}
+
+	// The limit is fully replenished once we reach the TAT
+	expire := (g.TAT + int64(clock.Millisecond) - 1) / int64(clock.Millisecond)
+	item.ExpireAt = expire
+	rl.ResetTime = expire
+
+	if isNew {
+		c.Add(item)
+	}
+	return rl, nil
+}
+
+// gcraInterval returns the emission interval and the burst tolerance in nanoseconds
+func gcraInterval(limit, duration, burst int64) (interval, tolerance int64) {
+	interval = duration * int64(clock.Millisecond) / limit
+	if interval == 0 {
+		interval = 1
+	}
+	return interval, interval * burst
+}
+
+// gcraRemaining returns the number of hits which would be accepted at `now` given the theoretical arrival time
+func gcraRemaining(tat, now, interval, tolerance int64) int64 {
+	remaining := (tolerance - (tat - now)) / interval
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Implements a token bucket which refills continuously at `Limit` tokens per `Duration`, holding up to `Burst`
+// tokens. Unlike tokenBucket() the bucket is never refilled all at once, so clients cannot burst at the end of
+// every window. See https://pkg.go.dev/golang.org/x/time/rate
+func continuousTokenBucket(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	continuousTokenBucketTimer := prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("continuousTokenBucket"))
+	defer continuousTokenBucketTimer.ObserveDuration()
+
+	burst, duration, err := continuousTokenBucketConfig(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The time in milliseconds to refill a single token
+	rate := float64(duration) / float64(r.Limit)
+	now := MillisecondNow()
+
+	// Get rate limit from cache.
+	hashKey := r.HashKey()
+	item, ok := c.GetItem(hashKey)
+
+	if s != nil && !ok {
+		// Cache miss.
+		// Check our store for the item.
+		if item, ok = s.Get(ctx, r); ok {
+			c.Add(item)
+		}
+	}
+
+	// Sanity checks.
+	if ok {
+		if item.Value == nil {
+			msgPart := "continuousTokenBucket: Invalid cache item; Value is nil"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("hashKey", hashKey),
+				attribute.String("key", r.UniqueKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		} else if item.Key != hashKey {
+			msgPart := "continuousTokenBucket: Invalid cache item; key mismatch"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("itemKey", item.Key),
+				attribute.String("hashKey", hashKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		}
+	}
+
+	var b *ContinuousTokenBucketItem
+	isNew := !ok
+	if ok {
+		// Item found in cache or store.
+		b, ok = item.Value.(*ContinuousTokenBucketItem)
+		if !ok {
+			// Client switched algorithms; perhaps due to a migration?
+			trace.SpanFromContext(ctx).AddEvent("Client switched algorithms; perhaps due to a migration?")
+
+			c.Remove(hashKey)
+
+			if s != nil {
+				s.Remove(ctx, hashKey)
+			}
+			isNew = true
+		}
+	}
+
+	if isNew {
+		// Item is not found in cache or store, create new.
+		b = &ContinuousTokenBucketItem{Tokens: float64(burst), UpdatedAt: now}
+		item = &CacheItem{
+			Algorithm: Algorithm_CONTINUOUS_TOKEN_BUCKET,
+			Key:       hashKey,
+			Value:     b,
+		}
+	}
+
+	// Refill the bucket at the rate it was last updated with, then apply any change to the limit
+	b.Tokens = b.tokensAt(now, burst)
+	b.UpdatedAt = now
+	if HasBehavior(r.Behavior, Behavior_RESET_REMAINING) {
+		b.Tokens = float64(burst)
+	}
+	b.Limit = r.Limit
+	b.Duration = duration
+	b.Burst = burst
+
+	if s != nil {
+		defer func() {
+			s.OnChange(ctx, r, item)
+		}()
+	}
+
+	rl := &RateLimitResp{
+		Status:    Status_UNDER_LIMIT,
+		Limit:     r.Limit,
+		Remaining: int64(b.Tokens),
+	}
+
+	switch {
+	// Client is only interested in retrieving the current status.
+	case r.Hits == 0:
+
+	// If requested is more than available, then return over the limit
+	// without taking any tokens, unless `DRAIN_OVER_LIMIT` is set.
+	case float64(r.Hits) > b.Tokens:
+		trace.SpanFromContext(ctx).AddEvent("Over the limit")
+		countOverLimit(r)
+		rl.Status = Status_OVER_LIMIT
+		if HasBehavior(r.Behavior, Behavior_DRAIN_OVER_LIMIT) {
+			// DRAIN_OVER_LIMIT behavior drains the remaining counter.
+			b.Tokens = 0
+			rl.Remaining = 0
+		}
+		if r.Hits <= burst {
+			rl.RetryAfter = int64(math.Ceil((float64(r.Hits) - b.Tokens) * rate))
+		}
+
+	// Client is refunding tokens, which never fill the bucket beyond the burst.
+	case r.Hits < 0:
+		b.Tokens = math.Min(b.Tokens-float64(r.Hits), float64(burst))
+		rl.Remaining = int64(b.Tokens)
+
+	default:
+		b.Tokens -= float64(r.Hits)
+		rl.Remaining = int64(b.Tokens)
+	}
+
+	// Once the bucket is full again the item is no different from a new item
+	rl.ResetTime = now + int64(math.Ceil((float64(burst)-b.Tokens)*rate))
+	item.ExpireAt = rl.ResetTime
+
+	if isNew {
+		c.Add(item)
+	}
+	return rl, nil
+}
+
+// continuousTokenBucketConfig returns the burst of the bucket and the milliseconds in which it refills `Limit` tokens
+func continuousTokenBucketConfig(r *RateLimitReq) (burst, duration int64, err error) {
+	burst = r.Burst
+	if burst == 0 {
+		burst = r.Limit
+	}
+
+	duration = r.Duration
+	if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
+		// Calculate the rate using the entire duration of the gregorian interval
+		duration, err = gregorianDuration(clock.Now(), r)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if r.Limit <= 0 || duration <= 0 {
+		return 0, 0, errors.New("`Limit` and `Duration` must be greater than zero when using CONTINUOUS_TOKEN_BUCKET")
+	}
+	return burst, duration, nil
+}
+
+// tokensAt returns the tokens in the bucket at `now`, up to `burst` tokens
+func (b *ContinuousTokenBucketItem) tokensAt(now, burst int64) float64 {
+	tokens := b.Tokens
+	if elapsed := now - b.UpdatedAt; elapsed > 0 && b.Limit > 0 && b.Duration > 0 {
+		tokens += float64(elapsed) * float64(b.Limit) / float64(b.Duration)
+	}
+	if tokens > float64(burst) {
+		tokens = float64(burst)
+	}
+	return tokens
+}
+
+// Implements a concurrency limit. Each hit acquires a slot which is held until it is released by a request
+// with negative hits (see ReleaseRateLimits) or until the lease expires after `Duration`.
+func concurrency(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	concurrencyTimer := prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("concurrency"))
+	defer concurrencyTimer.ObserveDuration()
+
+	now := MillisecondNow()
+	expire := now + r.Duration
+	if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
+		expire, err = gregorianExpiration(clock.Now(), r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Get rate limit from cache.
+	hashKey := r.HashKey()
+	item, ok := c.GetItem(hashKey)
+
+	if s != nil && !ok {
+		// Cache miss.
+		// Check our store for the item.
+		if item, ok = s.Get(ctx, r); ok {
+			c.Add(item)
+		}
+	}
+
+	// Sanity checks.
+	if ok {
+		if item.Value == nil {
+			msgPart := "concurrency: Invalid cache item; Value is nil"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("hashKey", hashKey),
+				attribute.String("key", r.UniqueKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		} else if item.Key != hashKey {
+			msgPart := "concurrency: Invalid cache item; key mismatch"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("itemKey", item.Key),
+				attribute.String("hashKey", hashKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		}
+	}
+
+	var ci *ConcurrencyItem
+	isNew := !ok
+	if ok {
+		// Item found in cache or store.
+		ci, ok = item.Value.(*ConcurrencyItem)
+		if !ok {
+			// Client switched algorithms; perhaps due to a migration?
+			trace.SpanFromContext(ctx).AddEvent("Client switched algorithms; perhaps due to a migration?")
+
+			c.Remove(hashKey)
+
+			if s != nil {
+				s.Remove(ctx, hashKey)
+			}
+			isNew = true
+		}
+	}
+
+	if isNew {
+		// Item is not found in cache or store, create new.
+		ci = &ConcurrencyItem{}
+		item = &CacheItem{
+			Algorithm: Algorithm_CONCURRENCY,
+			Key:       hashKey,
+			Value:     ci,
+		}
+	}
+
+	if HasBehavior(r.Behavior, Behavior_RESET_REMAINING) {
+		ci.Leases = nil
+	}
+	ci.Limit = r.Limit
+	ci.expireLeases(now)
+
+	if s != nil {
+		defer func() {
+			s.OnChange(ctx, r, item)
+		}()
+	}
+
+	rl := &RateLimitResp{
+		Status:    Status_UNDER_LIMIT,
+		Limit:     r.Limit,
+		Remaining: ci.remaining(),
+	}
+
+	switch {
+	// Client is only interested in retrieving the current status or
+	// updating the rate limit config.
+	case r.Hits == 0:
+
+	// Negative hits return previously acquired slots
+	case r.Hits < 0:
+		ci.release(-r.Hits)
+
+	// If requested is more than available, then return over the limit
+	// without acquiring any slots.
+	case r.Hits > rl.Remaining:
+		trace.SpanFromContext(ctx).AddEvent("Over the limit")
+		countOverLimit(r)
+		rl.Status = Status_OVER_LIMIT
+		rl.RetryAfter = ci.retryAfter(now, r.Hits)
+
+	default:
+		ci.Leases = append(ci.Leases, ConcurrencyLease{ExpireAt: expire, Count: r.Hits})
+	}
+
+	rl.Remaining = ci.remaining()
+	// The reset time is when the next slot is returned by an expiring lease
+	rl.ResetTime = now
+	if len(ci.Leases) != 0 {
+		rl.ResetTime = ci.Leases[0].ExpireAt
+	}
+	for _, l := range ci.Leases {
+		if l.ExpireAt < rl.ResetTime {
+			rl.ResetTime = l.ExpireAt
+		}
+		if l.ExpireAt > expire {
+			expire = l.ExpireAt
+		}
+	}
+	item.ExpireAt = expire
+
+	if isNew {
+		c.Add(item)
+	}
+	return rl, nil
+}
+
+// expireLeases drops all the leases which have expired by `now`
+func (ci *ConcurrencyItem) expireLeases(now int64) {
+	leases := ci.Leases[:0]
+	for _, l := range ci.Leases {
+		if l.ExpireAt > now {
+			leases = append(leases, l)
+		}
+	}
+	ci.Leases = leases
+}
+
+// release returns up to `count` slots, starting with the oldest lease
+func (ci *ConcurrencyItem) release(count int64) {
+	for len(ci.Leases) != 0 && count > 0 {
+		if ci.Leases[0].Count > count {
+			ci.Leases[0].Count -= count
+			return
+		}
+		count -= ci.Leases[0].Count
+		ci.Leases = ci.Leases[1:]
+	}
+}
+
+// retryAfter returns how long to wait until enough leases expire for `hits` slots to be acquired
+func (ci *ConcurrencyItem) retryAfter(now, hits int64) int64 {
+	// The hits will never be accepted
+	if hits > ci.Limit {
+		return 0
+	}
+
+	leases := make([]ConcurrencyLease, len(ci.Leases))
+	copy(leases, ci.Leases)
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].ExpireAt < leases[j].ExpireAt
+	})
+
+	free := ci.Limit
+	for _, l := range leases {
+		free -= l.Count
+	}
+	for _, l := range leases {
+		free += l.Count
+		if free >= hits {
+			return l.ExpireAt - now
+		}
+	}
+	return 0
+}
+
+// remaining returns the number of slots which are not held by a lease
+func (ci *ConcurrencyItem) remaining() int64 {
+	remaining := ci.Limit
+	for _, l := range ci.Leases {
+		remaining -= l.Count
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Implements the sliding window counter algorithm for rate limiting. Hits are counted in fixed windows,
+// and the count of the previous window is weighted by how much of it still overlaps the sliding window.
+func slidingWindow(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	slidingWindowTimer := prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("slidingWindow"))
+	defer slidingWindowTimer.ObserveDuration()
+
+	// Get rate limit from cache.
+	hashKey := r.HashKey()
+	item, ok := c.GetItem(hashKey)
+
+	if s != nil && !ok {
+		// Cache miss.
+		// Check our store for the item.
+		if item, ok = s.Get(ctx, r); ok {
+			c.Add(item)
+		}
+	}
+
+	// Sanity checks.
+	if ok {
+		if item.Value == nil {
+			msgPart := "slidingWindow: Invalid cache item; Value is nil"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("hashKey", hashKey),
+				attribute.String("key", r.UniqueKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		} else if item.Key != hashKey {
+			msgPart := "slidingWindow: Invalid cache item; key mismatch"
+			trace.SpanFromContext(ctx).AddEvent(msgPart, trace.WithAttributes(
+				attribute.String("itemKey", item.Key),
+				attribute.String("hashKey", hashKey),
+				attribute.String("name", r.Name),
+			))
+			logrus.Error(msgPart)
+			ok = false
+		}
+	}
+
+	if ok {
+		// Item found in cache or store.
+		w, ok := item.Value.(*SlidingWindowItem)
+		if !ok {
+			// Client switched algorithms; perhaps due to a migration?
+			trace.SpanFromContext(ctx).AddEvent("Client switched algorithms; perhaps due to a migration?")
+
+			c.Remove(hashKey)
+
+			if s != nil {
+				s.Remove(ctx, hashKey)
+			}
+
+			return slidingWindowNewItem(ctx, s, c, r)
+		}
+
+		now := MillisecondNow()
+		start, duration, err := slidingWindowBounds(r, now, w.WindowStart)
+		if err != nil {
+			return nil, err
+		}
+
+		// Slide the window forward if the current window has ended. The hits of the
+		// current window only carry over if the new window immediately follows it.
+		if start != w.WindowStart {
+			if start == w.WindowStart+w.Duration {
+				w.Previous = w.Current
+			} else {
+				w.Previous = 0
+			}
+			w.Current = 0
+			w.WindowStart = start
+		}
+		w.Duration = duration
+		w.Limit = r.Limit
+
+		if HasBehavior(r.Behavior, Behavior_RESET_REMAINING) {
+			w.Previous = 0
+			w.Current = 0
+		}
+
+		item.ExpireAt = w.WindowStart + w.Duration*2
+
+		rl := &RateLimitResp{
+			Status:    Status_UNDER_LIMIT,
+			Limit:     r.Limit,
+			Remaining: w.remaining(now),
+			ResetTime: w.resetTime(),
+		}
+
+		if s != nil {
+			defer func() {
+				s.OnChange(ctx, r, item)
+			}()
+		}
+
+		// Client is only interested in retrieving the current status or
+		// updating the rate limit config.
+		if r.Hits == 0 {
+			return rl, nil
+		}
+
+		// Client is refunding hits, which are only taken from the hits of the current window.
+		if r.Hits < 0 {
+			w.Current += r.Hits
+			if w.Current < 0 {
+				w.Current = 0
+			}
+			rl.Remaining = w.remaining(now)
+			rl.ResetTime = w.resetTime()
+			return rl, nil
+		}
+
+		// If we are already at the limit.
+		if rl.Remaining == 0 && r.Hits > 0 {
+			trace.SpanFromContext(ctx).AddEvent("Already over the limit")
+			countOverLimit(r)
+			rl.Status = Status_OVER_LIMIT
+			rl.RetryAfter = w.retryAfter(now, r.Hits)
+			return rl, nil
+		}
+
+		// If requested is more than available, then return over the limit
+		// without updating the window, unless `DRAIN_OVER_LIMIT` is set.
+		if r.Hits > rl.Remaining {
+			trace.SpanFromContext(ctx).AddEvent("Over the limit")
+			countOverLimit(r)
+			rl.Status = Status_OVER_LIMIT
+			if HasBehavior(r.Behavior, Behavior_DRAIN_OVER_LIMIT) {
+				// DRAIN_OVER_LIMIT behavior drains the remaining counter.
+				w.Current += rl.Remaining
+				rl.Remaining = 0
+				rl.ResetTime = w.resetTime()
+			}
+			rl.RetryAfter = w.retryAfter(now, r.Hits)
+			return rl, nil
+		}
+
+		w.Current += r.Hits
+		rl.Remaining -= r.Hits
+		rl.ResetTime = w.resetTime()
+		return rl, nil
+	}
+
+	// Item is not found in cache or store, create new.
+	return slidingWindowNewItem(ctx, s, c, r)
+}
+
+// Called by slidingWindow() when adding a new item in the store.
+func slidingWindowNewItem(ctx context.Context, s Store, c Cache, r *RateLimitReq) (resp *RateLimitResp, err error) {
+	r = withoutRefund(r)
+	now := MillisecondNow()
+	start, duration, err := slidingWindowBounds(r, now, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &SlidingWindowItem{
+		Limit:       r.Limit,
+		Duration:    duration,
+		WindowStart: start,
+		Current:     r.Hits,
+	}
+
+	rl := &RateLimitResp{
+		Status:    Status_UNDER_LIMIT,
+		Limit:     r.Limit,
+		Remaining: r.Limit - r.Hits,
+	}
+
+	// Client could be requesting that we always return OVER_LIMIT.
+	if r.Hits > r.Limit {
+		trace.SpanFromContext(ctx).AddEvent("Over the limit")
+		countOverLimit(r)
+		rl.Status = Status_OVER_LIMIT
+		rl.Remaining = r.Limit
+		w.Current = 0
+	}
+	rl.ResetTime = w.resetTime()
+
+	item := &CacheItem{
+		Algorithm: Algorithm_SLIDING_WINDOW,
+		Key:       r.HashKey(),
+		Value:     w,
+		ExpireAt:  w.WindowStart + w.Duration*2,
+	}
+
+	c.Add(item)
+
+	if s != nil {
+		s.OnChange(ctx, r, item)
+	}
+
+	return rl, nil
+}
+
+// slidingWindowBounds returns the start and the length of the fixed window which contains `now`.
+// Windows are aligned to `anchor`, which is the start of a previous window or zero if there is
+// none, unless the duration is gregorian in which case windows are aligned to the gregorian interval.
+func slidingWindowBounds(r *RateLimitReq, now, anchor int64) (start, duration int64, err error) {
+	if HasBehavior(r.Behavior, Behavior_DURATION_IS_GREGORIAN) {
+		n := clock.Now()
+		expire, err := gregorianExpiration(n, r)
+		if err != nil {
+			return 0, 0, err
+		}
+		duration, err = gregorianDuration(n, r)
+		if err != nil {
+			return 0, 0, err
+		}
+		// gregorianExpiration() returns the last millisecond of the interval
+		return expire + 1 - duration, duration, nil
+	}
+
+	if r.Duration <= 0 {
+		return 0, 0, errors.New("`Duration` must be greater than zero when using SLIDING_WINDOW")
+	}
+
+	if anchor == 0 || now < anchor {
+		return now, r.Duration, nil
+	}
+	return anchor + ((now-anchor)/r.Duration)*r.Duration, r.Duration, nil
+}
+
+// remaining returns how many hits are left in the sliding window ending at `now`
+func (w *SlidingWindowItem) remaining(now int64) int64 {
+	count := w.Current
+	if w.Previous != 0 && w.Duration > 0 {
+		elapsed := now - w.WindowStart
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		if elapsed < w.Duration {
+			// Round up so the weighted count never allows more than the limit
+			weight := float64(w.Duration-elapsed) / float64(w.Duration)
+			count += int64(math.Ceil(float64(w.Previous) * weight))
+		}
+	}
+	if count >= w.Limit {
+		return 0
+	}
+	return w.Limit - count
+}
+
+// retryAfter returns how long to wait until `hits` fit within the sliding window
+func (w *SlidingWindowItem) retryAfter(now, hits int64) int64 {
+	// The hits will never be accepted
+	if hits > w.Limit {
+		return 0
+	}
+
+	// Find how far into a window we must be for the weighted count of the window
+	// before it to leave room for the hits.
+	var at int64
+	if w.Current+hits <= w.Limit {
+		if w.Previous == 0 {
+			return 0
+		}
+		// Wait for enough of the previous window to slide out
+		free := float64(w.Limit-w.Current-hits) / float64(w.Previous)
+		at = w.WindowStart + int64(math.Ceil(float64(w.Duration)*(1-free)))
+	} else {
+		// Wait for enough of the current window to slide out once it becomes the previous window
+		free := float64(w.Limit-hits) / float64(w.Current)
+		at = w.WindowStart + w.Duration + int64(math.Ceil(float64(w.Duration)*(1-free)))
+	}
+
+	if at > now {
+		return at - now
+	}
+	return 0
+}
+
+// resetTime returns the time at which all hits counted so far will have slid out of the window
+func (w *SlidingWindowItem) resetTime() int64 {
+	if w.Current != 0 {
+		return w.WindowStart + w.Duration*2
+	}
+	return w.WindowStart + w.Duration
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package gubernator
 
+import "unsafe"
+
 type Cache interface {
 	Add(item *CacheItem) bool
 	UpdateExpiration(key string, expireAt int64) bool
@@ -39,3 +41,53 @@ type CacheItem struct {
 	// for the latest rate limit data.
 	InvalidAt int64
 }
+
+// isExpired returns true if the item has expired or been invalidated at `now`
+func (item *CacheItem) isExpired(now int64) bool {
+	return item.ExpireAt < now || (item.InvalidAt != 0 && item.InvalidAt < now)
+}
+
+// MemoryBoundedCache is implemented by caches which estimate the memory used by their items, such
+// that the cache can be limited by a memory budget instead of the number of items.
+type MemoryBoundedCache interface {
+	Cache
+	// SetMaxBytes limits the estimated memory used by the items in the cache, after which the
+	// maximum number of items is ignored. Must be called before any items are added.
+	SetMaxBytes(maxBytes int64)
+	// Bytes returns the estimated memory used by the items in the cache
+	Bytes() int64
+}
+
+// SweepableCache is implemented by caches which can remove expired items in the background,
+// rather than only when they are accessed or evicted.
+type SweepableCache interface {
+	Cache
+	// Sweep removes expired items, examining at most max items such that a large cache is swept
+	// over several calls. Each call resumes where the previous call stopped. Returns the number
+	// of items removed.
+	Sweep(max int) int
+}
+
+// cacheEntryOverhead approximates the memory a cache uses to index an item, which is a map entry
+// and a linked list element for both LRUCache and TinyLFUCache.
+const cacheEntryOverhead = 96
+
+// estimateCacheItemBytes returns the approximate memory used to hold the item in a cache
+func estimateCacheItemBytes(item *CacheItem) int64 {
+	size := int64(unsafe.Sizeof(*item)) + cacheEntryOverhead + int64(len(item.Key))
+	switch v := item.Value.(type) {
+	case *TokenBucketItem:
+		size += int64(unsafe.Sizeof(*v))
+	case *LeakyBucketItem:
+		size += int64(unsafe.Sizeof(*v))
+	case *SlidingWindowItem:
+		size += int64(unsafe.Sizeof(*v))
+	case *GCRAItem:
+		size += int64(unsafe.Sizeof(*v))
+	case *ContinuousTokenBucketItem:
+		size += int64(unsafe.Sizeof(*v))
+	case *ConcurrencyItem:
+		size += int64(unsafe.Sizeof(*v)) + int64(cap(v.Leases))*int64(unsafe.Sizeof(ConcurrencyLease{}))
+	}
+	return size
+}
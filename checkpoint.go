@@ -0,0 +1,158 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"time"
+
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// IncrementalLoader is a Loader which is able to save only the rate limits which changed
+// since the previous save. When the Loader implements this interface, periodic checkpoints
+// call SaveChanges() with the changed rate limits instead of calling Save() with every
+// rate limit in the cache.
+type IncrementalLoader interface {
+	Loader
+
+	// SaveChanges saves the rate limits which changed since the previous checkpoint. Rate limits
+	// removed from the cache are not included, implementations are expected to expire them.
+	SaveChanges(in chan *CacheItem) error
+}
+
+// checkpointManager periodically saves the contents of the cache through the Loader,
+// such that a crash does not lose every rate limit since the last clean shutdown.
+type checkpointManager struct {
+	wg       syncutil.WaitGroup
+	interval time.Duration
+	log      FieldLogger
+	instance *V1Instance
+	// Set when a checkpoint fails, such that the next checkpoint saves the entire cache
+	forceFull bool
+
+	metricCheckpointDuration prometheus.Summary
+	metricCheckpointSize     prometheus.Gauge
+	metricCheckpointCounter  *prometheus.CounterVec
+}
+
+func newCheckpointManager(interval time.Duration, instance *V1Instance) *checkpointManager {
+	cm := checkpointManager{
+		interval: interval,
+		log:      instance.log,
+		instance: instance,
+		metricCheckpointDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:       "gubernator_checkpoint_duration",
+			Help:       "The duration of periodic cache checkpoints in seconds.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+		}),
+		metricCheckpointSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gubernator_checkpoint_size",
+			Help: "The number of rate limits saved by the last checkpoint.",
+		}),
+		metricCheckpointCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gubernator_checkpoint_counter",
+			Help: "The count of periodic cache checkpoints.  Label \"result\" may be \"full\", \"incremental\", \"skipped\" or \"error\".",
+		}, []string{"result"}),
+	}
+
+	if interval > 0 && instance.conf.Loader != nil {
+		cm.run()
+	}
+	return &cm
+}
+
+func (cm *checkpointManager) run() {
+	tick := clock.NewTicker(cm.interval)
+	cm.wg.Until(func(done chan struct{}) bool {
+		select {
+		case <-tick.C():
+			ctx, cancel := context.WithTimeout(context.Background(), cm.interval)
+			if err := cm.checkpoint(ctx); err != nil {
+				cm.log.WithError(err).Error("while checkpointing the cache")
+			}
+			cancel()
+		case <-done:
+			tick.Stop()
+			return false
+		}
+		return true
+	})
+}
+
+// checkpoint saves the rate limits which changed since the last checkpoint when the Loader
+// is an IncrementalLoader, else every rate limit in the cache if any of them changed.
+func (cm *checkpointManager) checkpoint(ctx context.Context) error {
+	defer prometheus.NewTimer(cm.metricCheckpointDuration).ObserveDuration()
+
+	loader := cm.instance.conf.Loader
+	incremental, isIncremental := loader.(IncrementalLoader)
+	isIncremental = isIncremental && !cm.forceFull
+
+	items, changed, err := cm.instance.workerPool.snapshot(ctx, isIncremental)
+	if err != nil {
+		cm.forceFull = true
+		cm.metricCheckpointCounter.WithLabelValues("error").Inc()
+		return errors.Wrap(err, "while copying the cache")
+	}
+
+	if !changed && !cm.forceFull {
+		cm.metricCheckpointCounter.WithLabelValues("skipped").Inc()
+		return nil
+	}
+
+	in := make(chan *CacheItem, 500)
+	go func() {
+		for _, item := range items {
+			in <- item
+		}
+		close(in)
+	}()
+
+	// An IncrementalLoader is always saved through SaveChanges(), as after a failed checkpoint
+	// Save() may do more than an upsert of every rate limit in the cache.
+	if incremental != nil {
+		err = incremental.SaveChanges(in)
+	} else {
+		err = loader.Save(in)
+	}
+	// Ensure the goroutine above exits if the loader returned early
+	for range in {
+	}
+	if err != nil {
+		cm.forceFull = true
+		cm.metricCheckpointCounter.WithLabelValues("error").Inc()
+		return errors.Wrap(err, "while saving checkpoint")
+	}
+
+	cm.forceFull = false
+	cm.metricCheckpointSize.Set(float64(len(items)))
+	if isIncremental {
+		cm.metricCheckpointCounter.WithLabelValues("incremental").Inc()
+	} else {
+		cm.metricCheckpointCounter.WithLabelValues("full").Inc()
+	}
+	return nil
+}
+
+// Close stops the periodic checkpoints
+func (cm *checkpointManager) Close() {
+	cm.wg.Stop()
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/testutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkpointLoader records the keys passed to each call of Save() and SaveChanges()
+type checkpointLoader struct {
+	mutex sync.Mutex
+	saves [][]string
+}
+
+func (l *checkpointLoader) Load() (chan *gubernator.CacheItem, error) {
+	ch := make(chan *gubernator.CacheItem)
+	close(ch)
+	return ch, nil
+}
+
+func (l *checkpointLoader) Save(in chan *gubernator.CacheItem) error {
+	var keys []string
+	for item := range in {
+		keys = append(keys, item.Key)
+	}
+	sort.Strings(keys)
+	l.mutex.Lock()
+	l.saves = append(l.saves, keys)
+	l.mutex.Unlock()
+	return nil
+}
+
+func (l *checkpointLoader) Saves() [][]string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([][]string{}, l.saves...)
+}
+
+type incrementalCheckpointLoader struct {
+	checkpointLoader
+}
+
+func (l *incrementalCheckpointLoader) SaveChanges(in chan *gubernator.CacheItem) error {
+	return l.Save(in)
+}
+
+func checkpointHit(t *testing.T, client gubernator.V1Client, keys ...string) {
+	for _, key := range keys {
+		resp, err := client.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{
+				{
+					Name:      "test_checkpoint",
+					UniqueKey: key,
+					Duration:  gubernator.Minute,
+					Limit:     10,
+					Hits:      1,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "", resp.Responses[0].Error)
+	}
+}
+
+func checkpointCount(t *testing.T, srv *v1Server, result string) float64 {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(srv.srv))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != "gubernator_checkpoint_counter" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if m.GetLabel()[0].GetValue() == result {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestCheckpoint(t *testing.T) {
+	loader := &checkpointLoader{}
+	srv := newV1Server(t, "localhost:0", gubernator.Config{
+		Loader:             loader,
+		CheckpointInterval: time.Millisecond * 50,
+	})
+	defer srv.Close()
+
+	client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	checkpointHit(t, client, "account:1", "account:2")
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		saves := loader.Saves()
+		if !assert.NotEmpty(t, saves) {
+			return
+		}
+		assert.Equal(t, []string{"test_checkpoint_account:1", "test_checkpoint_account:2"}, saves[len(saves)-1])
+	})
+
+	// Checkpoints are skipped while nothing changes
+	saved := len(loader.Saves())
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, saved, len(loader.Saves()))
+	assert.NotZero(t, checkpointCount(t, srv, "skipped"))
+
+	// Any change saves the entire cache
+	checkpointHit(t, client, "account:2")
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		saves := loader.Saves()
+		if !assert.Greater(t, len(saves), saved) {
+			return
+		}
+		assert.Equal(t, []string{"test_checkpoint_account:1", "test_checkpoint_account:2"}, saves[len(saves)-1])
+	})
+	assert.NotZero(t, checkpointCount(t, srv, "full"))
+}
+
+func TestCheckpointIncremental(t *testing.T) {
+	loader := &incrementalCheckpointLoader{}
+	srv := newV1Server(t, "localhost:0", gubernator.Config{
+		Loader:             loader,
+		CheckpointInterval: time.Millisecond * 50,
+	})
+	defer srv.Close()
+
+	client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	checkpointHit(t, client, "account:1", "account:2")
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		// The hits may be split across checkpoints
+		var keys []string
+		for _, save := range loader.Saves() {
+			keys = append(keys, save...)
+		}
+		assert.ElementsMatch(t, []string{"test_checkpoint_account:1", "test_checkpoint_account:2"}, keys)
+	})
+
+	// Only the rate limits which changed are saved
+	checkpointHit(t, client, "account:2")
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		saves := loader.Saves()
+		assert.Equal(t, []string{"test_checkpoint_account:2"}, saves[len(saves)-1])
+	})
+	assert.NotZero(t, checkpointCount(t, srv, "incremental"))
+}
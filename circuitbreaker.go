@@ -0,0 +1,228 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/setter"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrStoreCircuitOpen is returned by StoreCircuitBreaker instead of calling the store while the circuit is open
+var ErrStoreCircuitOpen = errors.New("store circuit breaker is open")
+
+type StoreCircuitBreakerConfig struct {
+	// (Optional) The number of consecutive failed calls to the store which opens the circuit. Defaults to 5
+	FailureThreshold int
+
+	// (Optional) How long the circuit stays open before a single call is allowed
+	// through to find out if the store has recovered. Defaults to 10s
+	OpenDuration time.Duration
+
+	// (Optional) How long each call to the store may take before it is counted as a failure. Defaults to 500ms
+	Timeout time.Duration
+
+	// (Optional) A Logger which implements the declared logger interface (typically *logrus.Entry)
+	Logger FieldLogger
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (c circuitState) String() string {
+	switch c {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// StoreCircuitBreaker stops calling a StoreV2 which keeps failing, such that a failing store does not
+// add its timeout to every rate limit check. After FailureThreshold consecutive failures the circuit
+// opens and every call returns ErrStoreCircuitOpen. Once OpenDuration has passed a single call is let
+// through, which closes the circuit if it succeeds and opens it again if it fails.
+type StoreCircuitBreaker struct {
+	conf  StoreCircuitBreakerConfig
+	store StoreV2
+
+	mutex    sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	// True while the single call allowed through a half-open circuit is in flight
+	probing bool
+	lastErr error
+
+	metricState    prometheus.Gauge
+	metricRejected prometheus.Counter
+}
+
+var _ StoreV2 = &StoreCircuitBreaker{}
+var _ StoreHealthChecker = &StoreCircuitBreaker{}
+var _ prometheus.Collector = &StoreCircuitBreaker{}
+
+func NewStoreCircuitBreaker(store StoreV2, conf StoreCircuitBreakerConfig) *StoreCircuitBreaker {
+	setter.SetDefault(&conf.FailureThreshold, 5)
+	setter.SetDefault(&conf.OpenDuration, time.Second*10)
+	setter.SetDefault(&conf.Timeout, time.Millisecond*500)
+	setter.SetDefault(&conf.Logger, logrus.WithField("category", "gubernator"))
+
+	return &StoreCircuitBreaker{
+		conf:  conf,
+		store: store,
+		metricState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gubernator_store_circuit_state",
+			Help: "The state of the store circuit breaker.  0 is closed, 1 is open and 2 is half-open.",
+		}),
+		metricRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gubernator_store_circuit_rejected",
+			Help: "The count of store calls which were not made because the circuit breaker was open.",
+		}),
+	}
+}
+
+func (b *StoreCircuitBreaker) OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) error {
+	return b.call(ctx, func(ctx context.Context) error {
+		return b.store.OnChange(ctx, r, item)
+	})
+}
+
+func (b *StoreCircuitBreaker) Get(ctx context.Context, r *RateLimitReq) (item *CacheItem, ok bool, err error) {
+	err = b.call(ctx, func(ctx context.Context) error {
+		var err error
+		item, ok, err = b.store.Get(ctx, r)
+		return err
+	})
+	return item, ok, err
+}
+
+func (b *StoreCircuitBreaker) Remove(ctx context.Context, key string) error {
+	return b.call(ctx, func(ctx context.Context) error {
+		return b.store.Remove(ctx, key)
+	})
+}
+
+// HealthCheck returns an error describing the last failure while the circuit is not closed
+func (b *StoreCircuitBreaker) HealthCheck() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state == circuitClosed {
+		return nil
+	}
+	return fmt.Errorf("store circuit breaker is %s; last error: %s", b.state, b.lastErr)
+}
+
+func (b *StoreCircuitBreaker) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !b.allow() {
+		b.metricRejected.Inc()
+		return ErrStoreCircuitOpen
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, b.conf.Timeout)
+	err := fn(callCtx)
+	cancel()
+
+	// The caller giving up on the call says nothing about the health of the store
+	b.record(err, err != nil && ctx.Err() != nil)
+	return err
+}
+
+// allow returns true if the store should be called
+func (b *StoreCircuitBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if clock.Since(b.openedAt) < b.conf.OpenDuration {
+			return false
+		}
+		b.setState(circuitHalfOpen)
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *StoreCircuitBreaker) record(err error, ignore bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.probing = false
+	}
+	if ignore {
+		return
+	}
+
+	if err == nil {
+		b.failures = 0
+		if b.state != circuitClosed {
+			b.conf.Logger.Info("Store has recovered; circuit breaker closed")
+			b.setState(circuitClosed)
+		}
+		return
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.state == circuitHalfOpen || (b.state == circuitClosed && b.failures >= b.conf.FailureThreshold) {
+		b.conf.Logger.WithError(err).Errorf("Store failed %d times in a row; circuit breaker open for %s",
+			b.failures, b.conf.OpenDuration)
+		b.openedAt = clock.Now()
+		b.setState(circuitOpen)
+	}
+}
+
+func (b *StoreCircuitBreaker) setState(state circuitState) {
+	b.state = state
+	b.metricState.Set(float64(state))
+}
+
+// Describe fetches prometheus metrics to be registered
+func (b *StoreCircuitBreaker) Describe(ch chan<- *prometheus.Desc) {
+	b.metricRejected.Describe(ch)
+	b.metricState.Describe(ch)
+}
+
+// Collect fetches metrics from the circuit breaker for use by prometheus
+func (b *StoreCircuitBreaker) Collect(ch chan<- prometheus.Metric) {
+	b.metricRejected.Collect(ch)
+	b.metricState.Collect(ch)
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore returns the configured error from every call and counts the calls it receives
+type failingStore struct {
+	mutex sync.Mutex
+	err   error
+	calls int
+}
+
+func (s *failingStore) SetError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
+
+func (s *failingStore) Calls() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls
+}
+
+func (s *failingStore) call() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls++
+	return s.err
+}
+
+func (s *failingStore) OnChange(ctx context.Context, r *gubernator.RateLimitReq, item *gubernator.CacheItem) error {
+	return s.call()
+}
+
+func (s *failingStore) Get(ctx context.Context, r *gubernator.RateLimitReq) (*gubernator.CacheItem, bool, error) {
+	return nil, false, s.call()
+}
+
+func (s *failingStore) Remove(ctx context.Context, key string) error {
+	return s.call()
+}
+
+func collectorValue(t require.TestingT, c prometheus.Collector, name string, labels ...string) float64 {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(c))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			for i, l := range m.GetLabel() {
+				if i < len(labels) && l.GetValue() != labels[i] {
+					continue metrics
+				}
+			}
+			if m.GetCounter() != nil {
+				return m.GetCounter().GetValue()
+			}
+			return m.GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestStoreCircuitBreaker(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
+
+	store := &failingStore{err: errors.New("connection refused")}
+	breaker := gubernator.NewStoreCircuitBreaker(store, gubernator.StoreCircuitBreakerConfig{
+		FailureThreshold: 3,
+		OpenDuration:     time.Second,
+	})
+	ctx := context.Background()
+
+	// Failures below the threshold are returned without opening the circuit
+	for i := 0; i < 2; i++ {
+		assert.EqualError(t, breaker.Remove(ctx, "key"), "connection refused")
+	}
+	assert.NoError(t, breaker.HealthCheck())
+
+	_, _, err := breaker.Get(ctx, &gubernator.RateLimitReq{Name: "test_circuit", UniqueKey: "key"})
+	assert.EqualError(t, err, "connection refused")
+	require.Error(t, breaker.HealthCheck())
+	assert.Contains(t, breaker.HealthCheck().Error(), "connection refused")
+	assert.Equal(t, float64(1), collectorValue(t, breaker, "gubernator_store_circuit_state"))
+
+	// While open, the store is not called
+	assert.ErrorIs(t, breaker.Remove(ctx, "key"), gubernator.ErrStoreCircuitOpen)
+	assert.Equal(t, 3, store.Calls())
+	assert.Equal(t, float64(1), collectorValue(t, breaker, "gubernator_store_circuit_rejected"))
+
+	// A failed probe opens the circuit again
+	clock.Advance(time.Second)
+	assert.EqualError(t, breaker.Remove(ctx, "key"), "connection refused")
+	assert.Equal(t, 4, store.Calls())
+	assert.ErrorIs(t, breaker.Remove(ctx, "key"), gubernator.ErrStoreCircuitOpen)
+
+	// A successful probe closes the circuit
+	store.SetError(nil)
+	clock.Advance(time.Second)
+	assert.NoError(t, breaker.Remove(ctx, "key"))
+	assert.NoError(t, breaker.HealthCheck())
+	assert.Equal(t, float64(0), collectorValue(t, breaker, "gubernator_store_circuit_state"))
+	assert.NoError(t, breaker.Remove(ctx, "key"))
+	assert.Equal(t, 6, store.Calls())
+}
+
+// blockingStore waits until the context is done
+type blockingStore struct{}
+
+func (blockingStore) OnChange(ctx context.Context, r *gubernator.RateLimitReq, item *gubernator.CacheItem) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (blockingStore) Get(ctx context.Context, r *gubernator.RateLimitReq) (*gubernator.CacheItem, bool, error) {
+	<-ctx.Done()
+	return nil, false, ctx.Err()
+}
+
+func (blockingStore) Remove(ctx context.Context, key string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStoreCircuitBreakerContext(t *testing.T) {
+	breaker := gubernator.NewStoreCircuitBreaker(blockingStore{}, gubernator.StoreCircuitBreakerConfig{
+		FailureThreshold: 1,
+		Timeout:          time.Millisecond * 10,
+	})
+
+	// A cancelled caller does not count as a failure of the store
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, breaker.Remove(ctx, "key"), context.Canceled)
+	assert.NoError(t, breaker.HealthCheck())
+
+	// A call which takes longer than the timeout does
+	assert.ErrorIs(t, breaker.Remove(context.Background(), "key"), context.DeadlineExceeded)
+	assert.Error(t, breaker.HealthCheck())
+}
+
+func TestStoreCircuitBreakerServer(t *testing.T) {
+	store := &failingStore{err: errors.New("connection refused")}
+	breaker := gubernator.NewStoreCircuitBreaker(store, gubernator.StoreCircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenDuration:     time.Minute,
+	})
+
+	srv := newV1Server(t, "localhost:0", gubernator.Config{StoreV2: breaker})
+	defer srv.Close()
+	client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	errorsBefore := collectorValue(t, srv.srv, "gubernator_store_error_counter", "Get")
+
+	// Rate limits missing from the cache can not be served while the store fails
+	for i := 0; i < 3; i++ {
+		resp, err := client.GetRateLimits(ctx, &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{
+				{
+					Name:      "test_circuit_breaker",
+					UniqueKey: "account:1234",
+					Duration:  gubernator.Minute,
+					Limit:     10,
+					Hits:      1,
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, resp.Responses[0].Error, "Error in Store.Get")
+	}
+	assert.Equal(t, errorsBefore+3, collectorValue(t, srv.srv, "gubernator_store_error_counter", "Get"))
+	// Only the first call reached the store before the circuit opened
+	assert.Equal(t, 1, store.Calls())
+
+	health, err := client.HealthCheck(ctx, &gubernator.HealthCheckReq{})
+	require.NoError(t, err)
+	assert.Equal(t, gubernator.UnHealthy, health.Status)
+	assert.Contains(t, health.Message, "store circuit breaker is open")
+}
+
+// flakyStore fails Get() while an error is set, otherwise it passes every call to the wrapped store
+type flakyStore struct {
+	gubernator.StoreV2
+	mutex  sync.Mutex
+	getErr error
+}
+
+func (s *flakyStore) SetGetError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.getErr = err
+}
+
+func (s *flakyStore) Get(ctx context.Context, r *gubernator.RateLimitReq) (*gubernator.CacheItem, bool, error) {
+	s.mutex.Lock()
+	err := s.getErr
+	s.mutex.Unlock()
+	if err != nil {
+		return nil, false, err
+	}
+	return s.StoreV2.Get(ctx, r)
+}
+
+func TestStoreGetError(t *testing.T) {
+	mock := gubernator.NewMockStore()
+	store := &flakyStore{StoreV2: gubernator.NewStoreV2Adapter(mock)}
+
+	a := newV1Server(t, "localhost:0", gubernator.Config{StoreV2: store})
+	defer a.Close()
+	assert.Equal(t, int64(7), a.hit(t, "test_store_get_error", "account:1234", 3).Remaining)
+
+	// A second server shares the store, but does not hold the rate limit in its cache
+	b := newV1Server(t, "localhost:0", gubernator.Config{StoreV2: store})
+	defer b.Close()
+	client, err := gubernator.DialV1Server(b.listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	store.SetGetError(errors.New("connection refused"))
+	resp, err := client.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+		Requests: []*gubernator.RateLimitReq{
+			{
+				Name:      "test_store_get_error",
+				UniqueKey: "account:1234",
+				Duration:  gubernator.Minute,
+				Limit:     10,
+				Hits:      1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Responses[0].Error, "connection refused")
+
+	// The failed request did not replace the count held by the store
+	key := "test_store_get_error_account:1234"
+	require.Contains(t, mock.CacheItems, key)
+	assert.Equal(t, int64(7), mock.CacheItems[key].Value.(*gubernator.TokenBucketItem).Remaining)
+
+	// Once the store recovers, the count continues from the store
+	store.SetGetError(nil)
+	assert.Equal(t, int64(6), b.hit(t, "test_store_get_error", "account:1234", 1).Remaining)
+}
@@ -37,6 +37,10 @@ const (
 )
 
 func (m *RateLimitReq) HashKey() string {
+	// Shadow rate limits must not share their state with the enforced rate limit
+	if HasBehavior(m.Behavior, Behavior_SHADOW) {
+		return "shadow:" + m.Name + "_" + m.UniqueKey
+	}
 	return m.Name + "_" + m.UniqueKey
 }
 
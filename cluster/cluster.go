@@ -142,7 +142,12 @@ func Restart(ctx context.Context) error {
 		if err := daemons[i].Start(ctx); err != nil {
 			return err
 		}
-		daemons[i].SetPeers(peers)
+	}
+
+	// Only tell the instances about their peers once they are all running, else
+	// peers could be stuck in connection backoff when the next test begins.
+	for _, d := range daemons {
+		d.SetPeers(peers)
 	}
 	return nil
 }
@@ -159,9 +164,11 @@ func StartWith(localPeers []gubernator.PeerInfo) error {
 			DataCenter:        peer.DataCenter,
 			Behaviors: gubernator.BehaviorConfig{
 				// Suitable for testing but not production
-				GlobalSyncWait: clock.Millisecond * 50,
-				GlobalTimeout:  clock.Second * 5,
-				BatchTimeout:   clock.Second * 5,
+				GlobalSyncWait:      clock.Millisecond * 50,
+				GlobalTimeout:       clock.Second * 5,
+				BatchTimeout:        clock.Second * 5,
+				MultiRegionSyncWait: clock.Millisecond * 50,
+				MultiRegionTimeout:  clock.Second * 5,
 			},
 		})
 		cancel()
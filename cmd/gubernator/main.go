@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"syscall"
 
+	_ "github.com/lib/pq"
 	gubernator "github.com/mailgun/gubernator/v2"
 	"github.com/mailgun/holster/v4/clock"
 	"github.com/mailgun/holster/v4/tracing"
@@ -92,6 +93,14 @@ func main() {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	for range c {
+		if conf.DrainTimeout > 0 {
+			log.Info("caught signal; draining")
+			ctx, cancel := context.WithTimeout(context.Background(), conf.DrainTimeout)
+			if err := daemon.Drain(ctx); err != nil {
+				log.WithError(err).Error("while draining")
+			}
+			cancel()
+		}
 		log.Info("caught signal; shutting down")
 		daemon.Close()
 		_ = tracing.CloseTracing(context.Background())
@@ -67,6 +67,30 @@ type BehaviorConfig struct {
 
 	// Number of concurrent requests that will be made to peers. Defaults to 100
 	GlobalPeerRequestsConcurrency int
+
+	// How long a peer should wait before sending MULTI_REGION hits to the owning peers in other regions
+	MultiRegionSyncWait time.Duration
+	// How long we should wait for MULTI_REGION responses from peers in other regions
+	MultiRegionTimeout time.Duration
+	// The max number of MULTI_REGION hits we can batch into a single peer request
+	MultiRegionBatchLimit int
+
+	// How long we should spend handing off rate limits to their new owners after the peer ring changed
+	HandoffTimeout time.Duration
+	// DisableHandoff disables handing off rate limits to their new owners when the peer ring changes
+	DisableHandoff bool
+
+	// The number of successor peers on the ring which hold a replica of each rate limit, such that a
+	// successor can take over with near current counts when the owner fails. Defaults to 0, which disables replication
+	ReplicationFactor int
+	// How long an owning peer should wait before sending changed rate limits to their replicas
+	ReplicationSyncWait time.Duration
+	// How long we should wait for replication responses from peers
+	ReplicationTimeout time.Duration
+
+	// How long a peer holds the hits reserved for an atomic request before they are aborted, if the
+	// peer which received the request never commits or aborts them
+	ReservationTimeout time.Duration
 }
 
 // Config for a gubernator instance
@@ -88,10 +112,19 @@ type Config struct {
 	// longer than 1 hour.
 	Store Store
 
+	// (Optional) A persistent store implementation which reports errors. Takes the place of Store if both are
+	// provided. Errors returned by the store are counted by the `gubernator_store_error_counter` metric and
+	// otherwise treated as if the rate limit was missing from the store. See StoreCircuitBreaker
+	StoreV2 StoreV2
+
 	// (Optional) A loader from a persistent store. Allows the implementor the ability to load and save
 	// the contents of the cache when the gubernator instance is started and stopped
 	Loader Loader
 
+	// (Optional) How often the contents of the cache are saved through the Loader while the instance
+	// is running. Defaults to 0, which only saves the cache when the instance is stopped.
+	CheckpointInterval time.Duration
+
 	// (Optional) This is the peer picker algorithm the server will use decide which peer in the local cluster
 	// will own the rate limit
 	LocalPicker PeerPicker
@@ -120,6 +153,23 @@ type Config struct {
 
 	// (Optional) The total size of the cache used to store rate limits. Defaults to 50,000
 	CacheSize int
+
+	// (Optional) The estimated memory in bytes used by the cache to store rate limits, which is split
+	// evenly across the workers. When set, the cache is limited by memory instead of CacheSize.
+	// Requires a cache which implements MemoryBoundedCache.
+	CacheMaxBytes int64
+
+	// (Optional) How often each worker removes expired rate limits from its cache, for caches which
+	// implement SweepableCache. Defaults to 1 second, a negative interval disables the sweep.
+	CacheSweepInterval time.Duration
+
+	// (Optional) The maximum number of rate limits each worker examines per sweep, such that a sweep
+	// never blocks requests for long. Defaults to 1,000
+	CacheSweepBatch int
+
+	// (Optional) Named rate limit policies used to fill in requests which only
+	// provide a name, unique key and hits.
+	Policies *PolicyRegistry
 }
 
 func (c *Config) SetDefaults() error {
@@ -133,13 +183,28 @@ func (c *Config) SetDefaults() error {
 
 	setter.SetDefault(&c.Behaviors.GlobalPeerRequestsConcurrency, 100)
 
+	setter.SetDefault(&c.Behaviors.MultiRegionTimeout, time.Millisecond*500)
+	setter.SetDefault(&c.Behaviors.MultiRegionBatchLimit, maxBatchSize)
+	setter.SetDefault(&c.Behaviors.MultiRegionSyncWait, time.Second)
+
+	setter.SetDefault(&c.Behaviors.HandoffTimeout, time.Second*30)
+	setter.SetDefault(&c.Behaviors.ReplicationSyncWait, time.Millisecond*100)
+	setter.SetDefault(&c.Behaviors.ReplicationTimeout, time.Millisecond*500)
+	setter.SetDefault(&c.Behaviors.ReservationTimeout, time.Second*5)
+
 	setter.SetDefault(&c.LocalPicker, NewReplicatedConsistentHash(nil, defaultReplicas))
 	setter.SetDefault(&c.RegionPicker, NewRegionPicker(nil))
 
 	setter.SetDefault(&c.CacheSize, 50_000)
+	setter.SetDefault(&c.CacheSweepInterval, time.Second)
+	setter.SetDefault(&c.CacheSweepBatch, 1_000)
 	setter.SetDefault(&c.Workers, runtime.NumCPU())
 	setter.SetDefault(&c.Logger, logrus.New().WithField("category", "gubernator"))
 
+	if c.StoreV2 == nil && c.Store != nil {
+		c.StoreV2 = NewStoreV2Adapter(c.Store)
+	}
+
 	if c.CacheFactory == nil {
 		c.CacheFactory = func(maxSize int) Cache {
 			return NewLRUCache(maxSize)
@@ -150,6 +215,10 @@ func (c *Config) SetDefaults() error {
 		return fmt.Errorf("Behaviors.BatchLimit cannot exceed '%d'", maxBatchSize)
 	}
 
+	if _, ok := c.LocalPicker.(ReplicaPicker); c.Behaviors.ReplicationFactor > 0 && !ok {
+		return errors.New("Behaviors.ReplicationFactor requires a LocalPicker which implements ReplicaPicker")
+	}
+
 	// Make a copy of the TLS config in case our caller decides to make changes
 	if c.PeerTLS != nil {
 		c.PeerTLS = c.PeerTLS.Clone()
@@ -203,6 +272,20 @@ type DaemonConfig struct {
 	// (Optional) The number of items in the cache. Defaults to 50,000
 	CacheSize int
 
+	// (Optional) The cache implementation used to hold rate limits.
+	//  Valid options are ['lru', 'tinylfu'] (Defaults to 'lru')
+	CacheType string
+
+	// (Optional) The estimated memory in bytes used by the cache. When set, CacheSize is ignored.
+	CacheMaxBytes int64
+
+	// (Optional) How often expired rate limits are removed from the cache. Defaults to 1s,
+	//  a negative interval disables the sweep.
+	CacheSweepInterval time.Duration
+
+	// (Optional) The maximum number of rate limits examined by each worker per sweep. Defaults to 1,000
+	CacheSweepBatch int
+
 	// (Optional) The number of go routine workers used to process concurrent rate limit requests
 	// Defaults to the number of CPUs returned by runtime.NumCPU()
 	Workers int
@@ -249,6 +332,50 @@ type DaemonConfig struct {
 	// (Optional) TraceLevel sets the tracing level, this controls the number of spans included in a single trace.
 	//  Valid options are (tracing.InfoLevel, tracing.DebugLevel) Defaults to tracing.InfoLevel
 	TraceLevel tracing.Level
+
+	// (Optional) Path to a YAML file of named rate limit policies. See `Policy` for details.
+	PolicyFile string
+
+	// (Optional) How often the PolicyFile is checked for changes. Defaults to 5 seconds
+	PolicyReloadInterval time.Duration
+
+	// (Optional) The persistent store which is kept up to date as rate limits change.
+	//  Valid options are ['', 'redis', 'sql'] (Defaults to '', which disables the store)
+	StoreType string
+
+	// (Optional) The persistent store rate limits are loaded from on startup and saved to on shutdown.
+	//  Valid options are ['', 'redis', 'sql', 'file'] (Defaults to '', which disables the loader)
+	LoaderType string
+
+	// (Optional) Redis configuration used when StoreType or LoaderType is 'redis'
+	RedisConf RedisConfig
+
+	// (Optional) Database configuration used when StoreType or LoaderType is 'sql'
+	SQLConf SQLConfig
+
+	// (Optional) Circuit breaker configuration for the StoreType store. See `StoreCircuitBreaker`
+	StoreBreakerConf StoreCircuitBreakerConfig
+
+	// (Optional) Write changes to the StoreType store in the background. See `WriteBehindStore`
+	StoreWriteBehind bool
+
+	// (Optional) Write behind configuration used when StoreWriteBehind is true
+	WriteBehindConf WriteBehindConfig
+
+	// (Optional) Snapshot file configuration used when LoaderType is 'file'
+	FileLoaderConf FileLoaderConfig
+
+	// (Optional) How often the cache is saved through the loader while running.
+	//  Defaults to 0, which only saves the cache on shutdown.
+	CheckpointInterval time.Duration
+
+	// (Optional) How long to wait on shutdown while the rate limits owned by this instance are
+	//  handed off to the remaining peers. Defaults to 0, which drops the cache without draining.
+	DrainTimeout time.Duration
+
+	// (Optional) How long /v1/Ready fails before the rate limits are handed off, such that load
+	//  balancers and peers stop routing to this instance first. Defaults to 0
+	DrainReadinessDelay time.Duration
 }
 
 func (d *DaemonConfig) ClientTLS() *tls.Config {
@@ -318,10 +445,22 @@ func SetupDaemonConfig(logger *logrus.Logger, configFile io.Reader) (DaemonConfi
 	setter.SetDefault(&conf.HTTPStatusListenAddress, os.Getenv("GUBER_STATUS_HTTP_ADDRESS"), "")
 	setter.SetDefault(&conf.GRPCMaxConnectionAgeSeconds, getEnvInteger(log, "GUBER_GRPC_MAX_CONN_AGE_SEC"), 0)
 	setter.SetDefault(&conf.CacheSize, getEnvInteger(log, "GUBER_CACHE_SIZE"), 50_000)
+	setter.SetDefault(&conf.CacheType, os.Getenv("GUBER_CACHE_TYPE"), "lru")
+	setter.SetDefault(&conf.CacheMaxBytes, getEnvBytes(log, "GUBER_CACHE_MAX_BYTES"))
+	setter.SetDefault(&conf.CacheSweepInterval, getEnvDuration(log, "GUBER_CACHE_SWEEP_INTERVAL"), time.Second)
+	setter.SetDefault(&conf.CacheSweepBatch, getEnvInteger(log, "GUBER_CACHE_SWEEP_BATCH"), 1_000)
+	cacheChoices := []string{"lru", "tinylfu"}
+	if !slice.ContainsString(conf.CacheType, cacheChoices, nil) {
+		return conf, fmt.Errorf("GUBER_CACHE_TYPE is invalid; choices are [%s]", strings.Join(cacheChoices, ","))
+	}
 	setter.SetDefault(&conf.Workers, getEnvInteger(log, "GUBER_WORKER_COUNT"), 0)
 	setter.SetDefault(&conf.AdvertiseAddress, os.Getenv("GUBER_ADVERTISE_ADDRESS"), conf.GRPCListenAddress)
 	setter.SetDefault(&conf.DataCenter, os.Getenv("GUBER_DATA_CENTER"), "")
 	setter.SetDefault(&conf.MetricFlags, getEnvMetricFlags(log, "GUBER_METRIC_FLAGS"))
+	setter.SetDefault(&conf.PolicyFile, os.Getenv("GUBER_POLICY_FILE"))
+	setter.SetDefault(&conf.PolicyReloadInterval, getEnvDuration(log, "GUBER_POLICY_RELOAD_INTERVAL"), time.Second*5)
+	setter.SetDefault(&conf.DrainTimeout, getEnvDuration(log, "GUBER_DRAIN_TIMEOUT"))
+	setter.SetDefault(&conf.DrainReadinessDelay, getEnvDuration(log, "GUBER_DRAIN_READINESS_DELAY"))
 
 	choices := []string{"member-list", "k8s", "etcd", "dns"}
 	setter.SetDefault(&conf.PeerDiscoveryType, os.Getenv("GUBER_PEER_DISCOVERY_TYPE"), "member-list")
@@ -353,6 +492,64 @@ func SetupDaemonConfig(logger *logrus.Logger, configFile io.Reader) (DaemonConfi
 	setter.SetDefault(&conf.Behaviors.GlobalSyncWait, getEnvDuration(log, "GUBER_GLOBAL_SYNC_WAIT"))
 	setter.SetDefault(&conf.Behaviors.ForceGlobal, getEnvBool(log, "GUBER_FORCE_GLOBAL"))
 
+	setter.SetDefault(&conf.Behaviors.MultiRegionTimeout, getEnvDuration(log, "GUBER_MULTI_REGION_TIMEOUT"))
+	setter.SetDefault(&conf.Behaviors.MultiRegionBatchLimit, getEnvInteger(log, "GUBER_MULTI_REGION_BATCH_LIMIT"))
+	setter.SetDefault(&conf.Behaviors.MultiRegionSyncWait, getEnvDuration(log, "GUBER_MULTI_REGION_SYNC_WAIT"))
+
+	setter.SetDefault(&conf.Behaviors.HandoffTimeout, getEnvDuration(log, "GUBER_HANDOFF_TIMEOUT"))
+	setter.SetDefault(&conf.Behaviors.DisableHandoff, getEnvBool(log, "GUBER_DISABLE_HANDOFF"))
+
+	setter.SetDefault(&conf.Behaviors.ReplicationFactor, getEnvInteger(log, "GUBER_REPLICATION_FACTOR"))
+	setter.SetDefault(&conf.Behaviors.ReplicationSyncWait, getEnvDuration(log, "GUBER_REPLICATION_SYNC_WAIT"))
+	setter.SetDefault(&conf.Behaviors.ReplicationTimeout, getEnvDuration(log, "GUBER_REPLICATION_TIMEOUT"))
+
+	setter.SetDefault(&conf.Behaviors.ReservationTimeout, getEnvDuration(log, "GUBER_RESERVATION_TIMEOUT"))
+
+	// Persistence Config
+	storeChoices := []string{"", "redis", "sql"}
+	setter.SetDefault(&conf.StoreType, os.Getenv("GUBER_STORE_TYPE"))
+	if !slice.ContainsString(conf.StoreType, storeChoices, nil) {
+		return conf, fmt.Errorf("GUBER_STORE_TYPE is invalid; choices are [%s]", strings.Join(storeChoices, ","))
+	}
+	setter.SetDefault(&conf.LoaderType, os.Getenv("GUBER_LOADER_TYPE"))
+	loaderChoices := []string{"", "redis", "sql", "file"}
+	if !slice.ContainsString(conf.LoaderType, loaderChoices, nil) {
+		return conf, fmt.Errorf("GUBER_LOADER_TYPE is invalid; choices are [%s]", strings.Join(loaderChoices, ","))
+	}
+
+	setter.SetDefault(&conf.RedisConf.Address, os.Getenv("GUBER_REDIS_ADDRESS"), "localhost:6379")
+	setter.SetDefault(&conf.RedisConf.Username, os.Getenv("GUBER_REDIS_USERNAME"))
+	setter.SetDefault(&conf.RedisConf.Password, os.Getenv("GUBER_REDIS_PASSWORD"))
+	setter.SetDefault(&conf.RedisConf.DB, getEnvInteger(log, "GUBER_REDIS_DB"))
+	setter.SetDefault(&conf.RedisConf.KeyPrefix, os.Getenv("GUBER_REDIS_KEY_PREFIX"), "gubernator:")
+	setter.SetDefault(&conf.RedisConf.Timeout, getEnvDuration(log, "GUBER_REDIS_TIMEOUT"), time.Millisecond*500)
+
+	setter.SetDefault(&conf.SQLConf.Driver, os.Getenv("GUBER_SQL_DRIVER"), "postgres")
+	setter.SetDefault(&conf.SQLConf.DSN, os.Getenv("GUBER_SQL_DSN"))
+	setter.SetDefault(&conf.SQLConf.Table, os.Getenv("GUBER_SQL_TABLE"), "gubernator_rate_limits")
+	setter.SetDefault(&conf.SQLConf.Timeout, getEnvDuration(log, "GUBER_SQL_TIMEOUT"), time.Millisecond*500)
+	setter.SetDefault(&conf.SQLConf.CleanupInterval, getEnvDuration(log, "GUBER_SQL_CLEANUP_INTERVAL"), time.Minute)
+	if (conf.StoreType == "sql" || conf.LoaderType == "sql") && conf.SQLConf.DSN == "" {
+		return conf, errors.New("GUBER_SQL_DSN is required when GUBER_STORE_TYPE or GUBER_LOADER_TYPE is 'sql'")
+	}
+
+	setter.SetDefault(&conf.StoreBreakerConf.FailureThreshold, getEnvInteger(log, "GUBER_STORE_BREAKER_THRESHOLD"))
+	setter.SetDefault(&conf.StoreBreakerConf.OpenDuration, getEnvDuration(log, "GUBER_STORE_BREAKER_OPEN_DURATION"))
+	setter.SetDefault(&conf.StoreBreakerConf.Timeout, getEnvDuration(log, "GUBER_STORE_BREAKER_TIMEOUT"))
+
+	setter.SetDefault(&conf.StoreWriteBehind, getEnvBool(log, "GUBER_STORE_WRITE_BEHIND"))
+	setter.SetDefault(&conf.WriteBehindConf.FlushInterval, getEnvDuration(log, "GUBER_WRITE_BEHIND_FLUSH_INTERVAL"))
+	setter.SetDefault(&conf.WriteBehindConf.BatchSize, getEnvInteger(log, "GUBER_WRITE_BEHIND_BATCH_SIZE"))
+	setter.SetDefault(&conf.WriteBehindConf.MaxPending, getEnvInteger(log, "GUBER_WRITE_BEHIND_MAX_PENDING"))
+	setter.SetDefault(&conf.WriteBehindConf.FlushTimeout, getEnvDuration(log, "GUBER_WRITE_BEHIND_FLUSH_TIMEOUT"))
+
+	setter.SetDefault(&conf.FileLoaderConf.Path, os.Getenv("GUBER_SNAPSHOT_FILE"))
+	setter.SetDefault(&conf.FileLoaderConf.DisableFsync, getEnvBool(log, "GUBER_SNAPSHOT_DISABLE_FSYNC"))
+	if conf.LoaderType == "file" && conf.FileLoaderConf.Path == "" {
+		return conf, errors.New("GUBER_SNAPSHOT_FILE is required when GUBER_LOADER_TYPE is 'file'")
+	}
+	setter.SetDefault(&conf.CheckpointInterval, getEnvDuration(log, "GUBER_CHECKPOINT_INTERVAL"))
+
 	// TLS Config
 	if anyHasPrefix("GUBER_TLS_", os.Environ()) {
 		conf.TLS = &TLSConfig{}
@@ -622,6 +819,40 @@ func getEnvDuration(log logrus.FieldLogger, name string) time.Duration {
 	return d
 }
 
+// byteUnits are the suffixes accepted by getEnvBytes
+var byteUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	// Binary suffixes must be tried before the decimal suffixes they end with
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30},
+	{"K", 1_000}, {"M", 1_000_000}, {"G", 1_000_000_000},
+}
+
+// getEnvBytes parses a size in bytes, which may have a decimal (K, M, G) or binary (Ki, Mi, Gi) suffix
+func getEnvBytes(log logrus.FieldLogger, name string) int64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0
+	}
+	multiplier := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, multiplier = strings.TrimSuffix(v, u.suffix), u.multiplier
+			break
+		}
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err == nil && i < 0 {
+		err = errors.New("size cannot be negative")
+	}
+	if err != nil {
+		log.WithError(err).Errorf("while parsing '%s' as a size in bytes", name)
+		return 0
+	}
+	return i * multiplier
+}
+
 func getEnvSlice(name string) []string {
 	v := os.Getenv(name)
 	if v == "" {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
@@ -38,3 +39,162 @@ func TestDefaultInstanceId(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, daemonConfig.InstanceID)
 }
+
+func TestRedisStoreConfig(t *testing.T) {
+	os.Clearenv()
+	s := `
+GUBER_STORE_TYPE=redis
+GUBER_REDIS_ADDRESS=10.10.10.10:6379
+GUBER_REDIS_DB=2
+GUBER_REDIS_KEY_PREFIX=limits:
+GUBER_STORE_BREAKER_THRESHOLD=3
+GUBER_STORE_BREAKER_OPEN_DURATION=30s`
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(s))
+	require.NoError(t, err)
+	require.Equal(t, "redis", daemonConfig.StoreType)
+	require.Equal(t, "", daemonConfig.LoaderType)
+	require.Equal(t, "10.10.10.10:6379", daemonConfig.RedisConf.Address)
+	require.Equal(t, 2, daemonConfig.RedisConf.DB)
+	require.Equal(t, "limits:", daemonConfig.RedisConf.KeyPrefix)
+	require.Equal(t, 3, daemonConfig.StoreBreakerConf.FailureThreshold)
+	require.Equal(t, 30*time.Second, daemonConfig.StoreBreakerConf.OpenDuration)
+
+	os.Clearenv()
+	_, err = SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_STORE_TYPE=unknown"))
+	require.Error(t, err)
+}
+
+func TestSQLStoreConfig(t *testing.T) {
+	os.Clearenv()
+	s := `
+GUBER_STORE_TYPE=sql
+GUBER_LOADER_TYPE=sql
+GUBER_SQL_DSN=postgres://localhost/gubernator
+GUBER_SQL_CLEANUP_INTERVAL=5m`
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(s))
+	require.NoError(t, err)
+	require.Equal(t, "sql", daemonConfig.StoreType)
+	require.Equal(t, "sql", daemonConfig.LoaderType)
+	require.Equal(t, "postgres", daemonConfig.SQLConf.Driver)
+	require.Equal(t, "postgres://localhost/gubernator", daemonConfig.SQLConf.DSN)
+	require.Equal(t, "gubernator_rate_limits", daemonConfig.SQLConf.Table)
+	require.Equal(t, 5*time.Minute, daemonConfig.SQLConf.CleanupInterval)
+
+	os.Clearenv()
+	_, err = SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_STORE_TYPE=sql"))
+	require.Error(t, err)
+}
+
+func TestCacheTypeConfig(t *testing.T) {
+	os.Clearenv()
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(""))
+	require.NoError(t, err)
+	require.Equal(t, "lru", daemonConfig.CacheType)
+
+	os.Clearenv()
+	daemonConfig, err = SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_CACHE_TYPE=tinylfu"))
+	require.NoError(t, err)
+	require.Equal(t, "tinylfu", daemonConfig.CacheType)
+
+	os.Clearenv()
+	_, err = SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_CACHE_TYPE=fifo"))
+	require.Error(t, err)
+}
+
+func TestCacheMaxBytesConfig(t *testing.T) {
+	for _, tt := range []struct {
+		value    string
+		expected int64
+	}{
+		{value: "", expected: 0},
+		{value: "1048576", expected: 1 << 20},
+		{value: "512K", expected: 512_000},
+		{value: "512Mi", expected: 512 << 20},
+		{value: "2G", expected: 2_000_000_000},
+		{value: "2Gi", expected: 2 << 30},
+		{value: "lots", expected: 0},
+		{value: "-1M", expected: 0},
+	} {
+		os.Clearenv()
+		daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_CACHE_MAX_BYTES="+tt.value))
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, daemonConfig.CacheMaxBytes, tt.value)
+	}
+}
+
+func TestCacheSweepConfig(t *testing.T) {
+	os.Clearenv()
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(""))
+	require.NoError(t, err)
+	require.Equal(t, time.Second, daemonConfig.CacheSweepInterval)
+	require.Equal(t, 1_000, daemonConfig.CacheSweepBatch)
+
+	os.Clearenv()
+	s := `
+GUBER_CACHE_SWEEP_INTERVAL=-1s
+GUBER_CACHE_SWEEP_BATCH=50`
+	daemonConfig, err = SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(s))
+	require.NoError(t, err)
+	require.Equal(t, -time.Second, daemonConfig.CacheSweepInterval)
+	require.Equal(t, 50, daemonConfig.CacheSweepBatch)
+}
+
+func TestReplicationConfig(t *testing.T) {
+	os.Clearenv()
+	s := `
+GUBER_REPLICATION_FACTOR=2
+GUBER_REPLICATION_SYNC_WAIT=50ms`
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(s))
+	require.NoError(t, err)
+	require.Equal(t, 2, daemonConfig.Behaviors.ReplicationFactor)
+	require.Equal(t, 50*time.Millisecond, daemonConfig.Behaviors.ReplicationSyncWait)
+
+	require.NoError(t, (&Config{Behaviors: BehaviorConfig{ReplicationFactor: 1}}).SetDefaults())
+
+	// Embedding the interface hides ReplicatedConsistentHash.GetN()
+	type picker struct{ PeerPicker }
+	conf := Config{
+		Behaviors:   BehaviorConfig{ReplicationFactor: 1},
+		LocalPicker: picker{NewReplicatedConsistentHash(nil, defaultReplicas)},
+	}
+	require.Error(t, conf.SetDefaults())
+}
+
+func TestReservationConfig(t *testing.T) {
+	conf := Config{}
+	require.NoError(t, conf.SetDefaults())
+	require.Equal(t, 5*time.Second, conf.Behaviors.ReservationTimeout)
+
+	os.Clearenv()
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_RESERVATION_TIMEOUT=1s"))
+	require.NoError(t, err)
+	require.Equal(t, time.Second, daemonConfig.Behaviors.ReservationTimeout)
+}
+
+func TestDrainConfig(t *testing.T) {
+	os.Clearenv()
+	s := `
+GUBER_DRAIN_TIMEOUT=20s
+GUBER_DRAIN_READINESS_DELAY=5s`
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(s))
+	require.NoError(t, err)
+	require.Equal(t, 20*time.Second, daemonConfig.DrainTimeout)
+	require.Equal(t, 5*time.Second, daemonConfig.DrainReadinessDelay)
+}
+
+func TestFileLoaderConfig(t *testing.T) {
+	os.Clearenv()
+	s := `
+GUBER_LOADER_TYPE=file
+GUBER_SNAPSHOT_FILE=/tmp/gubernator.snapshot
+GUBER_SNAPSHOT_DISABLE_FSYNC=true`
+	daemonConfig, err := SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader(s))
+	require.NoError(t, err)
+	require.Equal(t, "file", daemonConfig.LoaderType)
+	require.Equal(t, "/tmp/gubernator.snapshot", daemonConfig.FileLoaderConf.Path)
+	require.True(t, daemonConfig.FileLoaderConf.DisableFsync)
+
+	os.Clearenv()
+	_, err = SetupDaemonConfig(logrus.StandardLogger(), strings.NewReader("GUBER_LOADER_TYPE=file"))
+	require.Error(t, err)
+}
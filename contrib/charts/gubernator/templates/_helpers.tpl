@@ -102,6 +102,12 @@ HTTP Port
   value: "{{ include "gubernator.grpc.port" . }}"
 - name: GUBER_K8S_ENDPOINTS_SELECTOR
   value: "app=gubernator"
+{{- if .Values.gubernator.drainTimeout }}
+- name: GUBER_DRAIN_TIMEOUT
+  value: "{{ .Values.gubernator.drainTimeout }}"
+- name: GUBER_DRAIN_READINESS_DELAY
+  value: "{{ .Values.gubernator.drainReadinessDelay | default "5s" }}"
+{{- end }}
 {{- if .Values.gubernator.debug }}
 - name: GUBER_DEBUG
   value: "true"
@@ -24,10 +24,13 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/mailgun/holster/v4/clock"
 	"github.com/mailgun/holster/v4/errors"
 	"github.com/mailgun/holster/v4/etcdutil"
 	"github.com/mailgun/holster/v4/setter"
@@ -43,6 +46,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type Daemon struct {
@@ -64,7 +68,13 @@ type Daemon struct {
 	promRegister  *prometheus.Registry
 	gwCancel      context.CancelFunc
 	instanceConf  Config
+	policyWatcher *PolicyFileWatcher
+	redisStore    *RedisStore
+	sqlStore      *SQLStore
+	writeBehind   *WriteBehindStore
 	client        V1Client
+	// Set by Drain(), after which /v1/Ready fails
+	draining atomic.Bool
 }
 
 // SpawnDaemon starts a new gubernator daemon according to the provided DaemonConfig.
@@ -90,14 +100,20 @@ func (s *Daemon) Start(ctx context.Context) error {
 
 	s.promRegister = prometheus.NewRegistry()
 
-	// The LRU cache for storing rate limits.
+	// The cache for storing rate limits.
 	cacheCollector := NewLRUCacheCollector()
 	if err := s.promRegister.Register(cacheCollector); err != nil {
 		return errors.Wrap(err, "during call to promRegister.Register()")
 	}
 
 	cacheFactory := func(maxSize int) Cache {
-		cache := NewLRUCache(maxSize)
+		var cache Cache
+		switch s.conf.CacheType {
+		case "tinylfu":
+			cache = NewTinyLFUCache(maxSize)
+		default:
+			cache = NewLRUCache(maxSize)
+		}
 		cacheCollector.AddCache(cache)
 		return cache
 	}
@@ -154,8 +170,82 @@ func (s *Daemon) Start(ctx context.Context) error {
 		CacheFactory:  cacheFactory,
 		Behaviors:     s.conf.Behaviors,
 		CacheSize:     s.conf.CacheSize,
+		CacheMaxBytes: s.conf.CacheMaxBytes,
 		Workers:       s.conf.Workers,
 		InstanceID:    s.conf.InstanceID,
+
+		CheckpointInterval: s.conf.CheckpointInterval,
+		CacheSweepInterval: s.conf.CacheSweepInterval,
+		CacheSweepBatch:    s.conf.CacheSweepBatch,
+	}
+
+	if s.conf.PolicyFile != "" {
+		s.instanceConf.Policies = NewPolicyRegistry(nil)
+		s.policyWatcher, err = NewPolicyFileWatcher(s.log, s.conf.PolicyFile,
+			s.conf.PolicyReloadInterval, s.instanceConf.Policies)
+		if err != nil {
+			return errors.Wrap(err, "while loading policies")
+		}
+		s.log.Infof("Loaded %d policies from '%s'", s.instanceConf.Policies.Len(), s.conf.PolicyFile)
+	}
+
+	if s.conf.StoreType == "redis" || s.conf.LoaderType == "redis" {
+		redisConf := s.conf.RedisConf
+		setter.SetDefault(&redisConf.Logger, s.log)
+		s.redisStore, err = NewRedisStore(redisConf)
+		if err != nil {
+			return errors.Wrap(err, "while creating redis store")
+		}
+		if s.conf.StoreType == "redis" {
+			s.instanceConf.StoreV2 = s.redisStore
+		}
+		if s.conf.LoaderType == "redis" {
+			s.instanceConf.Loader = s.redisStore
+		}
+	}
+
+	if s.conf.StoreType == "sql" || s.conf.LoaderType == "sql" {
+		sqlConf := s.conf.SQLConf
+		setter.SetDefault(&sqlConf.Logger, s.log)
+		s.sqlStore, err = NewSQLStore(sqlConf)
+		if err != nil {
+			return errors.Wrap(err, "while creating sql store")
+		}
+		if s.conf.StoreType == "sql" {
+			s.instanceConf.StoreV2 = s.sqlStore
+		}
+		if s.conf.LoaderType == "sql" {
+			s.instanceConf.Loader = s.sqlStore
+		}
+	}
+
+	if s.instanceConf.StoreV2 != nil {
+		breakerConf := s.conf.StoreBreakerConf
+		setter.SetDefault(&breakerConf.Logger, s.log)
+		breaker := NewStoreCircuitBreaker(s.instanceConf.StoreV2, breakerConf)
+		s.instanceConf.StoreV2 = breaker
+		if err := s.promRegister.Register(breaker); err != nil {
+			return errors.Wrap(err, "during call to promRegister.Register()")
+		}
+	}
+
+	if s.conf.StoreWriteBehind && s.instanceConf.StoreV2 != nil {
+		writeBehindConf := s.conf.WriteBehindConf
+		setter.SetDefault(&writeBehindConf.Logger, s.log)
+		s.writeBehind = NewWriteBehindStore(s.instanceConf.StoreV2, writeBehindConf)
+		s.instanceConf.StoreV2 = s.writeBehind
+		if err := s.promRegister.Register(s.writeBehind); err != nil {
+			return errors.Wrap(err, "during call to promRegister.Register()")
+		}
+	}
+
+	if s.conf.LoaderType == "file" {
+		fileConf := s.conf.FileLoaderConf
+		setter.SetDefault(&fileConf.Logger, s.log)
+		s.instanceConf.Loader, err = NewFileLoader(fileConf)
+		if err != nil {
+			return errors.Wrap(err, "while creating snapshot loader")
+		}
 	}
 
 	s.V1Server, err = NewV1Instance(s.instanceConf)
@@ -258,6 +348,7 @@ func (s *Daemon) Start(ctx context.Context) error {
 				DiscardUnknown: true,
 			},
 		}),
+		runtime.WithForwardResponseOption(setRetryAfterHeader),
 	)
 
 	// Set up an JSON Gateway API for our GRPC methods
@@ -289,6 +380,7 @@ func (s *Daemon) Start(ctx context.Context) error {
 	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
 		s.promRegister, promhttp.HandlerFor(s.promRegister, promhttp.HandlerOpts{}),
 	))
+	mux.HandleFunc("/v1/Ready", s.handleReady)
 	mux.Handle("/", gateway)
 	s.logWriter = newLogWriter(s.log)
 	log := log.New(s.logWriter, "", 0)
@@ -309,6 +401,7 @@ func (s *Daemon) Start(ctx context.Context) error {
 		if s.conf.HTTPStatusListenAddress != "" {
 			muxNoMTLS := http.NewServeMux()
 			muxNoMTLS.Handle("/v1/HealthCheck", gateway)
+			muxNoMTLS.HandleFunc("/v1/Ready", s.handleReady)
 			s.httpSrvNoMTLS = &http.Server{
 				Addr:      s.conf.HTTPStatusListenAddress,
 				Handler:   muxNoMTLS,
@@ -365,6 +458,37 @@ func (s *Daemon) Start(ctx context.Context) error {
 	return nil
 }
 
+// handleReady responds with 503 once the instance is draining, such that load balancers
+// and peer discovery stop routing requests to it before it shuts down.
+func (s *Daemon) handleReady(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() || s.V1Server.IsDraining() {
+		http.Error(w, "draining", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+// Drain hands off the rate limits owned by this instance to the remaining peers in preparation
+// for Close(). /v1/Ready fails first, and the instance continues to own its rate limits until
+// DrainReadinessDelay has elapsed, such that load balancers and peer discovery stop routing to
+// it before the rate limits are handed off.
+func (s *Daemon) Drain(ctx context.Context) error {
+	if s.V1Server == nil {
+		return nil
+	}
+	s.draining.Store(true)
+
+	select {
+	case <-clock.After(s.conf.DrainReadinessDelay):
+	case <-ctx.Done():
+	}
+
+	if err := s.V1Server.Drain(ctx); err != nil {
+		return errors.Wrap(err, "while draining")
+	}
+	return nil
+}
+
 // Close gracefully closes all server connections and listening sockets
 func (s *Daemon) Close() {
 	if s.httpSrv == nil && s.httpSrvNoMTLS == nil {
@@ -387,6 +511,22 @@ func (s *Daemon) Close() {
 	}
 	s.logWriter.Close()
 	_ = s.V1Server.Close()
+	if s.writeBehind != nil {
+		s.writeBehind.Close()
+		s.writeBehind = nil
+	}
+	if s.redisStore != nil {
+		_ = s.redisStore.Close()
+		s.redisStore = nil
+	}
+	if s.sqlStore != nil {
+		_ = s.sqlStore.Close()
+		s.sqlStore = nil
+	}
+	if s.policyWatcher != nil {
+		s.policyWatcher.Close()
+		s.policyWatcher = nil
+	}
 	s.wg.Stop()
 	s.statsHandler.Close()
 	s.gwCancel()
@@ -446,6 +586,29 @@ func (s *Daemon) Client() (V1Client, error) {
 	return s.client, nil
 }
 
+// setRetryAfterHeader sets the `Retry-After` header on gateway responses when any of the
+// rate limits are over the limit, using the longest wait of all the rate limits.
+func setRetryAfterHeader(_ context.Context, w http.ResponseWriter, m proto.Message) error {
+	resp, ok := m.(*GetRateLimitsResp)
+	if !ok {
+		return nil
+	}
+
+	var retryAfter int64
+	for _, rl := range resp.Responses {
+		if rl.Status == Status_OVER_LIMIT && rl.RetryAfter > retryAfter {
+			retryAfter = rl.RetryAfter
+		}
+	}
+	if retryAfter == 0 {
+		return nil
+	}
+
+	// Retry-After is in seconds, round up so clients do not retry too early
+	w.Header().Set("Retry-After", strconv.FormatInt((retryAfter+999)/1000, 10))
+	return nil
+}
+
 // WaitForConnect returns nil if the list of addresses is listening
 // for connections; will block until context is cancelled.
 func WaitForConnect(ctx context.Context, addresses []string) error {
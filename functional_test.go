@@ -298,6 +298,50 @@ func TestTokenBucketGregorian(t *testing.T) {
 	}
 }
 
+func TestTokenBucketGregorianTimeZone(t *testing.T) {
+	newYork, err := clock.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// 22:00 on the 10th in New York, but already the 11th in UTC
+	defer clock.Freeze(clock.Date(2019, clock.November, 11, 3, 0, 0, 0, clock.UTC)).Unfreeze()
+
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.NoError(t, err)
+
+	sendHit := func(timeZone string, duration int64) *guber.RateLimitResp {
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      "test_token_bucket_greg_tz",
+					UniqueKey: guber.RandomString(10),
+					Behavior:  guber.Behavior_DURATION_IS_GREGORIAN,
+					Algorithm: guber.Algorithm_TOKEN_BUCKET,
+					Duration:  duration,
+					Hits:      1,
+					Limit:     60,
+					TimeZone:  timeZone,
+					WeekStart: int32(clock.Monday),
+				},
+			},
+		})
+		require.NoError(t, err)
+		return resp.Responses[0]
+	}
+
+	rl := sendHit("America/New_York", guber.GregorianDays)
+	assert.Empty(t, rl.Error)
+	assert.Equal(t, clock.Date(2019, clock.November, 10, 23, 59, 59, 999000000, newYork),
+		clock.Unix(0, rl.ResetTime*int64(clock.Millisecond)).In(newYork))
+
+	// Sunday the 10th is the last day of the week beginning on Monday in New York
+	rl = sendHit("America/New_York", guber.GregorianWeeks)
+	assert.Empty(t, rl.Error)
+	assert.Equal(t, clock.Date(2019, clock.November, 10, 23, 59, 59, 999000000, newYork),
+		clock.Unix(0, rl.ResetTime*int64(clock.Millisecond)).In(newYork))
+
+	rl = sendHit("Mars/Olympus_Mons", guber.GregorianDays)
+	assert.Contains(t, rl.Error, "time zone 'Mars/Olympus_Mons' is not a valid IANA time zone")
+}
+
 func TestTokenBucketNegativeHits(t *testing.T) {
 	defer clock.Freeze(clock.Now()).Unfreeze()
 
@@ -313,25 +357,25 @@ func TestTokenBucketNegativeHits(t *testing.T) {
 		Hits      int64
 	}{
 		{
-			name:      "remaining should be three",
-			Remaining: 3,
+			name:      "refund should not raise remaining above the limit of a new bucket",
+			Remaining: 2,
 			Status:    guber.Status_UNDER_LIMIT,
 			Sleep:     clock.Duration(0),
 			Hits:      -1,
 		},
 		{
-			name:      "remaining should be four and under limit",
-			Remaining: 4,
+			name:      "remaining should be 0 and under limit",
+			Remaining: 0,
 			Status:    guber.Status_UNDER_LIMIT,
 			Sleep:     clock.Duration(0),
-			Hits:      -1,
+			Hits:      2,
 		},
 		{
-			name:      "remaining should be 0 and under limit",
+			name:      "should be over the limit",
 			Remaining: 0,
-			Status:    guber.Status_UNDER_LIMIT,
+			Status:    guber.Status_OVER_LIMIT,
 			Sleep:     clock.Duration(0),
-			Hits:      4,
+			Hits:      1,
 		},
 		{
 			name:      "remaining should be 1 and under limit",
@@ -340,6 +384,20 @@ func TestTokenBucketNegativeHits(t *testing.T) {
 			Sleep:     clock.Duration(0),
 			Hits:      -1,
 		},
+		{
+			name:      "should no longer report over the limit after a refund",
+			Remaining: 1,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+			Hits:      0,
+		},
+		{
+			name:      "refund should not raise remaining above the limit",
+			Remaining: 2,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+			Hits:      -5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -824,6 +882,13 @@ func TestLeakyBucketNegativeHits(t *testing.T) {
 			Status:    guber.Status_UNDER_LIMIT,
 			Sleep:     clock.Duration(0),
 		},
+		{
+			Name:      "cannot increase remaining above the burst",
+			Hits:      -20,
+			Remaining: 10,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
 	}
 
 	for _, test := range tests {
@@ -898,107 +963,557 @@ func TestLeakyBucketRequestMoreThanAvailable(t *testing.T) {
 	sendHit(guber.Status_OVER_LIMIT, 0, 1)
 }
 
-func TestMissingFields(t *testing.T) {
-	client, errs := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+func TestSlidingWindow(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
+
+	addr := cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress
+	client, errs := guber.DialV1Server(addr, nil)
 	require.Nil(t, errs)
 
 	tests := []struct {
-		Req    *guber.RateLimitReq
-		Status guber.Status
-		Error  string
+		name      string
+		Hits      int64
+		Remaining int64
+		Status    guber.Status
+		Sleep     clock.Duration
 	}{
 		{
-			Req: &guber.RateLimitReq{
-				Name:      "test_missing_fields",
-				UniqueKey: "account:1234",
-				Hits:      1,
-				Limit:     10,
-				Duration:  0,
-			},
-			Error:  "", // No Error
-			Status: guber.Status_UNDER_LIMIT,
+			name:      "remaining should be four",
+			Hits:      6,
+			Remaining: 4,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
 		},
 		{
-			Req: &guber.RateLimitReq{
-				Name:      "test_missing_fields",
-				UniqueKey: "account:12345",
-				Hits:      1,
-				Duration:  10000,
-				Limit:     0,
-			},
-			Error:  "", // No Error
-			Status: guber.Status_OVER_LIMIT,
+			name:      "remaining should be zero and under limit",
+			Hits:      4,
+			Remaining: 0,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
 		},
 		{
-			Req: &guber.RateLimitReq{
-				UniqueKey: "account:1234",
-				Hits:      1,
-				Duration:  10000,
-				Limit:     5,
-			},
-			Error:  "field 'namespace' cannot be empty",
-			Status: guber.Status_UNDER_LIMIT,
+			name:      "should be over the limit",
+			Hits:      1,
+			Remaining: 0,
+			Status:    guber.Status_OVER_LIMIT,
+			Sleep:     clock.Second,
 		},
 		{
-			Req: &guber.RateLimitReq{
-				Name:     "test_missing_fields",
-				Hits:     1,
-				Duration: 10000,
-				Limit:    5,
-			},
-			Error:  "field 'unique_key' cannot be empty",
-			Status: guber.Status_UNDER_LIMIT,
+			name:      "previous window should still count in full at the start of the next window",
+			Hits:      1,
+			Remaining: 0,
+			Status:    guber.Status_OVER_LIMIT,
+			Sleep:     clock.Millisecond * 500,
+		},
+		{
+			name:      "half way through the window half of the previous window should count",
+			Hits:      1,
+			Remaining: 4,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Millisecond * 500,
+		},
+		{
+			name:      "hits from the previous window should carry over",
+			Hits:      1,
+			Remaining: 8,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Second * 2,
+		},
+		{
+			name:      "after skipping a window only the current window should count",
+			Hits:      1,
+			Remaining: 9,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:      "a refund only returns the hits of the current window",
+			Hits:      -5,
+			Remaining: 10,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
 		},
 	}
 
-	for i, test := range tests {
-		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
-			Requests: []*guber.RateLimitReq{test.Req},
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      "test_sliding_window",
+						UniqueKey: "account:1234",
+						Algorithm: guber.Algorithm_SLIDING_WINDOW,
+						Duration:  guber.Second,
+						Limit:     10,
+						Hits:      tt.Hits,
+					},
+				},
+			})
+			require.Nil(t, err)
+
+			rl := resp.Responses[0]
+
+			assert.Empty(t, rl.Error)
+			assert.Equal(t, tt.Status, rl.Status)
+			assert.Equal(t, tt.Remaining, rl.Remaining)
+			assert.Equal(t, int64(10), rl.Limit)
+			assert.True(t, rl.ResetTime != 0)
+			clock.Advance(tt.Sleep)
 		})
-		require.Nil(t, err)
-		assert.Equal(t, test.Error, resp.Responses[0].Error, i)
-		assert.Equal(t, test.Status, resp.Responses[0].Status, i)
 	}
 }
 
-func TestGlobalRateLimits(t *testing.T) {
-	const (
-		name = "test_global"
-		key  = "account:12345"
-	)
+func TestGCRA(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
 
-	peers, err := cluster.ListNonOwningDaemons(name, key)
-	require.NoError(t, err)
+	addr := cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress
+	client, errs := guber.DialV1Server(addr, nil)
+	require.Nil(t, errs)
 
-	sendHit := func(client guber.V1Client, status guber.Status, hits int64, remain int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
-		defer cancel()
-		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
-			Requests: []*guber.RateLimitReq{
-				{
-					Name:      "test_global",
-					UniqueKey: "account:12345",
-					Algorithm: guber.Algorithm_TOKEN_BUCKET,
-					Behavior:  guber.Behavior_GLOBAL,
-					Duration:  guber.Minute * 3,
-					Hits:      hits,
-					Limit:     5,
+	tests := []struct {
+		name      string
+		Hits      int64
+		Remaining int64
+		Status    guber.Status
+		Sleep     clock.Duration
+	}{
+		{
+			name:      "burst should allow the entire limit at once",
+			Hits:      10,
+			Remaining: 0,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:      "should be over the limit",
+			Hits:      1,
+			Remaining: 0,
+			Status:    guber.Status_OVER_LIMIT,
+			Sleep:     clock.Millisecond * 100,
+		},
+		{
+			name:      "after waiting one emission interval a single hit should be accepted",
+			Hits:      1,
+			Remaining: 0,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Millisecond * 500,
+		},
+		{
+			name:      "after waiting 500ms remaining should be 5",
+			Hits:      0,
+			Remaining: 5,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:      "requesting more than remaining should be over the limit",
+			Hits:      6,
+			Remaining: 5,
+			Status:    guber.Status_OVER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:      "requesting the remainder should be under the limit",
+			Hits:      5,
+			Remaining: 0,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      "test_gcra",
+						UniqueKey: "account:1234",
+						Algorithm: guber.Algorithm_GCRA,
+						Duration:  guber.Second,
+						Limit:     10,
+						Hits:      tt.Hits,
+					},
 				},
-			},
+			})
+			require.Nil(t, err)
+
+			rl := resp.Responses[0]
+
+			assert.Empty(t, rl.Error)
+			assert.Equal(t, tt.Status, rl.Status)
+			assert.Equal(t, tt.Remaining, rl.Remaining)
+			assert.Equal(t, int64(10), rl.Limit)
+			assert.True(t, rl.ResetTime != 0)
+			clock.Advance(tt.Sleep)
 		})
-		require.NoError(t, err)
-		assert.Equal(t, "", resp.Responses[0].Error)
-		assert.Equal(t, remain, resp.Responses[0].Remaining)
-		assert.Equal(t, status, resp.Responses[0].Status)
-		assert.Equal(t, int64(5), resp.Responses[0].Limit)
 	}
-	// Our first hit should create the request on the peer and queue for async forward
-	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 1, 4)
+}
 
-	// Our second should be processed as if we own it since the async forward hasn't occurred yet
-	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 2, 2)
+func TestContinuousTokenBucket(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
 
-	testutil.UntilPass(t, 20, clock.Millisecond*200, func(t testutil.TestingT) {
+	addr := cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress
+	client, errs := guber.DialV1Server(addr, nil)
+	require.Nil(t, errs)
+
+	tests := []struct {
+		name       string
+		Hits       int64
+		Remaining  int64
+		RetryAfter int64
+		Status     guber.Status
+		Sleep      clock.Duration
+	}{
+		{
+			name:      "burst should allow twice the limit at once",
+			Hits:      20,
+			Remaining: 0,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:       "should be over the limit until a token is refilled",
+			Hits:       1,
+			Remaining:  0,
+			RetryAfter: 100,
+			Status:     guber.Status_OVER_LIMIT,
+			Sleep:      clock.Millisecond * 100,
+		},
+		{
+			name:      "after waiting 100ms a single hit should be accepted",
+			Hits:      1,
+			Remaining: 0,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Millisecond * 500,
+		},
+		{
+			name:      "after waiting 500ms remaining should be 5",
+			Hits:      0,
+			Remaining: 5,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:       "requesting more than remaining should be over the limit",
+			Hits:       6,
+			Remaining:  5,
+			RetryAfter: 100,
+			Status:     guber.Status_OVER_LIMIT,
+			Sleep:      clock.Second * 10,
+		},
+		{
+			name:      "the bucket refills up to the burst",
+			Hits:      0,
+			Remaining: 20,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:      "take some tokens",
+			Hits:      5,
+			Remaining: 15,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+		{
+			name:      "a refund does not fill the bucket beyond the burst",
+			Hits:      -10,
+			Remaining: 20,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Duration(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      "test_continuous_token_bucket",
+						UniqueKey: "account:1234",
+						Algorithm: guber.Algorithm_CONTINUOUS_TOKEN_BUCKET,
+						Duration:  guber.Second,
+						Limit:     10,
+						Burst:     20,
+						Hits:      tt.Hits,
+					},
+				},
+			})
+			require.Nil(t, err)
+
+			rl := resp.Responses[0]
+
+			assert.Empty(t, rl.Error)
+			assert.Equal(t, tt.Status, rl.Status)
+			assert.Equal(t, tt.Remaining, rl.Remaining)
+			assert.Equal(t, tt.RetryAfter, rl.RetryAfter)
+			assert.Equal(t, int64(10), rl.Limit)
+			// The bucket is full again once the missing tokens are refilled
+			assert.Equal(t, clock.Now().UnixMilli()+(20-rl.Remaining)*100, rl.ResetTime)
+			clock.Advance(tt.Sleep)
+		})
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
+
+	addr := cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress
+	client, errs := guber.DialV1Server(addr, nil)
+	require.Nil(t, errs)
+
+	req := func(hits int64) *guber.RateLimitReq {
+		return &guber.RateLimitReq{
+			Name:      "test_concurrency",
+			UniqueKey: "account:1234",
+			Algorithm: guber.Algorithm_CONCURRENCY,
+			Duration:  guber.Minute,
+			Limit:     2,
+			Hits:      hits,
+		}
+	}
+
+	acquire := func(hits, remaining int64, status guber.Status) {
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{req(hits)},
+		})
+		require.NoError(t, err)
+		rl := resp.Responses[0]
+		assert.Empty(t, rl.Error)
+		assert.Equal(t, status, rl.Status)
+		assert.Equal(t, remaining, rl.Remaining)
+		assert.Equal(t, int64(2), rl.Limit)
+	}
+
+	release := func(hits, remaining int64) {
+		resp, err := client.ReleaseRateLimits(context.Background(), &guber.ReleaseRateLimitsReq{
+			Requests: []*guber.RateLimitReq{req(hits)},
+		})
+		require.NoError(t, err)
+		rl := resp.Responses[0]
+		assert.Empty(t, rl.Error)
+		assert.Equal(t, guber.Status_UNDER_LIMIT, rl.Status)
+		assert.Equal(t, remaining, rl.Remaining)
+	}
+
+	acquire(1, 1, guber.Status_UNDER_LIMIT)
+	acquire(2, 1, guber.Status_OVER_LIMIT)
+	acquire(1, 0, guber.Status_UNDER_LIMIT)
+	acquire(1, 0, guber.Status_OVER_LIMIT)
+
+	release(1, 1)
+	acquire(1, 0, guber.Status_UNDER_LIMIT)
+
+	// Releasing more than was acquired should not exceed the limit
+	release(5, 2)
+	acquire(2, 0, guber.Status_UNDER_LIMIT)
+
+	// Leases expire if they are never released
+	clock.Advance(clock.Minute + clock.Millisecond)
+	acquire(0, 2, guber.Status_UNDER_LIMIT)
+
+	t.Run("release requires the CONCURRENCY algorithm", func(t *testing.T) {
+		r := req(1)
+		r.Algorithm = guber.Algorithm_TOKEN_BUCKET
+		resp, err := client.ReleaseRateLimits(context.Background(), &guber.ReleaseRateLimitsReq{
+			Requests: []*guber.RateLimitReq{r},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "field 'algorithm' must be CONCURRENCY", resp.Responses[0].Error)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
+
+	addr := cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress
+	client, errs := guber.DialV1Server(addr, nil)
+	require.Nil(t, errs)
+
+	tests := []struct {
+		name       string
+		Algorithm  guber.Algorithm
+		Hits       int64
+		Status     guber.Status
+		RetryAfter int64
+		Sleep      clock.Duration
+	}{
+		{
+			name:      "token bucket takes the entire limit",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Hits:      10,
+			Status:    guber.Status_UNDER_LIMIT,
+			Sleep:     clock.Millisecond * 100,
+		},
+		{
+			name:       "token bucket should retry once the window resets",
+			Algorithm:  guber.Algorithm_TOKEN_BUCKET,
+			Hits:       1,
+			Status:     guber.Status_OVER_LIMIT,
+			RetryAfter: 900,
+		},
+		{
+			name:      "token bucket hits more than the limit are never accepted",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Hits:      11,
+			Status:    guber.Status_OVER_LIMIT,
+		},
+		{
+			name:      "leaky bucket takes the entire limit",
+			Algorithm: guber.Algorithm_LEAKY_BUCKET,
+			Hits:      10,
+			Status:    guber.Status_UNDER_LIMIT,
+		},
+		{
+			name:       "leaky bucket should retry once enough hits have leaked",
+			Algorithm:  guber.Algorithm_LEAKY_BUCKET,
+			Hits:       2,
+			Status:     guber.Status_OVER_LIMIT,
+			RetryAfter: 200,
+			Sleep:      clock.Millisecond * 50,
+		},
+		{
+			name:       "leaky bucket should account for the time since the last leak",
+			Algorithm:  guber.Algorithm_LEAKY_BUCKET,
+			Hits:       2,
+			Status:     guber.Status_OVER_LIMIT,
+			RetryAfter: 150,
+		},
+		{
+			name:      "leaky bucket hits more than the burst are never accepted",
+			Algorithm: guber.Algorithm_LEAKY_BUCKET,
+			Hits:      11,
+			Status:    guber.Status_OVER_LIMIT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      "test_retry_after",
+						UniqueKey: "account:" + tt.Algorithm.String(),
+						Algorithm: tt.Algorithm,
+						Duration:  guber.Second,
+						Limit:     10,
+						Hits:      tt.Hits,
+					},
+				},
+			})
+			require.Nil(t, err)
+
+			rl := resp.Responses[0]
+
+			assert.Empty(t, rl.Error)
+			assert.Equal(t, tt.Status, rl.Status)
+			assert.Equal(t, tt.RetryAfter, rl.RetryAfter)
+			clock.Advance(tt.Sleep)
+		})
+	}
+}
+
+func TestMissingFields(t *testing.T) {
+	client, errs := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.Nil(t, errs)
+
+	tests := []struct {
+		Req    *guber.RateLimitReq
+		Status guber.Status
+		Error  string
+	}{
+		{
+			Req: &guber.RateLimitReq{
+				Name:      "test_missing_fields",
+				UniqueKey: "account:1234",
+				Hits:      1,
+				Limit:     10,
+				Duration:  0,
+			},
+			Error:  "", // No Error
+			Status: guber.Status_UNDER_LIMIT,
+		},
+		{
+			Req: &guber.RateLimitReq{
+				Name:      "test_missing_fields",
+				UniqueKey: "account:12345",
+				Hits:      1,
+				Duration:  10000,
+				Limit:     0,
+			},
+			Error:  "", // No Error
+			Status: guber.Status_OVER_LIMIT,
+		},
+		{
+			Req: &guber.RateLimitReq{
+				UniqueKey: "account:1234",
+				Hits:      1,
+				Duration:  10000,
+				Limit:     5,
+			},
+			Error:  "field 'namespace' cannot be empty",
+			Status: guber.Status_UNDER_LIMIT,
+		},
+		{
+			Req: &guber.RateLimitReq{
+				Name:     "test_missing_fields",
+				Hits:     1,
+				Duration: 10000,
+				Limit:    5,
+			},
+			Error:  "field 'unique_key' cannot be empty",
+			Status: guber.Status_UNDER_LIMIT,
+		},
+	}
+
+	for i, test := range tests {
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{test.Req},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, test.Error, resp.Responses[0].Error, i)
+		assert.Equal(t, test.Status, resp.Responses[0].Status, i)
+	}
+}
+
+func TestGlobalRateLimits(t *testing.T) {
+	const (
+		name = "test_global"
+		key  = "account:12345"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(client guber.V1Client, status guber.Status, hits int64, remain int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      "test_global",
+					UniqueKey: "account:12345",
+					Algorithm: guber.Algorithm_TOKEN_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 3,
+					Hits:      hits,
+					Limit:     5,
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].Error)
+		assert.Equal(t, remain, resp.Responses[0].Remaining)
+		assert.Equal(t, status, resp.Responses[0].Status)
+		assert.Equal(t, int64(5), resp.Responses[0].Limit)
+	}
+	// Our first hit should create the request on the peer and queue for async forward
+	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 1, 4)
+
+	// Our second should be processed as if we own it since the async forward hasn't occurred yet
+	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 2, 2)
+
+	testutil.UntilPass(t, 20, clock.Millisecond*200, func(t testutil.TestingT) {
 		// Inspect peers metrics, ensure the peer sent the global rate limit to the owner
 		metricsURL := fmt.Sprintf("http://%s/metrics", peers[0].Config().HTTPListenAddress)
 		m, err := getMetricRequest(metricsURL, "gubernator_global_send_duration_count")
@@ -1023,6 +1538,55 @@ func TestGlobalRateLimits(t *testing.T) {
 	sendHit(peers[4].MustClient(), guber.Status_OVER_LIMIT, 1, 0)
 }
 
+func TestGlobalContinuousTokenBucket(t *testing.T) {
+	const (
+		name = "test_global_continuous_token_bucket"
+		key  = "account:12345"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(t testutil.TestingT, client guber.V1Client, hits, remain int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_CONTINUOUS_TOKEN_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 3,
+					Hits:      hits,
+					Limit:     5,
+					Burst:     10,
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].Error)
+		assert.Equal(t, remain, resp.Responses[0].Remaining)
+	}
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+	broadcasts, err := getBroadcastCount(owner)
+	require.NoError(t, err)
+	sendHit(t, peers[0].MustClient(), 4, 6)
+
+	// Other peers receive the remaining tokens from the owner's broadcast
+	require.NoError(t, waitForBroadcast(clock.Second*3, owner, broadcasts+1))
+	testutil.UntilPass(t, 20, clock.Millisecond*200, func(t testutil.TestingT) {
+		sendHit(t, peers[1].MustClient(), 0, 6)
+	})
+
+	// and refill the bucket at the owner's rate of a token every 36 seconds
+	// before they receive a request for it
+	defer clock.Freeze(clock.Now()).Unfreeze()
+	clock.Advance(clock.Second * 72)
+	sendHit(t, peers[2].MustClient(), 0, 8)
+}
+
 // Ensure global broadcast updates all peers when GetRateLimits is called on
 // either owner or non-owner peer.
 func TestGlobalRateLimitsWithLoadBalancing(t *testing.T) {
@@ -1078,656 +1642,1295 @@ func TestGlobalRateLimitsWithLoadBalancing(t *testing.T) {
 	sendHit(guber.Status_UNDER_LIMIT, 1)
 	sendHit(guber.Status_UNDER_LIMIT, 2)
 
-	// Sleep to ensure the global broadcast occurs (every 100ms).
-	time.Sleep(150 * time.Millisecond)
+	// Sleep to ensure the global broadcast occurs (every 100ms).
+	time.Sleep(150 * time.Millisecond)
+
+	// All successive hits should return OVER_LIMIT.
+	for i := 2; i <= 10; i++ {
+		sendHit(guber.Status_OVER_LIMIT, i)
+	}
+}
+
+func TestGlobalRateLimitsPeerOverLimit(t *testing.T) {
+	const (
+		name = "test_global_token_limit"
+		key  = "account:12345"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(expectedStatus guber.Status, hits int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := peers[0].MustClient().GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_TOKEN_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 5,
+					Hits:      hits,
+					Limit:     2,
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].GetError())
+		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
+	}
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+
+	// Send two hits that should be processed by the owner and the broadcast to peer, depleting the remaining
+	sendHit(guber.Status_UNDER_LIMIT, 1)
+	sendHit(guber.Status_UNDER_LIMIT, 1)
+	// Wait for the broadcast from the owner to the peer
+	require.NoError(t, waitForBroadcast(clock.Second*3, owner, 1))
+	// Since the remainder is 0, the peer should set OVER_LIMIT instead of waiting for the owner
+	// to respond with OVER_LIMIT.
+	sendHit(guber.Status_OVER_LIMIT, 1)
+	// Wait for the broadcast from the owner to the peer
+	require.NoError(t, waitForBroadcast(clock.Second*3, owner, 2))
+	// The status should still be OVER_LIMIT
+	sendHit(guber.Status_OVER_LIMIT, 0)
+}
+
+func TestGlobalRateLimitsPeerOverLimitLeaky(t *testing.T) {
+	const (
+		name = "test_global_token_limit_leaky"
+		key  = "account:12345"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(client guber.V1Client, expectedStatus guber.Status, hits int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_LEAKY_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 5,
+					Hits:      hits,
+					Limit:     2,
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].GetError())
+		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
+	}
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+
+	// Send two hits that should be processed by the owner and the broadcast to peer, depleting the remaining
+	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 1)
+	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 1)
+	// Wait for the broadcast from the owner to the peers
+	require.NoError(t, waitForBroadcast(clock.Second*3, owner, 1))
+	// Ask a different peer if the status is over the limit
+	sendHit(peers[1].MustClient(), guber.Status_OVER_LIMIT, 1)
+}
+
+func TestGlobalRequestMoreThanAvailable(t *testing.T) {
+	const (
+		name = "test_global_more_than_available"
+		key  = "account:123456"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(client guber.V1Client, expectedStatus guber.Status, hits int64, remaining int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_LEAKY_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 1_000,
+					Hits:      hits,
+					Limit:     100,
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].GetError())
+		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
+	}
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+
+	prev, err := getBroadcastCount(owner)
+	require.NoError(t, err)
+
+	// Ensure GRPC has connections to each peer before we start, as we want
+	// the actual test requests to happen quite fast.
+	for _, p := range peers {
+		sendHit(p.MustClient(), guber.Status_UNDER_LIMIT, 0, 100)
+	}
+
+	// Send a request for 50 hits from each non owning peer in the cluster. These requests
+	// will be queued and sent to the owner as accumulated hits. As a result of the async nature
+	// of `Behavior_GLOBAL` rate limit requests spread across peers like this will be allowed to
+	// over-consume their resource within the rate limit window until the owner is updated and
+	// a broadcast to all peers is received.
+	//
+	// The maximum number of resources that can be over-consumed can be calculated by multiplying
+	// the remainder by the number of peers in the cluster. For example: If you have a remainder of 100
+	// and a cluster of 10 instances, then the maximum over-consumed resource is 1,000. If you need
+	// a more accurate remaining calculation, and wish to avoid over consuming a resource, then do
+	// not use `Behavior_GLOBAL`.
+	for _, p := range peers {
+		sendHit(p.MustClient(), guber.Status_UNDER_LIMIT, 50, 50)
+	}
+
+	// Wait for the broadcast from the owner to the peers
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+1))
+
+	// We should be over the limit
+	sendHit(peers[0].MustClient(), guber.Status_OVER_LIMIT, 1, 0)
+}
+
+func TestGlobalNegativeHits(t *testing.T) {
+	const (
+		name = "test_global_negative_hits"
+		key  = "account:12345"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(client guber.V1Client, status guber.Status, hits int64, remaining int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_TOKEN_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 100,
+					Hits:      hits,
+					Limit:     2,
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].GetError())
+		assert.Equal(t, status, resp.Responses[0].GetStatus())
+		assert.Equal(t, remaining, resp.Responses[0].Remaining)
+	}
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+	prev, err := getBroadcastCount(owner)
+	require.NoError(t, err)
+
+	// Send a negative hit on a rate limit with no hits, which is already full
+	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, -1, 2)
+
+	// Wait for the rate limit to propagate
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+1))
+
+	// Take every hit from a different peer
+	sendHit(peers[1].MustClient(), guber.Status_UNDER_LIMIT, 2, 0)
+
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+2))
+
+	// Refund a hit
+	sendHit(peers[2].MustClient(), guber.Status_UNDER_LIMIT, -1, 1)
+
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+3))
+
+	// Refunding more hits than were taken does not raise the remaining above the limit
+	sendHit(peers[3].MustClient(), guber.Status_UNDER_LIMIT, -5, 2)
+
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+4))
+
+	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 0, 2)
+}
+
+func TestGlobalResetRemaining(t *testing.T) {
+	const (
+		name = "test_global_reset"
+		key  = "account:123456"
+	)
+
+	peers, err := cluster.ListNonOwningDaemons(name, key)
+	require.NoError(t, err)
+
+	sendHit := func(client guber.V1Client, expectedStatus guber.Status, hits int64, remaining int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+		defer cancel()
+		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_LEAKY_BUCKET,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute * 1_000,
+					Hits:      hits,
+					Limit:     100,
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "", resp.Responses[0].GetError())
+		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
+		assert.Equal(t, remaining, resp.Responses[0].Remaining)
+	}
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+	prev, err := getBroadcastCount(owner)
+	require.NoError(t, err)
+
+	for _, p := range peers {
+		sendHit(p.MustClient(), guber.Status_UNDER_LIMIT, 50, 50)
+	}
+
+	// Wait for the broadcast from the owner to the peers
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+1))
+
+	// We should be over the limit and remaining should be zero
+	sendHit(peers[0].MustClient(), guber.Status_OVER_LIMIT, 1, 0)
+
+	// Now reset the remaining
+	ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+	defer cancel()
+	resp, err := peers[0].MustClient().GetRateLimits(ctx, &guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      name,
+				UniqueKey: key,
+				Algorithm: guber.Algorithm_LEAKY_BUCKET,
+				Behavior:  guber.Behavior_GLOBAL | guber.Behavior_RESET_REMAINING,
+				Duration:  guber.Minute * 1_000,
+				Hits:      0,
+				Limit:     100,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, 100, resp.Responses[0].Remaining)
+
+	// Wait for the reset to propagate.
+	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+2))
+
+	// Check a different peer to ensure remaining has been reset
+	resp, err = peers[1].MustClient().GetRateLimits(ctx, &guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      name,
+				UniqueKey: key,
+				Algorithm: guber.Algorithm_LEAKY_BUCKET,
+				Behavior:  guber.Behavior_GLOBAL,
+				Duration:  guber.Minute * 1_000,
+				Hits:      0,
+				Limit:     100,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, 100, resp.Responses[0].Remaining)
+
+}
+
+func getMetricRequest(url string, name string) (*model.Sample, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return getMetric(resp.Body, name)
+}
+
+func TestChangeLimit(t *testing.T) {
+	client, errs := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.Nil(t, errs)
+
+	tests := []struct {
+		Remaining int64
+		Algorithm guber.Algorithm
+		Status    guber.Status
+		Name      string
+		Limit     int64
+	}{
+		{
+			Name:      "Should subtract 1 from remaining",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 99,
+			Limit:     100,
+		},
+		{
+			Name:      "Should subtract 1 from remaining",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 98,
+			Limit:     100,
+		},
+		{
+			Name:      "Should subtract 1 from remaining and change limit to 10",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 7,
+			Limit:     10,
+		},
+		{
+			Name:      "Should subtract 1 from remaining with new limit of 10",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 6,
+			Limit:     10,
+		},
+		{
+			Name:      "Should subtract 1 from remaining with new limit of 200",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 195,
+			Limit:     200,
+		},
+		{
+			Name:      "Should subtract 1 from remaining for leaky bucket",
+			Algorithm: guber.Algorithm_LEAKY_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 99,
+			Limit:     100,
+		},
+		{
+			Name:      "Should subtract 1 from remaining for leaky bucket after limit change",
+			Algorithm: guber.Algorithm_LEAKY_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 9,
+			Limit:     10,
+		},
+		{
+			Name:      "Should subtract 1 from remaining for leaky bucket with new limit",
+			Algorithm: guber.Algorithm_LEAKY_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Remaining: 8,
+			Limit:     10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      "test_change_limit",
+						UniqueKey: "account:1234",
+						Algorithm: tt.Algorithm,
+						Duration:  guber.Millisecond * 9000,
+						Limit:     tt.Limit,
+						Hits:      1,
+					},
+				},
+			})
+			require.Nil(t, err)
+
+			rl := resp.Responses[0]
+
+			assert.Equal(t, tt.Status, rl.Status)
+			assert.Equal(t, tt.Remaining, rl.Remaining)
+			assert.Equal(t, tt.Limit, rl.Limit)
+			assert.True(t, rl.ResetTime != 0)
+		})
+	}
+}
+
+func TestResetRemaining(t *testing.T) {
+	client, errs := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.Nil(t, errs)
+
+	tests := []struct {
+		Remaining int64
+		Algorithm guber.Algorithm
+		Behavior  guber.Behavior
+		Status    guber.Status
+		Name      string
+		Limit     int64
+	}{
+		{
+			Name:      "Should subtract 1 from remaining",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Behavior:  guber.Behavior_BATCHING,
+			Remaining: 99,
+			Limit:     100,
+		},
+		{
+			Name:      "Should subtract 2 from remaining",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Behavior:  guber.Behavior_BATCHING,
+			Remaining: 98,
+			Limit:     100,
+		},
+		{
+			Name:      "Should reset the remaining",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Behavior:  guber.Behavior_RESET_REMAINING,
+			Remaining: 100,
+			Limit:     100,
+		},
+		{
+			Name:      "Should subtract 1 from remaining after reset",
+			Algorithm: guber.Algorithm_TOKEN_BUCKET,
+			Status:    guber.Status_UNDER_LIMIT,
+			Behavior:  guber.Behavior_BATCHING,
+			Remaining: 99,
+			Limit:     100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      "test_reset_remaining",
+						UniqueKey: "account:1234",
+						Algorithm: tt.Algorithm,
+						Duration:  guber.Millisecond * 9000,
+						Behavior:  tt.Behavior,
+						Limit:     tt.Limit,
+						Hits:      1,
+					},
+				},
+			})
+			require.Nil(t, err)
+
+			rl := resp.Responses[0]
+
+			assert.Equal(t, tt.Status, rl.Status)
+			assert.Equal(t, tt.Remaining, rl.Remaining)
+			assert.Equal(t, tt.Limit, rl.Limit)
+		})
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	client, err := guber.DialV1Server(cluster.DaemonAt(0).GRPCListeners[0].Addr().String(), nil)
+	require.NoError(t, err)
+
+	// Check that the cluster is healthy to start with
+	healthResp, err := client.HealthCheck(context.Background(), &guber.HealthCheckReq{})
+	require.NoError(t, err)
+
+	require.Equal(t, "healthy", healthResp.GetStatus())
+
+	// Create a global rate limit that will need to be sent to all peers in the cluster
+	_, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      "test_health_check",
+				UniqueKey: "account:12345",
+				Algorithm: guber.Algorithm_TOKEN_BUCKET,
+				Behavior:  guber.Behavior_BATCHING,
+				Duration:  guber.Second * 3,
+				Hits:      1,
+				Limit:     5,
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	// Stop the rest of the cluster to ensure errors occur on our instance
+	for i := 1; i < cluster.NumOfDaemons(); i++ {
+		d := cluster.DaemonAt(i)
+		require.NotNil(t, d)
+		d.Close()
+	}
+
+	// Hit the global rate limit again this time causing a connection error
+	_, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      "test_health_check",
+				UniqueKey: "account:12345",
+				Algorithm: guber.Algorithm_TOKEN_BUCKET,
+				Behavior:  guber.Behavior_GLOBAL,
+				Duration:  guber.Second * 3,
+				Hits:      1,
+				Limit:     5,
+			},
+		},
+	})
+	require.Nil(t, err)
 
-	// All successive hits should return OVER_LIMIT.
-	for i := 2; i <= 10; i++ {
-		sendHit(guber.Status_OVER_LIMIT, i)
-	}
-}
+	testutil.UntilPass(t, 20, clock.Millisecond*300, func(t testutil.TestingT) {
+		// Check the health again to get back the connection error
+		healthResp, err = client.HealthCheck(context.Background(), &guber.HealthCheckReq{})
+		if assert.Nil(t, err) {
+			return
+		}
 
-func TestGlobalRateLimitsPeerOverLimit(t *testing.T) {
-	const (
-		name = "test_global_token_limit"
-		key  = "account:12345"
-	)
+		assert.Equal(t, "unhealthy", healthResp.GetStatus())
+		assert.Contains(t, healthResp.GetMessage(), "connect: connection refused")
+	})
 
-	peers, err := cluster.ListNonOwningDaemons(name, key)
-	require.NoError(t, err)
+	// Restart stopped instances
+	ctx, cancel := context.WithTimeout(context.Background(), clock.Second*15)
+	defer cancel()
+	require.NoError(t, cluster.Restart(ctx))
 
-	sendHit := func(expectedStatus guber.Status, hits int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
-		defer cancel()
-		resp, err := peers[0].MustClient().GetRateLimits(ctx, &guber.GetRateLimitsReq{
-			Requests: []*guber.RateLimitReq{
-				{
-					Name:      name,
-					UniqueKey: key,
-					Algorithm: guber.Algorithm_TOKEN_BUCKET,
-					Behavior:  guber.Behavior_GLOBAL,
-					Duration:  guber.Minute * 5,
-					Hits:      hits,
-					Limit:     2,
-				},
-			},
+	// wait for every peer instance to come back online
+	for _, peer := range cluster.GetPeers() {
+		peerClient, err := guber.DialV1Server(peer.GRPCAddress, nil)
+		require.NoError(t, err)
+		testutil.UntilPass(t, 10, clock.Millisecond*300, func(t testutil.TestingT) {
+			healthResp, err = peerClient.HealthCheck(context.Background(), &guber.HealthCheckReq{})
+			assert.Equal(t, "healthy", healthResp.GetStatus())
 		})
-		assert.NoError(t, err)
-		assert.Equal(t, "", resp.Responses[0].GetError())
-		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
 	}
-	owner, err := cluster.FindOwningDaemon(name, key)
-	require.NoError(t, err)
-
-	// Send two hits that should be processed by the owner and the broadcast to peer, depleting the remaining
-	sendHit(guber.Status_UNDER_LIMIT, 1)
-	sendHit(guber.Status_UNDER_LIMIT, 1)
-	// Wait for the broadcast from the owner to the peer
-	require.NoError(t, waitForBroadcast(clock.Second*3, owner, 1))
-	// Since the remainder is 0, the peer should set OVER_LIMIT instead of waiting for the owner
-	// to respond with OVER_LIMIT.
-	sendHit(guber.Status_OVER_LIMIT, 1)
-	// Wait for the broadcast from the owner to the peer
-	require.NoError(t, waitForBroadcast(clock.Second*3, owner, 2))
-	// The status should still be OVER_LIMIT
-	sendHit(guber.Status_OVER_LIMIT, 0)
 }
 
-func TestGlobalRateLimitsPeerOverLimitLeaky(t *testing.T) {
-	const (
-		name = "test_global_token_limit_leaky"
-		key  = "account:12345"
-	)
+func TestLeakyBucketDivBug(t *testing.T) {
+	// Freeze time so we don't leak during the test
+	defer clock.Freeze(clock.Now()).Unfreeze()
 
-	peers, err := cluster.ListNonOwningDaemons(name, key)
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
 	require.NoError(t, err)
 
-	sendHit := func(client guber.V1Client, expectedStatus guber.Status, hits int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
-		defer cancel()
-		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
-			Requests: []*guber.RateLimitReq{
-				{
-					Name:      name,
-					UniqueKey: key,
-					Algorithm: guber.Algorithm_LEAKY_BUCKET,
-					Behavior:  guber.Behavior_GLOBAL,
-					Duration:  guber.Minute * 5,
-					Hits:      hits,
-					Limit:     2,
-				},
+	resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      "test_leaky_bucket_div",
+				UniqueKey: "account:12345",
+				Algorithm: guber.Algorithm_LEAKY_BUCKET,
+				Duration:  guber.Millisecond * 1000,
+				Hits:      1,
+				Limit:     2000,
 			},
-		})
-		assert.NoError(t, err)
-		assert.Equal(t, "", resp.Responses[0].GetError())
-		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
-	}
-	owner, err := cluster.FindOwningDaemon(name, key)
+		},
+	})
 	require.NoError(t, err)
+	assert.Equal(t, "", resp.Responses[0].Error)
+	assert.Equal(t, guber.Status_UNDER_LIMIT, resp.Responses[0].Status)
+	assert.Equal(t, int64(1999), resp.Responses[0].Remaining)
+	assert.Equal(t, int64(2000), resp.Responses[0].Limit)
 
-	// Send two hits that should be processed by the owner and the broadcast to peer, depleting the remaining
-	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 1)
-	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, 1)
-	// Wait for the broadcast from the owner to the peers
-	require.NoError(t, waitForBroadcast(clock.Second*3, owner, 1))
-	// Ask a different peer if the status is over the limit
-	sendHit(peers[1].MustClient(), guber.Status_OVER_LIMIT, 1)
+	// Should result in a rate of 0.5
+	resp, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      "test_leaky_bucket_div",
+				UniqueKey: "account:12345",
+				Algorithm: guber.Algorithm_LEAKY_BUCKET,
+				Duration:  guber.Millisecond * 1000,
+				Hits:      100,
+				Limit:     2000,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1899), resp.Responses[0].Remaining)
+	assert.Equal(t, int64(2000), resp.Responses[0].Limit)
 }
 
-func TestGlobalRequestMoreThanAvailable(t *testing.T) {
-	const (
-		name = "test_global_more_than_available"
-		key  = "account:123456"
-	)
-
-	peers, err := cluster.ListNonOwningDaemons(name, key)
-	require.NoError(t, err)
+func TestMultiRegion(t *testing.T) {
+	name := t.Name()
+	key := fmt.Sprintf("key:%016x", rand.Int())
 
-	sendHit := func(client guber.V1Client, expectedStatus guber.Status, hits int64, remaining int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
+	sendHit := func(t testutil.TestingT, client guber.V1Client, behavior guber.Behavior, hits int64) *guber.RateLimitResp {
+		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*5)
 		defer cancel()
 		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
 			Requests: []*guber.RateLimitReq{
 				{
 					Name:      name,
 					UniqueKey: key,
-					Algorithm: guber.Algorithm_LEAKY_BUCKET,
-					Behavior:  guber.Behavior_GLOBAL,
-					Duration:  guber.Minute * 1_000,
+					Algorithm: guber.Algorithm_TOKEN_BUCKET,
+					Behavior:  behavior,
+					Duration:  guber.Minute * 5,
 					Hits:      hits,
 					Limit:     100,
 				},
 			},
 		})
-		assert.NoError(t, err)
-		assert.Equal(t, "", resp.Responses[0].GetError())
-		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 1)
+		require.Empty(t, resp.Responses[0].Error)
+		return resp.Responses[0]
 	}
-	owner, err := cluster.FindOwningDaemon(name, key)
-	require.NoError(t, err)
 
-	prev, err := getBroadcastCount(owner)
+	none, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.NoError(t, err)
+	one, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterOne).GRPCAddress, nil)
 	require.NoError(t, err)
 
-	// Ensure GRPC has connections to each peer before we start, as we want
-	// the actual test requests to happen quite fast.
-	for _, p := range peers {
-		sendHit(p.MustClient(), guber.Status_UNDER_LIMIT, 0, 100)
-	}
+	// Queue a rate limit with multi region behavior on the DataCenterNone cluster
+	resp := sendHit(t, none, guber.Behavior_MULTI_REGION, 1)
+	assert.Equal(t, guber.Status_UNDER_LIMIT, resp.Status)
+	assert.Equal(t, int64(99), resp.Remaining)
 
-	// Send a request for 50 hits from each non owning peer in the cluster. These requests
-	// will be queued and sent to the owner as accumulated hits. As a result of the async nature
-	// of `Behavior_GLOBAL` rate limit requests spread across peers like this will be allowed to
-	// over-consume their resource within the rate limit window until the owner is updated and
-	// a broadcast to all peers is received.
-	//
-	// The maximum number of resources that can be over-consumed can be calculated by multiplying
-	// the remainder by the number of peers in the cluster. For example: If you have a remainder of 100
-	// and a cluster of 10 instances, then the maximum over-consumed resource is 1,000. If you need
-	// a more accurate remaining calculation, and wish to avoid over consuming a resource, then do
-	// not use `Behavior_GLOBAL`.
-	for _, p := range peers {
-		sendHit(p.MustClient(), guber.Status_UNDER_LIMIT, 50, 50)
-	}
+	// Wait until the rate limit count shows up on the DataCenterOne cluster
+	testutil.UntilPass(t, 20, clock.Millisecond*200, func(t testutil.TestingT) {
+		resp := sendHit(t, one, guber.Behavior_BATCHING, 0)
+		assert.Equal(t, int64(99), resp.Remaining)
+	})
 
-	// Wait for the broadcast from the owner to the peers
-	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+1))
+	// Increment the counts on the DataCenterOne cluster
+	resp = sendHit(t, one, guber.Behavior_MULTI_REGION, 2)
+	assert.Equal(t, int64(97), resp.Remaining)
 
-	// We should be over the limit
-	sendHit(peers[0].MustClient(), guber.Status_OVER_LIMIT, 1, 0)
+	// Wait until both rate limit counts show up on all data centers
+	testutil.UntilPass(t, 20, clock.Millisecond*200, func(t testutil.TestingT) {
+		resp := sendHit(t, none, guber.Behavior_BATCHING, 0)
+		assert.Equal(t, int64(97), resp.Remaining)
+	})
+	resp = sendHit(t, one, guber.Behavior_BATCHING, 0)
+	assert.Equal(t, int64(97), resp.Remaining)
+
+	// Hits rejected as over the limit are not counted by the other regions
+	resp = sendHit(t, none, guber.Behavior_MULTI_REGION, 98)
+	assert.Equal(t, guber.Status_OVER_LIMIT, resp.Status)
+	resp = sendHit(t, none, guber.Behavior_MULTI_REGION, 1)
+	assert.Equal(t, int64(96), resp.Remaining)
+	testutil.UntilPass(t, 20, clock.Millisecond*200, func(t testutil.TestingT) {
+		resp := sendHit(t, one, guber.Behavior_BATCHING, 0)
+		assert.Equal(t, int64(96), resp.Remaining)
+	})
+	resp = sendHit(t, one, guber.Behavior_BATCHING, 0)
+	assert.Equal(t, guber.Status_UNDER_LIMIT, resp.Status)
 }
 
-func TestGlobalNegativeHits(t *testing.T) {
-	const (
-		name = "test_global_negative_hits"
-		key  = "account:12345"
-	)
+func TestGRPCGateway(t *testing.T) {
+	address := cluster.GetRandomPeer(cluster.DataCenterNone).HTTPAddress
+	resp, err := http.DefaultClient.Get("http://" + address + "/v1/HealthCheck")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := io.ReadAll(resp.Body)
+
+	// This test ensures future upgrades don't accidentally change `under_score` to `camelCase` again.
+	assert.Contains(t, string(b), "peer_count")
+
+	var hc guber.HealthCheckResp
+	require.NoError(t, json.Unmarshal(b, &hc))
+	assert.Equal(t, int32(10), hc.PeerCount)
 
-	peers, err := cluster.ListNonOwningDaemons(name, key)
 	require.NoError(t, err)
 
-	sendHit := func(client guber.V1Client, status guber.Status, hits int64, remaining int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
-		defer cancel()
-		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
-			Requests: []*guber.RateLimitReq{
-				{
-					Name:      name,
-					UniqueKey: key,
-					Algorithm: guber.Algorithm_TOKEN_BUCKET,
-					Behavior:  guber.Behavior_GLOBAL,
-					Duration:  guber.Minute * 100,
-					Hits:      hits,
-					Limit:     2,
-				},
+	payload, err := json.Marshal(&guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      "requests_per_sec",
+				UniqueKey: "account:12345",
+				Duration:  guber.Millisecond * 1000,
+				Hits:      1,
+				Limit:     10,
 			},
-		})
-		assert.NoError(t, err)
-		assert.Equal(t, "", resp.Responses[0].GetError())
-		assert.Equal(t, status, resp.Responses[0].GetStatus())
-		assert.Equal(t, remaining, resp.Responses[0].Remaining)
-	}
-	owner, err := cluster.FindOwningDaemon(name, key)
-	require.NoError(t, err)
-	prev, err := getBroadcastCount(owner)
+		},
+	})
 	require.NoError(t, err)
 
-	// Send a negative hit on a rate limit with no hits
-	sendHit(peers[0].MustClient(), guber.Status_UNDER_LIMIT, -1, 3)
+	resp, err = http.DefaultClient.Post("http://"+address+"/v1/GetRateLimits",
+		"application/json", bytes.NewReader(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
 
-	// Wait for the negative remaining to propagate
-	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+1))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var r guber.GetRateLimitsResp
 
-	// Send another negative hit to a different peer
-	sendHit(peers[1].MustClient(), guber.Status_UNDER_LIMIT, -1, 4)
+	// NOTE: It is important to use 'protojson' instead of the standard 'json' package
+	//  else the enums will not be converted properly and json.Unmarshal() will return an
+	//  error.
+	require.NoError(t, json.Unmarshal(b, &r))
+	require.Equal(t, 1, len(r.Responses))
+	assert.Equal(t, guber.Status_UNDER_LIMIT, r.Responses[0].Status)
+}
 
-	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+2))
+func TestGRPCGatewayRetryAfter(t *testing.T) {
+	address := cluster.GetRandomPeer(cluster.DataCenterNone).HTTPAddress
+	payload, err := json.Marshal(&guber.GetRateLimitsReq{
+		Requests: []*guber.RateLimitReq{
+			{
+				Name:      "test_gateway_retry_after",
+				UniqueKey: "account:12345",
+				Duration:  guber.Minute,
+				Hits:      1,
+				Limit:     1,
+			},
+		},
+	})
+	require.NoError(t, err)
 
-	// Should have 4 in the remainder
-	sendHit(peers[2].MustClient(), guber.Status_UNDER_LIMIT, 4, 0)
+	sendHit := func(status guber.Status, retryAfter string) {
+		resp, err := http.DefaultClient.Post("http://"+address+"/v1/GetRateLimits",
+			"application/json", bytes.NewReader(payload))
+		require.NoError(t, err)
+		defer resp.Body.Close()
 
-	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+3))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, retryAfter, resp.Header.Get("Retry-After"))
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var r guber.GetRateLimitsResp
+		require.NoError(t, json.Unmarshal(b, &r))
+		require.Equal(t, 1, len(r.Responses))
+		assert.Equal(t, status, r.Responses[0].Status)
+	}
 
-	sendHit(peers[3].MustClient(), guber.Status_UNDER_LIMIT, 0, 0)
+	sendHit(guber.Status_UNDER_LIMIT, "")
+	sendHit(guber.Status_OVER_LIMIT, "60")
 }
 
-func TestGlobalResetRemaining(t *testing.T) {
-	const (
-		name = "test_global_reset"
-		key  = "account:123456"
-	)
+func TestAdminRateLimits(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
+	name, key := "test_admin_rate_limits", "account:1234"
 
+	// Send all the requests to a peer which does not own the rate limit,
+	// so the admin requests must be forwarded to the owner.
 	peers, err := cluster.ListNonOwningDaemons(name, key)
 	require.NoError(t, err)
+	client := peers[0].MustClient()
+	rlKey := &guber.RateLimitKey{Name: name, UniqueKey: key}
 
-	sendHit := func(client guber.V1Client, expectedStatus guber.Status, hits int64, remaining int64) {
-		ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
-		defer cancel()
-		resp, err := client.GetRateLimits(ctx, &guber.GetRateLimitsReq{
+	sendHit := func(hits, remaining int64, status guber.Status) {
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
 			Requests: []*guber.RateLimitReq{
 				{
 					Name:      name,
 					UniqueKey: key,
-					Algorithm: guber.Algorithm_LEAKY_BUCKET,
-					Behavior:  guber.Behavior_GLOBAL,
-					Duration:  guber.Minute * 1_000,
+					Behavior:  guber.Behavior_NO_BATCHING,
+					Duration:  guber.Minute,
+					Limit:     10,
 					Hits:      hits,
-					Limit:     100,
 				},
 			},
 		})
-		assert.NoError(t, err)
-		assert.Equal(t, "", resp.Responses[0].GetError())
-		assert.Equal(t, expectedStatus, resp.Responses[0].GetStatus())
-		assert.Equal(t, remaining, resp.Responses[0].Remaining)
+		require.NoError(t, err)
+		rl := resp.Responses[0]
+		assert.Empty(t, rl.Error)
+		assert.Equal(t, status, rl.Status)
+		assert.Equal(t, remaining, rl.Remaining)
 	}
-	owner, err := cluster.FindOwningDaemon(name, key)
-	require.NoError(t, err)
-	prev, err := getBroadcastCount(owner)
-	require.NoError(t, err)
 
-	for _, p := range peers {
-		sendHit(p.MustClient(), guber.Status_UNDER_LIMIT, 50, 50)
+	inspect := func() *guber.RateLimitState {
+		resp, err := client.InspectRateLimits(context.Background(), &guber.InspectRateLimitsReq{
+			Keys: []*guber.RateLimitKey{rlKey},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.States, 1)
+		assert.Empty(t, resp.States[0].Error)
+		return resp.States[0]
 	}
 
-	// Wait for the broadcast from the owner to the peers
-	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+1))
-
-	// We should be over the limit and remaining should be zero
-	sendHit(peers[0].MustClient(), guber.Status_OVER_LIMIT, 1, 0)
+	state := inspect()
+	assert.False(t, state.Found)
+	assert.Equal(t, name, state.Name)
+	assert.Equal(t, key, state.UniqueKey)
+
+	sendHit(3, 7, guber.Status_UNDER_LIMIT)
+
+	// Inspecting should not consume any hits
+	for i := 0; i < 2; i++ {
+		state = inspect()
+		assert.True(t, state.Found)
+		assert.Equal(t, guber.Algorithm_TOKEN_BUCKET, state.Algorithm)
+		assert.Equal(t, int64(10), state.Limit)
+		assert.Equal(t, int64(guber.Minute), state.Duration)
+		assert.Equal(t, int64(7), state.Remaining)
+		assert.Equal(t, guber.MillisecondNow()+guber.Minute, state.ResetTime)
+	}
 
-	// Now reset the remaining
-	ctx, cancel := context.WithTimeout(context.Background(), clock.Second*10)
-	defer cancel()
-	resp, err := peers[0].MustClient().GetRateLimits(ctx, &guber.GetRateLimitsReq{
-		Requests: []*guber.RateLimitReq{
+	// Overwrite the state
+	resp, err := client.SetRateLimits(context.Background(), &guber.SetRateLimitsReq{
+		States: []*guber.RateLimitState{
 			{
 				Name:      name,
 				UniqueKey: key,
-				Algorithm: guber.Algorithm_LEAKY_BUCKET,
-				Behavior:  guber.Behavior_GLOBAL | guber.Behavior_RESET_REMAINING,
-				Duration:  guber.Minute * 1_000,
-				Hits:      0,
-				Limit:     100,
+				Algorithm: guber.Algorithm_TOKEN_BUCKET,
+				Limit:     10,
+				Duration:  guber.Minute,
+				Remaining: 1,
 			},
 		},
 	})
 	require.NoError(t, err)
-	assert.NotEqual(t, 100, resp.Responses[0].Remaining)
+	require.Len(t, resp.States, 1)
+	assert.Empty(t, resp.States[0].Error)
+	assert.True(t, resp.States[0].Found)
+	assert.Equal(t, int64(1), resp.States[0].Remaining)
 
-	// Wait for the reset to propagate.
-	require.NoError(t, waitForBroadcast(clock.Second*10, owner, prev+2))
+	sendHit(1, 0, guber.Status_UNDER_LIMIT)
+	sendHit(1, 0, guber.Status_OVER_LIMIT)
 
-	// Check a different peer to ensure remaining has been reset
-	resp, err = peers[1].MustClient().GetRateLimits(ctx, &guber.GetRateLimitsReq{
-		Requests: []*guber.RateLimitReq{
-			{
-				Name:      name,
-				UniqueKey: key,
-				Algorithm: guber.Algorithm_LEAKY_BUCKET,
-				Behavior:  guber.Behavior_GLOBAL,
-				Duration:  guber.Minute * 1_000,
-				Hits:      0,
-				Limit:     100,
-			},
-		},
+	// Delete the state, which returns the state before it was deleted
+	dresp, err := client.DeleteRateLimits(context.Background(), &guber.DeleteRateLimitsReq{
+		Keys: []*guber.RateLimitKey{rlKey},
 	})
 	require.NoError(t, err)
-	assert.NotEqual(t, 100, resp.Responses[0].Remaining)
-
-}
+	require.Len(t, dresp.States, 1)
+	assert.Empty(t, dresp.States[0].Error)
+	assert.True(t, dresp.States[0].Found)
+	assert.Equal(t, int64(0), dresp.States[0].Remaining)
 
-func getMetricRequest(url string, name string) (*model.Sample, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	return getMetric(resp.Body, name)
+	assert.False(t, inspect().Found)
+	sendHit(1, 9, guber.Status_UNDER_LIMIT)
 }
 
-func TestChangeLimit(t *testing.T) {
-	client, errs := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
-	require.Nil(t, errs)
+func TestShadowBehavior(t *testing.T) {
+	name := "test_shadow_" + guber.RandomString(10)
+	key := "account:1234"
 
-	tests := []struct {
-		Remaining int64
-		Algorithm guber.Algorithm
-		Status    guber.Status
-		Name      string
-		Limit     int64
-	}{
-		{
-			Name:      "Should subtract 1 from remaining",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 99,
-			Limit:     100,
-		},
-		{
-			Name:      "Should subtract 1 from remaining",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 98,
-			Limit:     100,
-		},
-		{
-			Name:      "Should subtract 1 from remaining and change limit to 10",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 7,
-			Limit:     10,
-		},
-		{
-			Name:      "Should subtract 1 from remaining with new limit of 10",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 6,
-			Limit:     10,
-		},
-		{
-			Name:      "Should subtract 1 from remaining with new limit of 200",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 195,
-			Limit:     200,
-		},
-		{
-			Name:      "Should subtract 1 from remaining for leaky bucket",
-			Algorithm: guber.Algorithm_LEAKY_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 99,
-			Limit:     100,
-		},
-		{
-			Name:      "Should subtract 1 from remaining for leaky bucket after limit change",
-			Algorithm: guber.Algorithm_LEAKY_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 9,
-			Limit:     10,
-		},
-		{
-			Name:      "Should subtract 1 from remaining for leaky bucket with new limit",
-			Algorithm: guber.Algorithm_LEAKY_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Remaining: 8,
-			Limit:     10,
-		},
-	}
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.NoError(t, err)
 
-	for _, tt := range tests {
-		t.Run(tt.Name, func(t *testing.T) {
-			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
-				Requests: []*guber.RateLimitReq{
-					{
-						Name:      "test_change_limit",
-						UniqueKey: "account:1234",
-						Algorithm: tt.Algorithm,
-						Duration:  guber.Millisecond * 9000,
-						Limit:     tt.Limit,
-						Hits:      1,
-					},
+	sendHit := func(behavior guber.Behavior, hits int64) *guber.RateLimitResp {
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Algorithm: guber.Algorithm_TOKEN_BUCKET,
+					Behavior:  behavior,
+					Duration:  guber.Minute,
+					Limit:     2,
+					Hits:      hits,
 				},
-			})
-			require.Nil(t, err)
-
-			rl := resp.Responses[0]
-
-			assert.Equal(t, tt.Status, rl.Status)
-			assert.Equal(t, tt.Remaining, rl.Remaining)
-			assert.Equal(t, tt.Limit, rl.Limit)
-			assert.True(t, rl.ResetTime != 0)
+			},
 		})
+		require.NoError(t, err)
+		require.Equal(t, "", resp.Responses[0].Error)
+		return resp.Responses[0]
 	}
-}
 
-func TestResetRemaining(t *testing.T) {
-	client, errs := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
-	require.Nil(t, errs)
-
-	tests := []struct {
-		Remaining int64
-		Algorithm guber.Algorithm
-		Behavior  guber.Behavior
-		Status    guber.Status
-		Name      string
-		Limit     int64
+	for _, tt := range []struct {
+		remaining    int64
+		shadowStatus guber.Status
 	}{
-		{
-			Name:      "Should subtract 1 from remaining",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Behavior:  guber.Behavior_BATCHING,
-			Remaining: 99,
-			Limit:     100,
-		},
-		{
-			Name:      "Should subtract 2 from remaining",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Behavior:  guber.Behavior_BATCHING,
-			Remaining: 98,
-			Limit:     100,
-		},
-		{
-			Name:      "Should reset the remaining",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Behavior:  guber.Behavior_RESET_REMAINING,
-			Remaining: 100,
-			Limit:     100,
-		},
-		{
-			Name:      "Should subtract 1 from remaining after reset",
-			Algorithm: guber.Algorithm_TOKEN_BUCKET,
-			Status:    guber.Status_UNDER_LIMIT,
-			Behavior:  guber.Behavior_BATCHING,
-			Remaining: 99,
-			Limit:     100,
+		{remaining: 1, shadowStatus: guber.Status_UNDER_LIMIT},
+		{remaining: 0, shadowStatus: guber.Status_UNDER_LIMIT},
+		{remaining: 0, shadowStatus: guber.Status_OVER_LIMIT},
+	} {
+		rl := sendHit(guber.Behavior_SHADOW, 1)
+		assert.Equal(t, guber.Status_UNDER_LIMIT, rl.Status)
+		assert.Equal(t, tt.shadowStatus.String(), rl.Metadata["shadow_status"])
+		assert.Equal(t, tt.remaining, rl.Remaining)
+	}
+
+	// The shadow rate limit does not affect the enforced rate limit
+	rl := sendHit(0, 1)
+	assert.Equal(t, guber.Status_UNDER_LIMIT, rl.Status)
+	assert.Equal(t, int64(1), rl.Remaining)
+	assert.Empty(t, rl.Metadata["shadow_status"])
+
+	assert.Equal(t, float64(1), collectorValue(t, cluster.DaemonAt(0).V1Server, "gubernator_shadow_over_limit_counter", name))
+
+	// A shadow rate limit never aborts an atomic request
+	resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+		Atomic: true,
+		Requests: []*guber.RateLimitReq{
+			{Name: name, UniqueKey: key, Behavior: guber.Behavior_SHADOW, Duration: guber.Minute, Limit: 2, Hits: 1},
+			{Name: name, UniqueKey: key, Duration: guber.Minute, Limit: 2, Hits: 1},
 		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, guber.Status_UNDER_LIMIT, resp.Responses[0].Status)
+	assert.Equal(t, guber.Status_OVER_LIMIT.String(), resp.Responses[0].Metadata["shadow_status"])
+	assert.Equal(t, guber.Status_UNDER_LIMIT, resp.Responses[1].Status)
+	assert.Equal(t, int64(0), resp.Responses[1].Remaining)
+}
+
+func TestAtomicRateLimits(t *testing.T) {
+	name := "test_atomic"
+	prefix := guber.RandomString(10)
+
+	// Find two keys which are owned by different peers
+	keyA := prefix + "_a"
+	ownerA, err := cluster.FindOwningDaemon(name, keyA)
+	require.NoError(t, err)
+	var keyB string
+	for i := 0; ; i++ {
+		keyB = fmt.Sprintf("%s_b%d", prefix, i)
+		ownerB, err := cluster.FindOwningDaemon(name, keyB)
+		require.NoError(t, err)
+		if ownerB.PeerInfo.GRPCAddress != ownerA.PeerInfo.GRPCAddress {
+			break
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.Name, func(t *testing.T) {
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.NoError(t, err)
+
+	sendHits := func(hitsA, hitsB int64) []*guber.RateLimitResp {
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Atomic: true,
+			Requests: []*guber.RateLimitReq{
+				{Name: name, UniqueKey: keyA, Algorithm: guber.Algorithm_TOKEN_BUCKET, Duration: guber.Minute, Limit: 10, Hits: hitsA},
+				{Name: name, UniqueKey: keyB, Algorithm: guber.Algorithm_LEAKY_BUCKET, Duration: guber.Minute, Limit: 2, Hits: hitsB},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 2)
+		return resp.Responses
+	}
+
+	// Both rate limits are under the limit, so the hits of both are applied
+	resp := sendHits(1, 1)
+	for _, rl := range resp {
+		assert.Empty(t, rl.Error)
+		assert.Equal(t, guber.Status_UNDER_LIMIT, rl.Status)
+	}
+	assert.Equal(t, int64(9), resp[0].Remaining)
+	assert.Equal(t, int64(1), resp[1].Remaining)
+
+	// The second rate limit is over the limit, so no hits are applied to the first
+	resp = sendHits(1, 2)
+	for _, rl := range resp {
+		assert.Empty(t, rl.Error)
+		assert.Equal(t, guber.Status_OVER_LIMIT, rl.Status)
+	}
+	assert.Equal(t, int64(9), resp[0].Remaining)
+	assert.Equal(t, int64(1), resp[1].Remaining)
+
+	resp = sendHits(0, 0)
+	assert.Equal(t, int64(9), resp[0].Remaining)
+	assert.Equal(t, int64(1), resp[1].Remaining)
+
+	// An invalid rate limit fails the group without applying any hits
+	resp2, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+		Atomic: true,
+		Requests: []*guber.RateLimitReq{
+			{Name: name, UniqueKey: keyA, Algorithm: guber.Algorithm_TOKEN_BUCKET, Duration: guber.Minute, Limit: 10, Hits: 1},
+			{Name: name, UniqueKey: keyB, Duration: guber.Minute, Limit: 2, Hits: 1, Behavior: guber.Behavior_GLOBAL},
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resp2.Responses[0].Error, "atomic group failed")
+	assert.Contains(t, resp2.Responses[1].Error, "GLOBAL is not supported")
+
+	resp = sendHits(0, 0)
+	assert.Equal(t, int64(9), resp[0].Remaining)
+	assert.Equal(t, int64(1), resp[1].Remaining)
+}
+
+func TestAdminRateLimitsAlgorithms(t *testing.T) {
+	defer clock.Freeze(clock.Now()).Unfreeze()
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
+	require.NoError(t, err)
+
+	for _, algorithm := range []guber.Algorithm{
+		guber.Algorithm_TOKEN_BUCKET,
+		guber.Algorithm_LEAKY_BUCKET,
+		guber.Algorithm_SLIDING_WINDOW,
+		guber.Algorithm_GCRA,
+		guber.Algorithm_CONCURRENCY,
+		guber.Algorithm_CONTINUOUS_TOKEN_BUCKET,
+	} {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			name, key := "test_admin_algorithms_"+algorithm.String(), "account:1234"
 			resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
 				Requests: []*guber.RateLimitReq{
 					{
-						Name:      "test_reset_remaining",
-						UniqueKey: "account:1234",
-						Algorithm: tt.Algorithm,
-						Duration:  guber.Millisecond * 9000,
-						Behavior:  tt.Behavior,
-						Limit:     tt.Limit,
-						Hits:      1,
+						Name:      name,
+						UniqueKey: key,
+						Algorithm: algorithm,
+						Duration:  guber.Minute,
+						Limit:     10,
+						Hits:      2,
 					},
 				},
 			})
-			require.Nil(t, err)
+			require.NoError(t, err)
+			require.Empty(t, resp.Responses[0].Error)
 
-			rl := resp.Responses[0]
+			// GCRA rate limits only hold their theoretical arrival time, so their config is provided
+			iresp, err := client.InspectRateLimits(context.Background(), &guber.InspectRateLimitsReq{
+				Keys: []*guber.RateLimitKey{{Name: name, UniqueKey: key, Limit: 10, Duration: guber.Minute}},
+			})
+			require.NoError(t, err)
+			state := iresp.States[0]
+			assert.Empty(t, state.Error)
+			assert.True(t, state.Found)
+			assert.Equal(t, algorithm, state.Algorithm)
+			assert.Equal(t, int64(10), state.Limit)
+			assert.Equal(t, resp.Responses[0].Remaining, state.Remaining)
+			assert.Equal(t, resp.Responses[0].ResetTime, state.ResetTime)
+
+			if algorithm == guber.Algorithm_GCRA {
+				iresp, err = client.InspectRateLimits(context.Background(), &guber.InspectRateLimitsReq{
+					Keys: []*guber.RateLimitKey{{Name: name, UniqueKey: key}},
+				})
+				require.NoError(t, err)
+				state = iresp.States[0]
+				assert.True(t, state.Found)
+				assert.Zero(t, state.Limit)
+				assert.Equal(t, resp.Responses[0].ResetTime, state.ResetTime)
+			}
 
-			assert.Equal(t, tt.Status, rl.Status)
-			assert.Equal(t, tt.Remaining, rl.Remaining)
-			assert.Equal(t, tt.Limit, rl.Limit)
+			sresp, err := client.SetRateLimits(context.Background(), &guber.SetRateLimitsReq{
+				States: []*guber.RateLimitState{
+					{
+						Name:      name,
+						UniqueKey: key,
+						Algorithm: algorithm,
+						Limit:     10,
+						Duration:  guber.Minute,
+						Remaining: 5,
+					},
+				},
+			})
+			require.NoError(t, err)
+			assert.Empty(t, sresp.States[0].Error)
+			assert.Equal(t, int64(5), sresp.States[0].Remaining)
+
+			resp, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+				Requests: []*guber.RateLimitReq{
+					{
+						Name:      name,
+						UniqueKey: key,
+						Algorithm: algorithm,
+						Duration:  guber.Minute,
+						Limit:     10,
+						Hits:      1,
+					},
+				},
+			})
+			require.NoError(t, err)
+			assert.Empty(t, resp.Responses[0].Error)
+			assert.Equal(t, int64(4), resp.Responses[0].Remaining)
 		})
 	}
 }
 
-func TestHealthCheck(t *testing.T) {
-	client, err := guber.DialV1Server(cluster.DaemonAt(0).GRPCListeners[0].Addr().String(), nil)
+func TestAdminRateLimitsErrors(t *testing.T) {
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
 	require.NoError(t, err)
 
-	// Check that the cluster is healthy to start with
-	healthResp, err := client.HealthCheck(context.Background(), &guber.HealthCheckReq{})
+	iresp, err := client.InspectRateLimits(context.Background(), &guber.InspectRateLimitsReq{
+		Keys: []*guber.RateLimitKey{{Name: "test_admin_errors"}},
+	})
 	require.NoError(t, err)
+	assert.Equal(t, "field 'unique_key' cannot be empty", iresp.States[0].Error)
 
-	require.Equal(t, "healthy", healthResp.GetStatus())
-
-	// Create a global rate limit that will need to be sent to all peers in the cluster
-	_, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
-		Requests: []*guber.RateLimitReq{
-			{
-				Name:      "test_health_check",
-				UniqueKey: "account:12345",
-				Algorithm: guber.Algorithm_TOKEN_BUCKET,
-				Behavior:  guber.Behavior_BATCHING,
-				Duration:  guber.Second * 3,
-				Hits:      1,
-				Limit:     5,
-			},
+	for _, tt := range []struct {
+		name  string
+		state *guber.RateLimitState
+		err   string
+	}{
+		{
+			name:  "limit",
+			state: &guber.RateLimitState{Duration: guber.Minute},
+			err:   "field 'limit' must be greater than zero",
 		},
-	})
-	require.Nil(t, err)
-
-	// Stop the rest of the cluster to ensure errors occur on our instance
-	for i := 1; i < cluster.NumOfDaemons(); i++ {
-		d := cluster.DaemonAt(i)
-		require.NotNil(t, d)
-		d.Close()
-	}
-
-	// Hit the global rate limit again this time causing a connection error
-	_, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
-		Requests: []*guber.RateLimitReq{
-			{
-				Name:      "test_health_check",
-				UniqueKey: "account:12345",
-				Algorithm: guber.Algorithm_TOKEN_BUCKET,
-				Behavior:  guber.Behavior_GLOBAL,
-				Duration:  guber.Second * 3,
-				Hits:      1,
-				Limit:     5,
-			},
+		{
+			name:  "duration",
+			state: &guber.RateLimitState{Limit: 10},
+			err:   "field 'duration' must be greater than zero",
 		},
-	})
-	require.Nil(t, err)
-
-	testutil.UntilPass(t, 20, clock.Millisecond*300, func(t testutil.TestingT) {
-		// Check the health again to get back the connection error
-		healthResp, err = client.HealthCheck(context.Background(), &guber.HealthCheckReq{})
-		if assert.Nil(t, err) {
-			return
-		}
-
-		assert.Equal(t, "unhealthy", healthResp.GetStatus())
-		assert.Contains(t, healthResp.GetMessage(), "connect: connection refused")
-	})
-
-	// Restart stopped instances
-	ctx, cancel := context.WithTimeout(context.Background(), clock.Second*15)
-	defer cancel()
-	require.NoError(t, cluster.Restart(ctx))
-
-	// wait for every peer instance to come back online
-	for _, peer := range cluster.GetPeers() {
-		peerClient, err := guber.DialV1Server(peer.GRPCAddress, nil)
-		require.NoError(t, err)
-		testutil.UntilPass(t, 10, clock.Millisecond*300, func(t testutil.TestingT) {
-			healthResp, err = peerClient.HealthCheck(context.Background(), &guber.HealthCheckReq{})
-			assert.Equal(t, "healthy", healthResp.GetStatus())
+		{
+			name:  "remaining",
+			state: &guber.RateLimitState{Limit: 10, Duration: guber.Minute, Remaining: 11},
+			err:   "field 'remaining' must be between 0 and '10'",
+		},
+		{
+			name:  "algorithm",
+			state: &guber.RateLimitState{Limit: 10, Duration: guber.Minute, Algorithm: 100},
+			err:   "invalid rate limit algorithm '100'",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.state.Name = "test_admin_errors"
+			tt.state.UniqueKey = "account:1234"
+			resp, err := client.SetRateLimits(context.Background(), &guber.SetRateLimitsReq{
+				States: []*guber.RateLimitState{tt.state},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.err, resp.States[0].Error)
 		})
 	}
 }
 
-func TestLeakyBucketDivBug(t *testing.T) {
-	// Freeze time so we don't leak during the test
+func TestInspectLeakyBucket(t *testing.T) {
 	defer clock.Freeze(clock.Now()).Unfreeze()
-
 	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
 	require.NoError(t, err)
+	name, key := "test_inspect_leaky_bucket", "account:1234"
 
 	resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
 		Requests: []*guber.RateLimitReq{
 			{
-				Name:      "test_leaky_bucket_div",
-				UniqueKey: "account:12345",
+				Name:      name,
+				UniqueKey: key,
 				Algorithm: guber.Algorithm_LEAKY_BUCKET,
-				Duration:  guber.Millisecond * 1000,
-				Hits:      1,
-				Limit:     2000,
+				Duration:  guber.Minute,
+				Limit:     10,
+				Hits:      5,
 			},
 		},
 	})
 	require.NoError(t, err)
-	assert.Equal(t, "", resp.Responses[0].Error)
-	assert.Equal(t, guber.Status_UNDER_LIMIT, resp.Responses[0].Status)
-	assert.Equal(t, int64(1999), resp.Responses[0].Remaining)
-	assert.Equal(t, int64(2000), resp.Responses[0].Limit)
+	require.Empty(t, resp.Responses[0].Error)
+	assert.Equal(t, int64(5), resp.Responses[0].Remaining)
 
-	// Should result in a rate of 0.5
-	resp, err = client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
-		Requests: []*guber.RateLimitReq{
-			{
-				Name:      "test_leaky_bucket_div",
-				UniqueKey: "account:12345",
-				Algorithm: guber.Algorithm_LEAKY_BUCKET,
-				Duration:  guber.Millisecond * 1000,
-				Hits:      100,
-				Limit:     2000,
-			},
-		},
+	// Two hits leak out of the bucket, which inspect reports without another hit
+	clock.Advance(clock.Second * 12)
+	iresp, err := client.InspectRateLimits(context.Background(), &guber.InspectRateLimitsReq{
+		Keys: []*guber.RateLimitKey{{Name: name, UniqueKey: key}},
 	})
 	require.NoError(t, err)
-	assert.Equal(t, int64(1899), resp.Responses[0].Remaining)
-	assert.Equal(t, int64(2000), resp.Responses[0].Limit)
-}
-
-func TestMultiRegion(t *testing.T) {
-
-	// TODO: Queue a rate limit with multi region behavior on the DataCenterNone cluster
-	// TODO: Check the immediate response is correct
-	// TODO: Wait until the rate limit count shows up on the DataCenterOne and DataCenterTwo cluster
-
-	// TODO: Increment the counts on the DataCenterTwo and DataCenterOne clusters
-	// TODO: Wait until both rate limit count show up on all datacenters
+	assert.Equal(t, int64(7), iresp.States[0].Remaining)
+	assert.Equal(t, clock.Now().UnixNano()/1000000+3*6000, iresp.States[0].ResetTime)
 }
 
-func TestGRPCGateway(t *testing.T) {
-	address := cluster.GetRandomPeer(cluster.DataCenterNone).HTTPAddress
-	resp, err := http.DefaultClient.Get("http://" + address + "/v1/HealthCheck")
+func TestListRateLimits(t *testing.T) {
+	client, err := guber.DialV1Server(cluster.GetRandomPeer(cluster.DataCenterNone).GRPCAddress, nil)
 	require.NoError(t, err)
-	defer resp.Body.Close()
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-	b, err := io.ReadAll(resp.Body)
 
-	// This test ensures future upgrades don't accidentally change `under_score` to `camelCase` again.
-	assert.Contains(t, string(b), "peer_count")
+	// Create rate limits which are spread across the peers in the cluster
+	var expected, overLimit []string
+	for i := 0; i < 15; i++ {
+		key := fmt.Sprintf("account:%02d", i)
+		var hits int64 = 1
+		if i%3 == 0 {
+			hits = 10
+			overLimit = append(overLimit, "test_list_rate_limits_"+key)
+		}
+		resp, err := client.GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      "test_list_rate_limits",
+					UniqueKey: key,
+					Behavior:  guber.Behavior_NO_BATCHING,
+					Duration:  guber.Minute,
+					Limit:     10,
+					Hits:      hits,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.Responses[0].Error)
+		expected = append(expected, "test_list_rate_limits_"+key)
+	}
 
-	var hc guber.HealthCheckResp
-	require.NoError(t, json.Unmarshal(b, &hc))
-	assert.Equal(t, int32(10), hc.PeerCount)
+	list := func(req *guber.ListRateLimitsReq) []string {
+		var keys []string
+		for {
+			resp, err := client.ListRateLimits(context.Background(), req)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(resp.States), int(req.PageSize))
+			for _, state := range resp.States {
+				assert.True(t, state.Found)
+				assert.Equal(t, int64(10), state.Limit)
+				keys = append(keys, state.Key)
+			}
+			if resp.NextPageToken == "" {
+				return keys
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
 
+	assert.Equal(t, expected, list(&guber.ListRateLimitsReq{
+		Prefix:   "test_list_rate_limits_",
+		PageSize: 4,
+	}))
+	assert.Equal(t, overLimit, list(&guber.ListRateLimitsReq{
+		Prefix:    "test_list_rate_limits_",
+		OverLimit: true,
+		PageSize:  2,
+	}))
+	assert.Equal(t, expected[10:], list(&guber.ListRateLimitsReq{
+		Prefix:   "test_list_rate_limits_account:1",
+		PageSize: 1000,
+	}))
+
+	// Copies of a GLOBAL rate limit held by non-owning peers are not listed
+	name, key := "test_list_rate_limits_global", "account:1234"
+	owner, err := cluster.FindOwningDaemon(name, key)
+	require.NoError(t, err)
+	peers, err := cluster.ListNonOwningDaemons(name, key)
 	require.NoError(t, err)
+	for _, d := range append(peers, owner) {
+		resp, err := d.MustClient().GetRateLimits(context.Background(), &guber.GetRateLimitsReq{
+			Requests: []*guber.RateLimitReq{
+				{
+					Name:      name,
+					UniqueKey: key,
+					Behavior:  guber.Behavior_GLOBAL,
+					Duration:  guber.Minute,
+					Limit:     10,
+					Hits:      1,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.Responses[0].Error)
+	}
+	assert.Equal(t, []string{name + "_" + key}, list(&guber.ListRateLimitsReq{
+		Prefix:   name,
+		PageSize: 10,
+	}))
+
+	_, err = client.ListRateLimits(context.Background(), &guber.ListRateLimitsReq{PageToken: "!"})
+	require.Error(t, err)
+	_, err = client.ListRateLimits(context.Background(), &guber.ListRateLimitsReq{PageSize: 1001})
+	require.Error(t, err)
+}
 
-	payload, err := json.Marshal(&guber.GetRateLimitsReq{
-		Requests: []*guber.RateLimitReq{
+func TestGRPCGatewayAdmin(t *testing.T) {
+	address := cluster.GetRandomPeer(cluster.DataCenterNone).HTTPAddress
+	payload, err := json.Marshal(&guber.SetRateLimitsReq{
+		States: []*guber.RateLimitState{
 			{
-				Name:      "requests_per_sec",
-				UniqueKey: "account:12345",
-				Duration:  guber.Millisecond * 1000,
-				Hits:      1,
+				Name:      "test_gateway_admin",
+				UniqueKey: "account:1234",
 				Limit:     10,
+				Duration:  guber.Minute,
+				Remaining: 3,
 			},
 		},
 	})
 	require.NoError(t, err)
 
-	resp, err = http.DefaultClient.Post("http://"+address+"/v1/GetRateLimits",
-		"application/json", bytes.NewReader(payload))
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	post := func(path string, payload []byte) []byte {
+		resp, err := http.DefaultClient.Post("http://"+address+path,
+			"application/json", bytes.NewReader(payload))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return b
+	}
+	post("/v1/admin/SetRateLimits", payload)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-	b, err = io.ReadAll(resp.Body)
+	payload, err = json.Marshal(&guber.InspectRateLimitsReq{
+		Keys: []*guber.RateLimitKey{{Name: "test_gateway_admin", UniqueKey: "account:1234"}},
+	})
 	require.NoError(t, err)
-	var r guber.GetRateLimitsResp
 
-	// NOTE: It is important to use 'protojson' instead of the standard 'json' package
-	//  else the enums will not be converted properly and json.Unmarshal() will return an
-	//  error.
-	require.NoError(t, json.Unmarshal(b, &r))
-	require.Equal(t, 1, len(r.Responses))
-	assert.Equal(t, guber.Status_UNDER_LIMIT, r.Responses[0].Status)
+	var r guber.InspectRateLimitsResp
+	require.NoError(t, json.Unmarshal(post("/v1/admin/InspectRateLimits", payload), &r))
+	require.Len(t, r.States, 1)
+	assert.True(t, r.States[0].Found)
+	assert.Equal(t, int64(3), r.States[0].Remaining)
+
+	var d guber.DeleteRateLimitsResp
+	require.NoError(t, json.Unmarshal(post("/v1/admin/DeleteRateLimits", payload), &d))
+	require.Len(t, d.States, 1)
+	assert.True(t, d.States[0].Found)
+
+	require.NoError(t, json.Unmarshal(post("/v1/admin/InspectRateLimits", payload), &r))
+	assert.False(t, r.States[0].Found)
 }
 
 func TestGetPeerRateLimits(t *testing.T) {
@@ -75,11 +75,16 @@ func (gm *globalManager) QueueHit(r *RateLimitReq) {
 }
 
 func (gm *globalManager) QueueUpdate(req *RateLimitReq, resp *RateLimitResp) {
-	gm.broadcastQueue <- &UpdatePeerGlobal{
+	g := &UpdatePeerGlobal{
 		Key:       req.HashKey(),
 		Algorithm: req.Algorithm,
 		Status:    resp,
 	}
+	if req.Algorithm == Algorithm_CONTINUOUS_TOKEN_BUCKET {
+		// The request succeeded, so the config is valid
+		g.Burst, g.Duration, _ = continuousTokenBucketConfig(req)
+	}
+	gm.broadcastQueue <- g
 }
 
 // runAsyncHits collects async hit requests in a forever loop,
@@ -90,42 +95,61 @@ func (gm *globalManager) QueueUpdate(req *RateLimitReq, resp *RateLimitResp) {
 func (gm *globalManager) runAsyncHits() {
 	var interval = NewInterval(gm.conf.GlobalSyncWait)
 	hits := make(map[string]*RateLimitReq)
+	// Refunds are aggregated apart from the hits, as a refund netted against the hits would let
+	// the owner accept hits which it would otherwise reject, before the refund is applied.
+	refunds := make(map[string]*RateLimitReq)
+
+	// Refunds are sent after the hits, such that the owner never caps a refund for hits it has
+	// not yet seen.
+	send := func() {
+		if len(hits) != 0 {
+			gm.sendHits(hits)
+		}
+		if len(refunds) != 0 {
+			gm.sendHits(refunds)
+		}
+		hits = make(map[string]*RateLimitReq)
+		refunds = make(map[string]*RateLimitReq)
+	}
 
 	gm.wg.Until(func(done chan struct{}) bool {
 
 		select {
 		case r := <-gm.hitsQueue:
+			queue := hits
+			if r.Hits < 0 && !HasBehavior(r.Behavior, Behavior_RESET_REMAINING) {
+				queue = refunds
+			}
+
 			// Aggregate the hits into a single request
 			key := r.HashKey()
-			_, ok := hits[key]
+			_, ok := queue[key]
 			if ok {
 				// If any of our hits includes a request to RESET_REMAINING
 				// ensure the owning peer gets this behavior
 				if HasBehavior(r.Behavior, Behavior_RESET_REMAINING) {
-					SetBehavior(&hits[key].Behavior, Behavior_RESET_REMAINING, true)
+					SetBehavior(&queue[key].Behavior, Behavior_RESET_REMAINING, true)
 				}
-				hits[key].Hits += r.Hits
+				queue[key].Hits += r.Hits
 			} else {
-				hits[key] = r
+				queue[key] = r
 			}
 
 			// Send the hits if we reached our batch limit
-			if len(hits) == gm.conf.GlobalBatchLimit {
-				gm.sendHits(hits)
-				hits = make(map[string]*RateLimitReq)
+			if len(queue) == gm.conf.GlobalBatchLimit {
+				send()
 				return true
 			}
 
 			// If this is our first queued hit since last send
 			// queue the next interval
-			if len(hits) == 1 {
+			if len(hits)+len(refunds) == 1 {
 				interval.Next()
 			}
 
 		case <-interval.C:
-			if len(hits) != 0 {
-				gm.sendHits(hits)
-				hits = make(map[string]*RateLimitReq)
+			if len(hits)+len(refunds) != 0 {
+				send()
 			}
 		case <-done:
 			interval.Stop()
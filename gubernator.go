@@ -43,12 +43,19 @@ const (
 type V1Instance struct {
 	UnimplementedV1Server
 	UnimplementedPeersV1Server
-	global     *globalManager
-	peerMutex  sync.RWMutex
-	log        FieldLogger
-	conf       Config
-	isClosed   bool
-	workerPool *WorkerPool
+	global      *globalManager
+	multiRegion *multiRegionManager
+	peerMutex   sync.RWMutex
+	log         FieldLogger
+	conf        Config
+	isClosed    bool
+	// Set by Drain(), after which this instance no longer owns any rate limits
+	isDraining bool
+	workerPool  *WorkerPool
+	checkpoint  *checkpointManager
+	handoff     *handoffManager
+	replication *replicationManager
+	reservation *reservationManager
 }
 
 var (
@@ -69,6 +76,10 @@ var (
 		Name: "gubernator_over_limit_counter",
 		Help: "The number of rate limit checks that are over the limit.",
 	})
+	metricShadowOverLimitCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gubernator_shadow_over_limit_counter",
+		Help: "The number of SHADOW rate limit checks that would have been over the limit.  Label \"name\" is the name of the rate limit.",
+	}, []string{"name"})
 	metricConcurrentChecks = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "gubernator_concurrent_checks_counter",
 		Help: "The number of concurrent GetRateLimits API calls.",
@@ -81,10 +92,25 @@ var (
 		Name: "gubernator_command_counter",
 		Help: "The count of commands processed by each worker in WorkerPool.",
 	}, []string{"worker", "method"})
+	metricStoreErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gubernator_store_error_counter",
+		Help: "The count of errors returned by the Store.  Label \"method\" is the Store method which failed.",
+	}, []string{"method"})
 	metricWorkerQueue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "gubernator_worker_queue_length",
 		Help: "The count of requests queued up in WorkerPool.",
 	}, []string{"method", "worker"})
+	metricCacheSweepCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gubernator_cache_sweep_counter",
+		Help: "The count of expired rate limits removed from the cache by the background sweep.",
+	})
+	metricCacheSweepDuration = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "gubernator_cache_sweep_duration",
+		Help: "The duration of each worker's background sweep of expired rate limits in seconds.",
+		Objectives: map[float64]float64{
+			0.99: 0.001,
+		},
+	})
 
 	// Batch behavior.
 	metricBatchSendRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -122,6 +148,11 @@ func NewV1Instance(conf Config) (s *V1Instance, err error) {
 
 	s.workerPool = NewWorkerPool(&conf)
 	s.global = newGlobalManager(conf.Behaviors, s)
+	s.multiRegion = newMultiRegionManager(conf.Behaviors, s)
+	s.checkpoint = newCheckpointManager(conf.CheckpointInterval, s)
+	s.handoff = newHandoffManager(s)
+	s.replication = newReplicationManager(conf.Behaviors, s)
+	s.reservation = newReservationManager(conf.Behaviors, s)
 
 	// Register our instance with all GRPC servers
 	for _, srv := range conf.GRPCServers {
@@ -149,7 +180,12 @@ func (s *V1Instance) Close() (err error) {
 		return nil
 	}
 
+	s.replication.Close()
+	s.reservation.Close()
 	s.global.Close()
+	s.multiRegion.Close()
+	s.checkpoint.Close()
+	s.handoff.Close()
 
 	if s.conf.Loader != nil {
 		err = s.workerPool.Store(ctx)
@@ -186,6 +222,10 @@ func (s *V1Instance) GetRateLimits(ctx context.Context, r *GetRateLimitsReq) (*G
 			"Requests.RateLimits list too large; max size is '%d'", maxBatchSize)
 	}
 
+	if r.Atomic {
+		return &GetRateLimitsResp{Responses: s.getAtomicRateLimits(ctx, r.Requests)}, nil
+	}
+
 	resp := GetRateLimitsResp{
 		Responses: make([]*RateLimitResp, len(r.Requests)),
 	}
@@ -194,7 +234,6 @@ func (s *V1Instance) GetRateLimits(ctx context.Context, r *GetRateLimitsReq) (*G
 
 	// For each item in the request body
 	for i, req := range r.Requests {
-		key := req.Name + "_" + req.UniqueKey
 		var peer *PeerClient
 		var err error
 
@@ -220,10 +259,16 @@ func (s *V1Instance) GetRateLimits(ctx context.Context, r *GetRateLimitsReq) (*G
 			continue
 		}
 
+		if s.conf.Policies != nil {
+			s.conf.Policies.Apply(req)
+		}
+
 		if s.conf.Behaviors.ForceGlobal {
 			SetBehavior(&req.Behavior, Behavior_GLOBAL, true)
 		}
 
+		// The key depends on the behaviors, which may have been added by a policy
+		key := req.HashKey()
 		peer, err = s.GetPeer(ctx, key)
 		if err != nil {
 			countError(err, "Error in GetPeer")
@@ -281,6 +326,62 @@ func (s *V1Instance) GetRateLimits(ctx context.Context, r *GetRateLimitsReq) (*G
 		resp.Responses[a.Idx] = a.Resp
 	}
 
+	for i, req := range r.Requests {
+		shadowResponse(req, resp.Responses[i])
+	}
+
+	return &resp, nil
+}
+
+// ReleaseRateLimits returns the slots acquired from CONCURRENCY rate limits. The requests are routed to
+// the owning peers the same way as GetRateLimits, with `Hits` being the number of slots to release.
+func (s *V1Instance) ReleaseRateLimits(ctx context.Context, r *ReleaseRateLimitsReq) (*ReleaseRateLimitsResp, error) {
+	funcTimer := prometheus.NewTimer(metricFuncTimeDuration.WithLabelValues("V1Instance.ReleaseRateLimits"))
+	defer funcTimer.ObserveDuration()
+
+	resp := ReleaseRateLimitsResp{
+		Responses: make([]*RateLimitResp, len(r.Requests)),
+	}
+
+	// A release is a rate limit check with negative hits
+	var release GetRateLimitsReq
+	var idx []int
+	for i, req := range r.Requests {
+		// A release which only provides the name of a policy takes the algorithm from the policy
+		cpy := proto.Clone(req).(*RateLimitReq)
+		if s.conf.Policies != nil {
+			s.conf.Policies.Apply(cpy)
+		}
+
+		if cpy.Algorithm != Algorithm_CONCURRENCY {
+			metricCheckErrorCounter.WithLabelValues("Invalid request").Inc()
+			resp.Responses[i] = &RateLimitResp{Error: "field 'algorithm' must be CONCURRENCY"}
+			continue
+		}
+
+		if req.Hits < 0 {
+			metricCheckErrorCounter.WithLabelValues("Invalid request").Inc()
+			resp.Responses[i] = &RateLimitResp{Error: "field 'hits' cannot be negative"}
+			continue
+		}
+
+		cpy.Hits = -req.Hits
+		release.Requests = append(release.Requests, cpy)
+		idx = append(idx, i)
+	}
+
+	if len(release.Requests) == 0 {
+		return &resp, nil
+	}
+
+	out, err := s.GetRateLimits(ctx, &release)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rl := range out.Responses {
+		resp.Responses[idx[i]] = rl
+	}
 	return &resp, nil
 }
 
@@ -358,6 +459,15 @@ func (s *V1Instance) asyncRequest(ctx context.Context, req *AsyncReq) {
 				continue
 			}
 
+			// If the owner is unreachable, fail over to the next replica. Which may be us!
+			if next := s.replication.failoverPeer(req.Key, req.Peer, err); next != nil {
+				s.log.WithContext(ctx).WithError(err).WithField("key", req.Key).
+					Warnf("Owner unreachable; failing over to replica '%s'", next.Info().GRPCAddress)
+				attempts++
+				req.Peer = next
+				continue
+			}
+
 			// Not calling `countError()` because we expect the remote end to
 			// report this error.
 			err = errors.Wrap(err, fmt.Sprintf("Error while fetching rate limit '%s' from peer", req.Key))
@@ -397,6 +507,8 @@ func (s *V1Instance) getGlobalRateLimit(ctx context.Context, req *RateLimitReq)
 	cpy := proto.Clone(req).(*RateLimitReq)
 	SetBehavior(&cpy.Behavior, Behavior_NO_BATCHING, true)
 	SetBehavior(&cpy.Behavior, Behavior_GLOBAL, false)
+	// The owning peer is responsible for sending the hits to other regions
+	SetBehavior(&cpy.Behavior, Behavior_MULTI_REGION, false)
 
 	// Process the rate limit like we own it
 	resp, err = s.getLocalRateLimit(ctx, cpy)
@@ -408,6 +520,29 @@ func (s *V1Instance) getGlobalRateLimit(ctx context.Context, req *RateLimitReq)
 	return resp, nil
 }
 
+// TransferPeerRateLimits is called by the previous owner of the rate limits after the peer ring changed,
+// to hand off the rate limits now owned by this instance.
+func (s *V1Instance) TransferPeerRateLimits(ctx context.Context, r *TransferPeerRateLimitsReq) (*TransferPeerRateLimitsResp, error) {
+	return s.handoff.receive(ctx, r)
+}
+
+// ReplicatePeerRateLimits is called by the owner of the rate limits to store replicas with this instance,
+// such that this instance can take over the rate limits if the owner fails.
+func (s *V1Instance) ReplicatePeerRateLimits(ctx context.Context, r *ReplicatePeerRateLimitsReq) (*ReplicatePeerRateLimitsResp, error) {
+	return s.replication.receive(ctx, r)
+}
+
+// ReservePeerRateLimits is called by the peer which received an atomic request, to apply the hits of
+// the rate limits owned by this instance until the reservation is committed or aborted.
+func (s *V1Instance) ReservePeerRateLimits(ctx context.Context, r *ReservePeerRateLimitsReq) (*ReservePeerRateLimitsResp, error) {
+	return s.reservation.reserve(ctx, r)
+}
+
+// CommitPeerRateLimits commits or aborts a reservation made by ReservePeerRateLimits
+func (s *V1Instance) CommitPeerRateLimits(ctx context.Context, r *CommitPeerRateLimitsReq) (*CommitPeerRateLimitsResp, error) {
+	return s.reservation.finish(ctx, r)
+}
+
 // UpdatePeerGlobals updates the local cache with a list of global rate limits. This method should only
 // be called by a peer who is the owner of a global rate limit.
 func (s *V1Instance) UpdatePeerGlobals(ctx context.Context, r *UpdatePeerGlobalsReq) (*UpdatePeerGlobalsResp, error) {
@@ -433,6 +568,43 @@ func (s *V1Instance) UpdatePeerGlobals(ctx context.Context, r *UpdatePeerGlobals
 				Remaining: g.Status.Remaining,
 				CreatedAt: now,
 			}
+		case Algorithm_SLIDING_WINDOW:
+			// The owner does not share its windows, so count the hits it has
+			// accepted as belonging to a window which starts now.
+			item.Value = &SlidingWindowItem{
+				Limit:       g.Status.Limit,
+				Current:     g.Status.Limit - g.Status.Remaining,
+				WindowStart: now,
+			}
+		case Algorithm_GCRA:
+			// The owner reports the theoretical arrival time as the reset time
+			item.Value = &GCRAItem{
+				TAT: g.Status.ResetTime * 1000000,
+			}
+		case Algorithm_CONTINUOUS_TOKEN_BUCKET:
+			// Peers which predate `duration` do not send the refill rate, in which
+			// case the bucket refills at the rate of the next request
+			burst := g.Burst
+			if burst == 0 {
+				burst = g.Status.Limit
+			}
+			item.Value = &ContinuousTokenBucketItem{
+				Limit:     g.Status.Limit,
+				Duration:  g.Duration,
+				Burst:     burst,
+				Tokens:    float64(g.Status.Remaining),
+				UpdatedAt: now,
+			}
+		case Algorithm_CONCURRENCY:
+			// The owner does not share its leases, so hold the slots in use
+			// until the next owner lease expires.
+			item.Value = &ConcurrencyItem{
+				Limit: g.Status.Limit,
+				Leases: []ConcurrencyLease{{
+					ExpireAt: g.Status.ResetTime,
+					Count:    g.Status.Limit - g.Status.Remaining,
+				}},
+			}
 		}
 		err := s.workerPool.AddCacheItem(ctx, g.Key, item)
 		if err != nil {
@@ -494,7 +666,15 @@ func (s *V1Instance) GetPeerRateLimits(ctx context.Context, r *GetPeerRateLimits
 				SetBehavior(&rin.req.Behavior, Behavior_DRAIN_OVER_LIMIT, true)
 			}
 
-			rl, err := s.getLocalRateLimit(ctx, rin.req)
+			var rl *RateLimitResp
+			var err error
+			if peer := s.drainedOwner(ctx, rin.req.HashKey()); peer != nil {
+				// Peers which have not yet noticed that we are draining still send us rate limits
+				// we no longer own, relay them to the new owner.
+				rl, err = peer.GetPeerRateLimit(ctx, rin.req)
+			} else {
+				rl, err = s.getLocalRateLimit(ctx, rin.req)
+			}
 			if err != nil {
 				// Return the error for this request
 				err = errors.Wrap(err, "Error in getLocalRateLimit")
@@ -544,6 +724,18 @@ func (s *V1Instance) HealthCheck(ctx context.Context, r *HealthCheckReq) (health
 		}
 	}
 
+	if s.isDraining {
+		errs = append(errs, "instance is draining")
+	}
+
+	if hc, ok := s.conf.StoreV2.(StoreHealthChecker); ok {
+		if err := hc.HealthCheck(); err != nil {
+			err = fmt.Errorf("error returned from store.HealthCheck: %s", err)
+			span.RecordError(err)
+			errs = append(errs, err.Error())
+		}
+	}
+
 	health = &HealthCheckResp{
 		PeerCount: int32(len(localPeers) + len(regionPeers)),
 		Status:    Healthy,
@@ -562,6 +754,22 @@ func (s *V1Instance) HealthCheck(ctx context.Context, r *HealthCheckReq) (health
 	return health, nil
 }
 
+// shadowResponse reports a SHADOW rate limit as under the limit, leaving the status the rate limit
+// would have returned in the response metadata.
+func shadowResponse(r *RateLimitReq, resp *RateLimitResp) {
+	if !HasBehavior(r.Behavior, Behavior_SHADOW) || resp.Error != "" {
+		return
+	}
+	if resp.Status == Status_OVER_LIMIT {
+		metricShadowOverLimitCounter.WithLabelValues(r.Name).Inc()
+	}
+	if resp.Metadata == nil {
+		resp.Metadata = make(map[string]string)
+	}
+	resp.Metadata["shadow_status"] = resp.Status.String()
+	resp.Status = Status_UNDER_LIMIT
+}
+
 func (s *V1Instance) getLocalRateLimit(ctx context.Context, r *RateLimitReq) (_ *RateLimitResp, err error) {
 	ctx = tracing.StartNamedScope(ctx, "V1Instance.getLocalRateLimit", trace.WithAttributes(
 		attribute.String("ratelimit.key", r.UniqueKey),
@@ -577,13 +785,25 @@ func (s *V1Instance) getLocalRateLimit(ctx context.Context, r *RateLimitReq) (_
 		return nil, errors.Wrap(err, "during workerPool.GetRateLimit")
 	}
 
+	s.queueLocalUpdate(r, resp)
+	return resp, nil
+}
+
+// queueLocalUpdate sends the change to a rate limit we own to the peers which track it
+func (s *V1Instance) queueLocalUpdate(r *RateLimitReq, resp *RateLimitResp) {
 	metricGetRateLimitCounter.WithLabelValues("local").Inc()
 	// If global behavior, then broadcast update to all peers.
 	if HasBehavior(r.Behavior, Behavior_GLOBAL) {
 		s.global.QueueUpdate(r, resp)
 	}
 
-	return resp, nil
+	// If multi region behavior, then send the hits to the owning peers in the other regions.
+	// Hits we rejected were never allowed, so only accepted hits and refunds are sent.
+	if HasBehavior(r.Behavior, Behavior_MULTI_REGION) && (resp.Status == Status_UNDER_LIMIT || r.Hits < 0) {
+		s.multiRegion.QueueHit(r)
+	}
+
+	s.replication.QueueUpdate(r)
 }
 
 // SetPeers replaces the peers and shuts down all the previous peers.
@@ -592,6 +812,15 @@ func (s *V1Instance) SetPeers(peerInfo []PeerInfo) {
 	localPicker := s.conf.LocalPicker.New()
 	regionPicker := s.conf.RegionPicker.New()
 
+	// A draining instance no longer owns any rate limits, unless there is no other peer to own them
+	var localPeers int
+	for _, info := range peerInfo {
+		if info.DataCenter == s.conf.DataCenter {
+			localPeers++
+		}
+	}
+	skipOwner := s.IsDraining() && localPeers > 1
+
 	for _, info := range peerInfo {
 		// Add peers that are not in our local DC to the RegionPicker
 		if info.DataCenter != s.conf.DataCenter {
@@ -614,6 +843,9 @@ func (s *V1Instance) SetPeers(peerInfo []PeerInfo) {
 			regionPicker.Add(peer)
 			continue
 		}
+		if info.IsOwner && skipOwner {
+			continue
+		}
 		// If we don't have an existing PeerClient create a new one
 		peer := s.conf.LocalPicker.GetByPeerInfo(info)
 		if peer == nil {
@@ -644,6 +876,9 @@ func (s *V1Instance) SetPeers(peerInfo []PeerInfo) {
 
 	s.log.WithField("peers", peerInfo).Debug("peers updated")
 
+	// Hand off the rate limits we no longer own to their new owners
+	s.handoff.onRingChange(oldLocalPicker, localPicker)
+
 	// Shutdown any old peers we no longer need
 	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Behaviors.BatchTimeout)
 	defer cancel()
@@ -699,6 +934,69 @@ func (s *V1Instance) GetPeer(ctx context.Context, key string) (p *PeerClient, er
 	return p, nil
 }
 
+// Drain prepares the instance for shutdown. The instance is reported as unhealthy, stops owning rate
+// limits such that requests are forwarded to the peers which own them next, and hands off every rate
+// limit in the cache to those peers. Returns once the handoff completes or the context is cancelled.
+func (s *V1Instance) Drain(ctx context.Context) error {
+	s.peerMutex.Lock()
+	if s.isDraining {
+		s.peerMutex.Unlock()
+		return nil
+	}
+	s.isDraining = true
+	oldLocalPicker := s.conf.LocalPicker
+	localPicker := oldLocalPicker.New()
+	for _, peer := range oldLocalPicker.Peers() {
+		if !peer.Info().IsOwner {
+			localPicker.Add(peer)
+		}
+	}
+	// Without another peer to hand off to, we continue to own our rate limits until we exit
+	if len(localPicker.Peers()) == 0 {
+		s.peerMutex.Unlock()
+		return nil
+	}
+	s.conf.LocalPicker = localPicker
+	s.peerMutex.Unlock()
+
+	s.log.Info("Draining; handing off rate limits to the remaining peers")
+	return s.handoff.drain(ctx, oldLocalPicker, localPicker)
+}
+
+// IsDraining returns true once Drain() was called
+func (s *V1Instance) IsDraining() bool {
+	s.peerMutex.RLock()
+	defer s.peerMutex.RUnlock()
+	return s.isDraining
+}
+
+// drainedOwner returns the peer which now owns the rate limit if this instance is draining
+func (s *V1Instance) drainedOwner(ctx context.Context, key string) *PeerClient {
+	if !s.IsDraining() {
+		return nil
+	}
+	peer, err := s.GetPeer(ctx, key)
+	if err != nil || peer.Info().IsOwner {
+		return nil
+	}
+	return peer
+}
+
+// getReplicaPeers returns the owner of the key followed by the successors which hold its replicas
+func (s *V1Instance) getReplicaPeers(key string) []*PeerClient {
+	s.peerMutex.RLock()
+	defer s.peerMutex.RUnlock()
+	picker, ok := s.conf.LocalPicker.(ReplicaPicker)
+	if !ok {
+		return nil
+	}
+	peers, err := picker.GetN(key, s.conf.Behaviors.ReplicationFactor+1)
+	if err != nil {
+		return nil
+	}
+	return peers
+}
+
 func (s *V1Instance) GetPeerList() []*PeerClient {
 	s.peerMutex.RLock()
 	defer s.peerMutex.RUnlock()
@@ -711,22 +1009,49 @@ func (s *V1Instance) GetRegionPickers() map[string]PeerPicker {
 	return s.conf.RegionPicker.Pickers()
 }
 
+// GetRegionPeers returns the peer client which owns the hash key in each of the other regions
+func (s *V1Instance) GetRegionPeers(key string) ([]*PeerClient, error) {
+	s.peerMutex.RLock()
+	defer s.peerMutex.RUnlock()
+	peers, err := s.conf.RegionPicker.GetClients(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error in conf.RegionPicker.GetClients")
+	}
+	return peers, nil
+}
+
 // Describe fetches prometheus metrics to be registered
 func (s *V1Instance) Describe(ch chan<- *prometheus.Desc) {
 	metricBatchQueueLength.Describe(ch)
 	metricBatchSendDuration.Describe(ch)
 	metricBatchSendRetries.Describe(ch)
+	metricCacheSweepCounter.Describe(ch)
+	metricCacheSweepDuration.Describe(ch)
 	metricCheckErrorCounter.Describe(ch)
 	metricCommandCounter.Describe(ch)
 	metricConcurrentChecks.Describe(ch)
 	metricFuncTimeDuration.Describe(ch)
 	metricGetRateLimitCounter.Describe(ch)
 	metricOverLimitCounter.Describe(ch)
+	metricShadowOverLimitCounter.Describe(ch)
+	metricStoreErrorCounter.Describe(ch)
 	metricWorkerQueue.Describe(ch)
+	s.checkpoint.metricCheckpointCounter.Describe(ch)
+	s.checkpoint.metricCheckpointDuration.Describe(ch)
+	s.checkpoint.metricCheckpointSize.Describe(ch)
+	s.handoff.metricHandoffCounter.Describe(ch)
+	s.handoff.metricHandoffDuration.Describe(ch)
+	s.replication.metricReplicationCounter.Describe(ch)
+	s.replication.metricReplicationDuration.Describe(ch)
+	s.replication.metricFailoverCounter.Describe(ch)
+	s.reservation.metricReservationCounter.Describe(ch)
 	s.global.metricBroadcastCounter.Describe(ch)
 	s.global.metricBroadcastDuration.Describe(ch)
 	s.global.metricGlobalQueueLength.Describe(ch)
 	s.global.metricGlobalSendDuration.Describe(ch)
+	s.multiRegion.metricMultiRegionQueueLength.Describe(ch)
+	s.multiRegion.metricMultiRegionSendDuration.Describe(ch)
+	s.multiRegion.metricMultiRegionSendErrors.Describe(ch)
 }
 
 // Collect fetches metrics from the server for use by prometheus
@@ -734,17 +1059,33 @@ func (s *V1Instance) Collect(ch chan<- prometheus.Metric) {
 	metricBatchQueueLength.Collect(ch)
 	metricBatchSendDuration.Collect(ch)
 	metricBatchSendRetries.Collect(ch)
+	metricCacheSweepCounter.Collect(ch)
+	metricCacheSweepDuration.Collect(ch)
 	metricCheckErrorCounter.Collect(ch)
 	metricCommandCounter.Collect(ch)
 	metricConcurrentChecks.Collect(ch)
 	metricFuncTimeDuration.Collect(ch)
 	metricGetRateLimitCounter.Collect(ch)
 	metricOverLimitCounter.Collect(ch)
+	metricShadowOverLimitCounter.Collect(ch)
+	metricStoreErrorCounter.Collect(ch)
 	metricWorkerQueue.Collect(ch)
+	s.checkpoint.metricCheckpointCounter.Collect(ch)
+	s.checkpoint.metricCheckpointDuration.Collect(ch)
+	s.checkpoint.metricCheckpointSize.Collect(ch)
+	s.handoff.metricHandoffCounter.Collect(ch)
+	s.handoff.metricHandoffDuration.Collect(ch)
+	s.replication.metricReplicationCounter.Collect(ch)
+	s.replication.metricReplicationDuration.Collect(ch)
+	s.replication.metricFailoverCounter.Collect(ch)
+	s.reservation.metricReservationCounter.Collect(ch)
 	s.global.metricBroadcastCounter.Collect(ch)
 	s.global.metricBroadcastDuration.Collect(ch)
 	s.global.metricGlobalQueueLength.Collect(ch)
 	s.global.metricGlobalSendDuration.Collect(ch)
+	s.multiRegion.metricMultiRegionQueueLength.Collect(ch)
+	s.multiRegion.metricMultiRegionSendDuration.Collect(ch)
+	s.multiRegion.metricMultiRegionSendErrors.Collect(ch)
 }
 
 // HasBehavior returns true if the provided behavior is set
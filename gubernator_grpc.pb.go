@@ -34,8 +34,13 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	V1_GetRateLimits_FullMethodName = "/pb.gubernator.V1/GetRateLimits"
-	V1_HealthCheck_FullMethodName   = "/pb.gubernator.V1/HealthCheck"
+	V1_GetRateLimits_FullMethodName     = "/pb.gubernator.V1/GetRateLimits"
+	V1_ReleaseRateLimits_FullMethodName = "/pb.gubernator.V1/ReleaseRateLimits"
+	V1_InspectRateLimits_FullMethodName = "/pb.gubernator.V1/InspectRateLimits"
+	V1_DeleteRateLimits_FullMethodName  = "/pb.gubernator.V1/DeleteRateLimits"
+	V1_SetRateLimits_FullMethodName     = "/pb.gubernator.V1/SetRateLimits"
+	V1_ListRateLimits_FullMethodName    = "/pb.gubernator.V1/ListRateLimits"
+	V1_HealthCheck_FullMethodName       = "/pb.gubernator.V1/HealthCheck"
 )
 
 // V1Client is the client API for V1 service.
@@ -44,6 +49,21 @@ const (
 type V1Client interface {
 	// Given a list of rate limit requests, return the rate limits of each.
 	GetRateLimits(ctx context.Context, in *GetRateLimitsReq, opts ...grpc.CallOption) (*GetRateLimitsResp, error)
+	// Given a list of CONCURRENCY rate limit requests, release the number of slots given
+	// by `hits` which were previously acquired by GetRateLimits. Leases are not tracked per
+	// caller, so the oldest slots are released first regardless of which caller acquired them.
+	ReleaseRateLimits(ctx context.Context, in *ReleaseRateLimitsReq, opts ...grpc.CallOption) (*ReleaseRateLimitsResp, error)
+	// Admin: Return the current state of the given rate limits from their owning peers
+	// without consuming any hits.
+	InspectRateLimits(ctx context.Context, in *InspectRateLimitsReq, opts ...grpc.CallOption) (*InspectRateLimitsResp, error)
+	// Admin: Remove the state of the given rate limits from their owning peers, such that the
+	// next hit starts a new rate limit.
+	DeleteRateLimits(ctx context.Context, in *DeleteRateLimitsReq, opts ...grpc.CallOption) (*DeleteRateLimitsResp, error)
+	// Admin: Overwrite the state of the given rate limits on their owning peers.
+	SetRateLimits(ctx context.Context, in *SetRateLimitsReq, opts ...grpc.CallOption) (*SetRateLimitsResp, error)
+	// Admin: List the state of the rate limits held by every peer in the local cluster which match
+	// the provided filters, ordered by their key.
+	ListRateLimits(ctx context.Context, in *ListRateLimitsReq, opts ...grpc.CallOption) (*ListRateLimitsResp, error)
 	// This method is for round trip benchmarking and can be used by
 	// the client to determine connectivity to the server
 	HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...grpc.CallOption) (*HealthCheckResp, error)
@@ -66,6 +86,51 @@ func (c *v1Client) GetRateLimits(ctx context.Context, in *GetRateLimitsReq, opts
 	return out, nil
 }
 
+func (c *v1Client) ReleaseRateLimits(ctx context.Context, in *ReleaseRateLimitsReq, opts ...grpc.CallOption) (*ReleaseRateLimitsResp, error) {
+	out := new(ReleaseRateLimitsResp)
+	err := c.cc.Invoke(ctx, V1_ReleaseRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) InspectRateLimits(ctx context.Context, in *InspectRateLimitsReq, opts ...grpc.CallOption) (*InspectRateLimitsResp, error) {
+	out := new(InspectRateLimitsResp)
+	err := c.cc.Invoke(ctx, V1_InspectRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) DeleteRateLimits(ctx context.Context, in *DeleteRateLimitsReq, opts ...grpc.CallOption) (*DeleteRateLimitsResp, error) {
+	out := new(DeleteRateLimitsResp)
+	err := c.cc.Invoke(ctx, V1_DeleteRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) SetRateLimits(ctx context.Context, in *SetRateLimitsReq, opts ...grpc.CallOption) (*SetRateLimitsResp, error) {
+	out := new(SetRateLimitsResp)
+	err := c.cc.Invoke(ctx, V1_SetRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) ListRateLimits(ctx context.Context, in *ListRateLimitsReq, opts ...grpc.CallOption) (*ListRateLimitsResp, error) {
+	out := new(ListRateLimitsResp)
+	err := c.cc.Invoke(ctx, V1_ListRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *v1Client) HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...grpc.CallOption) (*HealthCheckResp, error) {
 	out := new(HealthCheckResp)
 	err := c.cc.Invoke(ctx, V1_HealthCheck_FullMethodName, in, out, opts...)
@@ -81,6 +146,21 @@ func (c *v1Client) HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...
 type V1Server interface {
 	// Given a list of rate limit requests, return the rate limits of each.
 	GetRateLimits(context.Context, *GetRateLimitsReq) (*GetRateLimitsResp, error)
+	// Given a list of CONCURRENCY rate limit requests, release the number of slots given
+	// by `hits` which were previously acquired by GetRateLimits. Leases are not tracked per
+	// caller, so the oldest slots are released first regardless of which caller acquired them.
+	ReleaseRateLimits(context.Context, *ReleaseRateLimitsReq) (*ReleaseRateLimitsResp, error)
+	// Admin: Return the current state of the given rate limits from their owning peers
+	// without consuming any hits.
+	InspectRateLimits(context.Context, *InspectRateLimitsReq) (*InspectRateLimitsResp, error)
+	// Admin: Remove the state of the given rate limits from their owning peers, such that the
+	// next hit starts a new rate limit.
+	DeleteRateLimits(context.Context, *DeleteRateLimitsReq) (*DeleteRateLimitsResp, error)
+	// Admin: Overwrite the state of the given rate limits on their owning peers.
+	SetRateLimits(context.Context, *SetRateLimitsReq) (*SetRateLimitsResp, error)
+	// Admin: List the state of the rate limits held by every peer in the local cluster which match
+	// the provided filters, ordered by their key.
+	ListRateLimits(context.Context, *ListRateLimitsReq) (*ListRateLimitsResp, error)
 	// This method is for round trip benchmarking and can be used by
 	// the client to determine connectivity to the server
 	HealthCheck(context.Context, *HealthCheckReq) (*HealthCheckResp, error)
@@ -93,6 +173,21 @@ type UnimplementedV1Server struct {
 func (UnimplementedV1Server) GetRateLimits(context.Context, *GetRateLimitsReq) (*GetRateLimitsResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRateLimits not implemented")
 }
+func (UnimplementedV1Server) ReleaseRateLimits(context.Context, *ReleaseRateLimitsReq) (*ReleaseRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseRateLimits not implemented")
+}
+func (UnimplementedV1Server) InspectRateLimits(context.Context, *InspectRateLimitsReq) (*InspectRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectRateLimits not implemented")
+}
+func (UnimplementedV1Server) DeleteRateLimits(context.Context, *DeleteRateLimitsReq) (*DeleteRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRateLimits not implemented")
+}
+func (UnimplementedV1Server) SetRateLimits(context.Context, *SetRateLimitsReq) (*SetRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRateLimits not implemented")
+}
+func (UnimplementedV1Server) ListRateLimits(context.Context, *ListRateLimitsReq) (*ListRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRateLimits not implemented")
+}
 func (UnimplementedV1Server) HealthCheck(context.Context, *HealthCheckReq) (*HealthCheckResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -126,6 +221,96 @@ func _V1_GetRateLimits_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _V1_ReleaseRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).ReleaseRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: V1_ReleaseRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).ReleaseRateLimits(ctx, req.(*ReleaseRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_InspectRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).InspectRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: V1_InspectRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).InspectRateLimits(ctx, req.(*InspectRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_DeleteRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).DeleteRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: V1_DeleteRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).DeleteRateLimits(ctx, req.(*DeleteRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_SetRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).SetRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: V1_SetRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).SetRateLimits(ctx, req.(*SetRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_ListRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).ListRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: V1_ListRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).ListRateLimits(ctx, req.(*ListRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _V1_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckReq)
 	if err := dec(in); err != nil {
@@ -155,6 +340,26 @@ var V1_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetRateLimits",
 			Handler:    _V1_GetRateLimits_Handler,
 		},
+		{
+			MethodName: "ReleaseRateLimits",
+			Handler:    _V1_ReleaseRateLimits_Handler,
+		},
+		{
+			MethodName: "InspectRateLimits",
+			Handler:    _V1_InspectRateLimits_Handler,
+		},
+		{
+			MethodName: "DeleteRateLimits",
+			Handler:    _V1_DeleteRateLimits_Handler,
+		},
+		{
+			MethodName: "SetRateLimits",
+			Handler:    _V1_SetRateLimits_Handler,
+		},
+		{
+			MethodName: "ListRateLimits",
+			Handler:    _V1_ListRateLimits_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _V1_HealthCheck_Handler,
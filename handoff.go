@@ -0,0 +1,245 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handoffManager hands off the rate limits this instance no longer owns to their new owners
+// after the peer ring changed. Without a handoff the new owner starts the rate limit again
+// from a full bucket, giving the client extra hits every time the cluster is scaled.
+type handoffManager struct {
+	wg       syncutil.WaitGroup
+	log      FieldLogger
+	instance *V1Instance
+	// Ensures only a single handoff runs at a time
+	mutex  sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	metricHandoffDuration prometheus.Summary
+	metricHandoffCounter  *prometheus.CounterVec
+}
+
+func newHandoffManager(instance *V1Instance) *handoffManager {
+	hm := handoffManager{
+		log:      instance.log,
+		instance: instance,
+		metricHandoffDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:       "gubernator_handoff_duration",
+			Help:       "The duration of handing off rate limits to their new owners after the peer ring changed in seconds.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+		}),
+		metricHandoffCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gubernator_handoff_counter",
+			Help: "The count of rate limits handed off after the peer ring changed.  Label \"result\" may be \"sent\" or \"failed\" for rate limits sent to their new owner, or \"accepted\" or \"rejected\" for rate limits received from their previous owner.",
+		}, []string{"result"}),
+	}
+	hm.ctx, hm.cancel = context.WithCancel(context.Background())
+	return &hm
+}
+
+// onRingChange hands off the rate limits owned by this instance under the old picker which
+// are owned by another peer under the new picker. The handoff runs in the background.
+func (hm *handoffManager) onRingChange(oldPicker, newPicker PeerPicker) {
+	if hm.instance.conf.Behaviors.DisableHandoff || len(oldPicker.Peers()) == 0 {
+		return
+	}
+
+	hm.wg.Go(func() {
+		hm.mutex.Lock()
+		defer hm.mutex.Unlock()
+
+		ctx, cancel := context.WithTimeout(hm.ctx, hm.instance.conf.Behaviors.HandoffTimeout)
+		defer cancel()
+		if err := hm.handoff(ctx, oldPicker, newPicker); err != nil {
+			hm.log.WithError(err).Error("while handing off rate limits to their new owners")
+		}
+	})
+}
+
+// drain hands off every rate limit owned under the old picker to its owner under the new picker,
+// which no longer includes this instance. Unlike onRingChange() this waits for the handoff to
+// complete, and is not disabled by BehaviorConfig.DisableHandoff.
+func (hm *handoffManager) drain(ctx context.Context, oldPicker, newPicker PeerPicker) error {
+	hm.mutex.Lock()
+	defer hm.mutex.Unlock()
+	return hm.handoff(ctx, oldPicker, newPicker)
+}
+
+func (hm *handoffManager) handoff(ctx context.Context, oldPicker, newPicker PeerPicker) error {
+	defer prometheus.NewTimer(hm.metricHandoffDuration).ObserveDuration()
+
+	// Collect the items which changed owner, grouped by their new owner
+	moved := make(map[*PeerClient][]*CacheItem)
+	now := MillisecondNow()
+	err := hm.instance.workerPool.EachCacheItem(ctx, func(item *CacheItem) {
+		if item.ExpireAt <= now {
+			return
+		}
+		oldOwner, err := oldPicker.Get(item.Key)
+		if err != nil || !oldOwner.Info().IsOwner {
+			// We hold a copy of a GLOBAL rate limit owned by another peer
+			return
+		}
+		newOwner, err := newPicker.Get(item.Key)
+		if err != nil || newOwner.Info().IsOwner {
+			return
+		}
+		moved[newOwner] = append(moved[newOwner], copyCacheItem(item))
+	})
+	if err != nil {
+		return errors.Wrap(err, "while collecting rate limits which changed owner")
+	}
+
+	var sent, failed int
+	for peer, items := range moved {
+		for len(items) != 0 {
+			batch := items
+			if len(batch) > maxBatchSize {
+				batch = batch[:maxBatchSize]
+			}
+			items = items[len(batch):]
+
+			if err := hm.send(ctx, peer, batch); err != nil {
+				failed += len(batch)
+				hm.log.WithError(err).WithField("peer", peer.Info().GRPCAddress).
+					Errorf("while handing off %d rate limits", len(batch))
+				continue
+			}
+			sent += len(batch)
+		}
+	}
+	hm.metricHandoffCounter.WithLabelValues("sent").Add(float64(sent))
+	hm.metricHandoffCounter.WithLabelValues("failed").Add(float64(failed))
+
+	if sent != 0 || failed != 0 {
+		hm.log.Infof("Handed off %d rate limits to their new owners; %d failed", sent, failed)
+	}
+	return nil
+}
+
+// send transfers the items to the peer, then removes them from our cache
+func (hm *handoffManager) send(ctx context.Context, peer *PeerClient, items []*CacheItem) error {
+	req := &TransferPeerRateLimitsReq{
+		Items:   make([][]byte, 0, len(items)),
+		Version: uint32(snapshotVersion),
+	}
+	for _, item := range items {
+		b, err := appendSnapshotItem(nil, item)
+		if err != nil {
+			return errors.Wrapf(err, "while encoding rate limit '%s'", item.Key)
+		}
+		req.Items = append(req.Items, b)
+	}
+
+	if _, err := peer.TransferPeerRateLimits(ctx, req); err != nil {
+		return err
+	}
+
+	// Hits which arrived with the old picker after the items were copied are lost,
+	// which is no worse than the new owner starting from a full bucket.
+	for _, item := range items {
+		if err := hm.instance.workerPool.RemoveCacheItem(ctx, item.Key); err != nil {
+			return errors.Wrapf(err, "while removing rate limit '%s' from the cache", item.Key)
+		}
+	}
+	return nil
+}
+
+// receive adds the items handed off by their previous owner to our cache. If we already hold an
+// item for the same rate limit, because hits arrived before the handoff, we keep whichever
+// item has fewer hits remaining.
+func (hm *handoffManager) receive(ctx context.Context, r *TransferPeerRateLimitsReq) (*TransferPeerRateLimitsResp, error) {
+	if len(r.Items) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'TransferPeerRateLimitsReq.items' list too large; max size is '%d'", maxBatchSize)
+	}
+	if r.Version != uint32(snapshotVersion) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"unsupported 'TransferPeerRateLimitsReq.version' '%d'; expected '%d'", r.Version, snapshotVersion)
+	}
+
+	// The sender keeps the rate limits if the transfer fails
+	if hm.instance.IsDraining() {
+		return nil, status.Error(codes.Unavailable, "instance is draining")
+	}
+
+	resp := &TransferPeerRateLimitsResp{}
+	var rejected int
+	for _, b := range r.Items {
+		item, err := decodeSnapshotItem(b)
+		if err != nil {
+			rejected++
+			hm.log.WithError(err).Error("while decoding rate limit handed off by peer")
+			continue
+		}
+		if item.ExpireAt <= MillisecondNow() {
+			rejected++
+			continue
+		}
+
+		added, err := hm.instance.workerPool.MergeCacheItem(ctx, item, func(existing *CacheItem) bool {
+			return handoffReplaces(existing, item, MillisecondNow())
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Error in workerPool.MergeCacheItem")
+		}
+		if !added {
+			rejected++
+			continue
+		}
+		resp.Accepted++
+	}
+
+	hm.metricHandoffCounter.WithLabelValues("accepted").Add(float64(resp.Accepted))
+	hm.metricHandoffCounter.WithLabelValues("rejected").Add(float64(rejected))
+	return resp, nil
+}
+
+// handoffReplaces returns true if the incoming item has fewer hits remaining than the existing item
+func handoffReplaces(existing, incoming *CacheItem, now int64) bool {
+	// The hits remaining of a GCRA rate limit depend on its config, but a later theoretical
+	// arrival time always means fewer hits remain
+	if e, ok := existing.Value.(*GCRAItem); ok {
+		if i, ok := incoming.Value.(*GCRAItem); ok {
+			return i.TAT > e.TAT
+		}
+	}
+
+	var existingState, incomingState RateLimitState
+	setCacheItemState(&existingState, existing, nil, now)
+	setCacheItemState(&incomingState, incoming, nil, now)
+	if existingState.Error != "" {
+		return true
+	}
+	return incomingState.Error == "" && incomingState.Remaining < existingState.Remaining
+}
+
+// Close cancels any handoff in progress
+func (hm *handoffManager) Close() {
+	hm.cancel()
+	hm.wg.Wait()
+}
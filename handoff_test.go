@@ -0,0 +1,200 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoff(t *testing.T) {
+	a := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer a.Close()
+	b := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer b.Close()
+
+	// Each server starts out owning every rate limit
+	const count = 20
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("account:%d", i)
+		a.hit(t, "test_handoff", key, 3)
+		if i%2 == 0 {
+			b.hit(t, "test_handoff", key, 1)
+		} else {
+			b.hit(t, "test_handoff", key, 5)
+		}
+	}
+
+	// Both servers hand off the rate limits which are now owned by the other server
+	addrA, addrB := a.listener.Addr().String(), b.listener.Addr().String()
+	a.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA, IsOwner: true}, {GRPCAddress: addrB}})
+	b.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA}, {GRPCAddress: addrB, IsOwner: true}})
+
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		sent := collectorValue(t, a.srv, "gubernator_handoff_counter", "sent") +
+			collectorValue(t, b.srv, "gubernator_handoff_counter", "sent")
+		assert.Equal(t, float64(count), sent)
+	})
+	assert.Zero(t, collectorValue(t, a.srv, "gubernator_handoff_counter", "failed"))
+	assert.Zero(t, collectorValue(t, b.srv, "gubernator_handoff_counter", "failed"))
+
+	// The new owner keeps whichever rate limit has fewer hits remaining
+	for i := 0; i < count; i++ {
+		expected := int64(7)
+		if i%2 != 0 {
+			expected = 5
+		}
+		resp := a.hit(t, "test_handoff", fmt.Sprintf("account:%d", i), 0)
+		assert.Equal(t, expected, resp.Remaining, "account:%d", i)
+	}
+}
+
+func TestHandoffDisabled(t *testing.T) {
+	conf := gubernator.Config{Behaviors: gubernator.BehaviorConfig{DisableHandoff: true}}
+	a := newV1Server(t, "localhost:0", conf)
+	defer a.Close()
+	b := newV1Server(t, "localhost:0", conf)
+	defer b.Close()
+
+	addrA, addrB := a.listener.Addr().String(), b.listener.Addr().String()
+	var key string
+	for i := 0; key == ""; i++ {
+		a.hit(t, "test_handoff", fmt.Sprintf("account:%d", i), 3)
+		a.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA, IsOwner: true}, {GRPCAddress: addrB}})
+		peer, err := a.srv.GetPeer(context.Background(), fmt.Sprintf("test_handoff_account:%d", i))
+		require.NoError(t, err)
+		if !peer.Info().IsOwner {
+			key = fmt.Sprintf("account:%d", i)
+		}
+		a.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA, IsOwner: true}})
+	}
+
+	a.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA, IsOwner: true}, {GRPCAddress: addrB}})
+	b.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA}, {GRPCAddress: addrB, IsOwner: true}})
+
+	// The new owner starts again from a full bucket
+	assert.Equal(t, int64(10), a.hit(t, "test_handoff", key, 0).Remaining)
+}
+
+func TestDrain(t *testing.T) {
+	a := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer a.Close()
+	b := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer b.Close()
+
+	addrA, addrB := a.listener.Addr().String(), b.listener.Addr().String()
+	a.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA, IsOwner: true}, {GRPCAddress: addrB}})
+	b.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA}, {GRPCAddress: addrB, IsOwner: true}})
+
+	// Hit every rate limit through both servers, such that each owns some of them
+	const count = 20
+	for i := 0; i < count; i++ {
+		a.hit(t, "test_handoff", fmt.Sprintf("account:%d", i), 1)
+		b.hit(t, "test_handoff", fmt.Sprintf("account:%d", i), 2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	require.NoError(t, a.srv.Drain(ctx))
+	assert.True(t, a.srv.IsDraining())
+	assert.Zero(t, collectorValue(t, a.srv, "gubernator_handoff_counter", "failed"))
+
+	client, err := gubernator.DialV1Server(addrA, nil)
+	require.NoError(t, err)
+	health, err := client.HealthCheck(ctx, &gubernator.HealthCheckReq{})
+	require.NoError(t, err)
+	assert.Equal(t, gubernator.UnHealthy, health.Status)
+	assert.Contains(t, health.Message, "instance is draining")
+
+	// The remaining server owns every rate limit, and requests which still arrive at
+	// the draining server are forwarded to it
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("account:%d", i)
+		assert.Equal(t, int64(7), b.hit(t, "test_handoff", key, 0).Remaining, key)
+		assert.Equal(t, int64(6), a.hit(t, "test_handoff", key, 1).Remaining, key)
+		assert.Equal(t, int64(6), b.hit(t, "test_handoff", key, 0).Remaining, key)
+	}
+
+	// Peers which have not yet noticed the drain cannot hand off rate limits to us
+	peer, err := gubernator.NewPeerClient(gubernator.PeerConfig{Info: gubernator.PeerInfo{GRPCAddress: addrA}})
+	require.NoError(t, err)
+	defer peer.Shutdown(ctx)
+	_, err = peer.TransferPeerRateLimits(ctx, &gubernator.TransferPeerRateLimitsReq{Version: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instance is draining")
+}
+
+func TestHandoffVersion(t *testing.T) {
+	a := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	peer, err := gubernator.NewPeerClient(gubernator.PeerConfig{
+		Info: gubernator.PeerInfo{GRPCAddress: a.listener.Addr().String()},
+	})
+	require.NoError(t, err)
+	defer peer.Shutdown(ctx)
+
+	// Items encoded in a snapshot format we do not understand are rejected
+	_, err = peer.TransferPeerRateLimits(ctx, &gubernator.TransferPeerRateLimitsReq{Version: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported 'TransferPeerRateLimitsReq.version'")
+
+	resp, err := peer.TransferPeerRateLimits(ctx, &gubernator.TransferPeerRateLimitsReq{Version: 1})
+	require.NoError(t, err)
+	assert.Zero(t, resp.Accepted)
+}
+
+func TestDaemonDrainReady(t *testing.T) {
+	d := spawnDaemon(t, gubernator.DaemonConfig{
+		GRPCListenAddress:   "127.0.0.1:9697",
+		HTTPListenAddress:   "127.0.0.1:9687",
+		DrainReadinessDelay: time.Millisecond * 100,
+	})
+	defer d.Close()
+
+	ready := func() int {
+		resp, err := http.Get(fmt.Sprintf("http://%s/v1/Ready", d.HTTPListener.Addr().String()))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, ready())
+
+	// Readiness fails before the instance stops owning its rate limits
+	begin := time.Now()
+	done := make(chan error)
+	go func() { done <- d.Drain(context.Background()) }()
+	testutil.UntilPass(t, 20, time.Millisecond*10, func(t testutil.TestingT) {
+		assert.Equal(t, http.StatusServiceUnavailable, ready())
+	})
+	assert.False(t, d.V1Server.IsDraining())
+
+	require.NoError(t, <-done)
+	assert.GreaterOrEqual(t, time.Since(begin), time.Millisecond*100)
+	assert.True(t, d.V1Server.IsDraining())
+	assert.Equal(t, http.StatusServiceUnavailable, ready())
+}
@@ -18,6 +18,8 @@ package gubernator
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mailgun/holster/v4/clock"
@@ -50,7 +52,12 @@ func (i *Interval) run(d clock.Duration) {
 		select {
 		case <-i.in:
 			time.Sleep(d)
-			i.C <- struct{}{}
+			// The reader may have stopped reading `C` before calling Stop()
+			select {
+			case i.C <- struct{}{}:
+			case <-done:
+				return false
+			}
 			return true
 		case <-done:
 			return false
@@ -80,32 +87,98 @@ const (
 	GregorianYears
 )
 
-// GregorianDuration returns the entire duration of the Gregorian interval
-func GregorianDuration(now clock.Time, d int64) (int64, error) {
+var errInvalidGregorian = errors.New("behavior DURATION_IS_GREGORIAN is set; but `Duration` is not a valid gregorian interval")
+
+// GregorianCalendar determines where gregorian intervals begin. The zero value begins intervals in the
+// location of `now`, weeks on Sunday and months on their first day.
+type GregorianCalendar struct {
+	// The location in which intervals begin and end, if nil the location of `now` is used
+	Location *time.Location
+	// The day on which GregorianWeeks intervals begin
+	WeekStart time.Weekday
+	// The day of the month on which GregorianMonths intervals begin, such as a billing day. Months with
+	// fewer days begin on their last day. Zero begins months on their first day.
+	MonthStartDay int
+}
+
+// Bounds returns the beginning of the gregorian interval `d` which contains `now`, and the beginning
+// of the interval which follows it.
+func (c GregorianCalendar) Bounds(now clock.Time, d int64) (begin, end clock.Time, err error) {
+	if c.WeekStart < time.Sunday || c.WeekStart > time.Saturday {
+		return begin, end, fmt.Errorf("week start '%d' is not a valid day of the week", c.WeekStart)
+	}
+	if c.MonthStartDay < 0 || c.MonthStartDay > 31 {
+		return begin, end, fmt.Errorf("month start day '%d' is not a valid day of the month", c.MonthStartDay)
+	}
+	if c.Location != nil {
+		now = now.In(c.Location)
+	}
+	loc := now.Location()
+	y, m, day := now.Date()
+
 	switch d {
 	case GregorianMinutes:
-		return 60000, nil
+		begin = now.Truncate(clock.Minute)
+		return begin, begin.Add(clock.Minute), nil
 	case GregorianHours:
-		return 3.6e+6, nil
+		// See time.Truncate() documentation on why we can't reliably use time.Truncate(Hour) here.
+		begin = clock.Date(y, m, day, now.Hour(), 0, 0, 0, loc)
+		return begin, begin.Add(clock.Hour), nil
 	case GregorianDays:
-		return 8.64e+7, nil
+		begin = clock.Date(y, m, day, 0, 0, 0, 0, loc)
+		return begin, begin.AddDate(0, 0, 1), nil
 	case GregorianWeeks:
-		return 0, errors.New("`Duration = GregorianWeeks` not yet supported; consider making a PR!`")
+		offset := (int(now.Weekday()) - int(c.WeekStart) + 7) % 7
+		begin = clock.Date(y, m, day-offset, 0, 0, 0, 0, loc)
+		return begin, begin.AddDate(0, 0, 7), nil
 	case GregorianMonths:
-		y, m, _ := now.Date()
-		// Given the beginning of the month, subtract the end of the current month to get the duration
-		begin := clock.Date(y, m, 1, 0, 0, 0, 0, now.Location())
-		end := begin.AddDate(0, 1, 0).Add(-clock.Nanosecond)
-		return end.UnixNano() - begin.UnixNano()/1000000, nil
+		begin = c.monthStart(y, m, loc)
+		if now.Before(begin) {
+			begin = c.monthStart(y, m-1, loc)
+		}
+		return begin, c.monthStart(begin.Year(), begin.Month()+1, loc), nil
 	case GregorianYears:
-		y, _, _ := now.Date()
-		// Given the beginning of the year, subtract the end of the current year to get the duration
-		begin := clock.Date(y, clock.January, 1, 0, 0, 0, 0, now.Location())
-		end := begin.AddDate(1, 0, 0).Add(-clock.Nanosecond)
-		return end.UnixNano() - begin.UnixNano()/1000000, nil
+		begin = clock.Date(y, clock.January, 1, 0, 0, 0, 0, loc)
+		return begin, begin.AddDate(1, 0, 0), nil
 	}
-	return 0, errors.New("behavior DURATION_IS_GREGORIAN is set; but `Duration` is not a valid gregorian interval")
+	return begin, end, errInvalidGregorian
+}
+
+// monthStart returns the beginning of the interval which starts in the month `m`
+func (c GregorianCalendar) monthStart(y int, m clock.Month, loc *time.Location) clock.Time {
+	first := clock.Date(y, m, 1, 0, 0, 0, 0, loc)
+	if c.MonthStartDay <= 1 {
+		return first
+	}
+	day := c.MonthStartDay
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Duration returns the entire duration of the gregorian interval which contains `now` in milliseconds
+func (c GregorianCalendar) Duration(now clock.Time, d int64) (int64, error) {
+	begin, end, err := c.Bounds(now, d)
+	if err != nil {
+		return 0, err
+	}
+	return end.Sub(begin).Milliseconds(), nil
+}
+
+// Expiration returns the last millisecond of the gregorian interval which contains `now`, in
+// milliseconds since the epoch
+func (c GregorianCalendar) Expiration(now clock.Time, d int64) (int64, error) {
+	_, end, err := c.Bounds(now, d)
+	if err != nil {
+		return 0, err
+	}
+	return end.Add(-clock.Nanosecond).UnixNano() / 1000000, nil
+}
 
+// GregorianDuration returns the entire duration of the Gregorian interval
+func GregorianDuration(now clock.Time, d int64) (int64, error) {
+	return GregorianCalendar{}.Duration(now, d)
 }
 
 // GregorianExpiration returns an gregorian interval as defined by the
@@ -115,34 +188,49 @@ func GregorianDuration(now clock.Time, d int64) (int64, error) {
 // Example: If `now` is 2019-01-01 11:20:10 and `d` = GregorianMinutes then the return
 // expire time would be 2019-01-01 11:20:59 in milliseconds since epoch
 func GregorianExpiration(now clock.Time, d int64) (int64, error) {
-	switch d {
-	case GregorianMinutes:
-		return now.Truncate(clock.Minute).
-			Add(clock.Minute-clock.Nanosecond).
-			UnixNano() / 1000000, nil
-	case GregorianHours:
-		y, m, d := now.Date()
-		// See time.Truncate() documentation on why we can' reliably use time.Truncate(Hour) here.
-		return clock.Date(y, m, d, now.Hour(), 0, 0, 0, now.Location()).
-			Add(clock.Hour-clock.Nanosecond).
-			UnixNano() / 1000000, nil
-	case GregorianDays:
-		y, m, d := now.Date()
-		return clock.Date(y, m, d, 23, 59, 59, int(clock.Second-clock.Nanosecond), now.Location()).
-			UnixNano() / 1000000, nil
-	case GregorianWeeks:
-		return 0, errors.New("`Duration = GregorianWeeks` not yet supported; consider making a PR!`")
-	case GregorianMonths:
-		y, m, _ := now.Date()
-		return clock.Date(y, m, 1, 0, 0, 0, 0, now.Location()).
-			AddDate(0, 1, 0).Add(-clock.Nanosecond).
-			UnixNano() / 1000000, nil
-	case GregorianYears:
-		y, _, _ := now.Date()
-		return clock.Date(y, clock.January, 1, 0, 0, 0, 0, now.Location()).
-			AddDate(1, 0, 0).
-			Add(-clock.Nanosecond).
-			UnixNano() / 1000000, nil
+	return GregorianCalendar{}.Expiration(now, d)
+}
+
+// locations caches the time zones loaded by gregorianCalendar(), as time.LoadLocation() reads the
+// time zone database on every call
+var locations sync.Map
+
+// gregorianCalendar returns the calendar of the time zone, week start and month start day of the request
+func gregorianCalendar(r *RateLimitReq) (GregorianCalendar, error) {
+	c := GregorianCalendar{
+		WeekStart:     time.Weekday(r.WeekStart),
+		MonthStartDay: int(r.MonthStartDay),
+	}
+	if r.TimeZone == "" {
+		return c, nil
+	}
+	if loc, ok := locations.Load(r.TimeZone); ok {
+		c.Location = loc.(*time.Location)
+		return c, nil
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return c, fmt.Errorf("time zone '%s' is not a valid IANA time zone", r.TimeZone)
+	}
+	locations.Store(r.TimeZone, loc)
+	c.Location = loc
+	return c, nil
+}
+
+// gregorianDuration returns the entire duration of the gregorian interval of the request
+func gregorianDuration(now clock.Time, r *RateLimitReq) (int64, error) {
+	c, err := gregorianCalendar(r)
+	if err != nil {
+		return 0, err
+	}
+	return c.Duration(now, r.Duration)
+}
+
+// gregorianExpiration returns the last millisecond of the gregorian interval of the request
+func gregorianExpiration(now clock.Time, r *RateLimitReq) (int64, error) {
+	c, err := gregorianCalendar(r)
+	if err != nil {
+		return 0, err
 	}
-	return 0, errors.New("behavior DURATION_IS_GREGORIAN is set; but `Duration` is not a valid gregorian interval")
+	return c.Expiration(now, r.Duration)
 }
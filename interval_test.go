@@ -134,3 +134,128 @@ func TestGregorianExpirationInvalid(t *testing.T) {
 	assert.Equal(t, int64(0), expire)
 	assert.Equal(t, "behavior DURATION_IS_GREGORIAN is set; but `Duration` is not a valid gregorian interval", err.Error())
 }
+
+func TestGregorianExpirationWeek(t *testing.T) {
+	// Wednesday
+	now := clock.Date(2019, clock.November, 13, 10, 20, 30, 0, clock.UTC)
+	expire, err := gubernator.GregorianExpiration(now, gubernator.GregorianWeeks)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Date(2019, clock.November, 16, 23, 59, 59, 999000000, clock.UTC),
+		clock.Unix(0, expire*1000000).UTC())
+
+	cal := gubernator.GregorianCalendar{WeekStart: clock.Monday}
+	expire, err = cal.Expiration(now, gubernator.GregorianWeeks)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Date(2019, clock.November, 17, 23, 59, 59, 999000000, clock.UTC),
+		clock.Unix(0, expire*1000000).UTC())
+
+	// The week begins on the day it starts
+	cal = gubernator.GregorianCalendar{WeekStart: clock.Wednesday}
+	expire, err = cal.Expiration(now, gubernator.GregorianWeeks)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Date(2019, clock.November, 19, 23, 59, 59, 999000000, clock.UTC),
+		clock.Unix(0, expire*1000000).UTC())
+
+	duration, err := gubernator.GregorianDuration(now, gubernator.GregorianWeeks)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7*24*clock.Hour/clock.Millisecond), duration)
+
+	_, err = gubernator.GregorianCalendar{WeekStart: 7}.Expiration(now, gubernator.GregorianWeeks)
+	assert.EqualError(t, err, "week start '7' is not a valid day of the week")
+}
+
+func TestGregorianTimeZone(t *testing.T) {
+	newYork, err := clock.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	cal := gubernator.GregorianCalendar{Location: newYork}
+
+	// Still the 10th in New York
+	now := clock.Date(2019, clock.November, 11, 3, 0, 0, 0, clock.UTC)
+	expire, err := cal.Expiration(now, gubernator.GregorianDays)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Date(2019, clock.November, 10, 23, 59, 59, 999000000, newYork),
+		clock.Unix(0, expire*1000000).In(newYork))
+
+	// The day daylight saving time ends is 25 hours long
+	now = clock.Date(2019, clock.November, 3, 12, 0, 0, 0, newYork)
+	duration, err := cal.Duration(now, gubernator.GregorianDays)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25*clock.Hour/clock.Millisecond), duration)
+}
+
+func TestGregorianMonthStartDay(t *testing.T) {
+	cal := gubernator.GregorianCalendar{MonthStartDay: 15}
+
+	for _, tt := range []struct {
+		name  string
+		cal   gubernator.GregorianCalendar
+		now   clock.Time
+		begin clock.Time
+		end   clock.Time
+	}{
+		{
+			name:  "before the start day",
+			cal:   cal,
+			now:   clock.Date(2019, clock.November, 11, 0, 0, 0, 0, clock.UTC),
+			begin: clock.Date(2019, clock.October, 15, 0, 0, 0, 0, clock.UTC),
+			end:   clock.Date(2019, clock.November, 15, 0, 0, 0, 0, clock.UTC),
+		},
+		{
+			name:  "on the start day",
+			cal:   cal,
+			now:   clock.Date(2019, clock.November, 15, 0, 0, 0, 0, clock.UTC),
+			begin: clock.Date(2019, clock.November, 15, 0, 0, 0, 0, clock.UTC),
+			end:   clock.Date(2019, clock.December, 15, 0, 0, 0, 0, clock.UTC),
+		},
+		{
+			name:  "across the end of the year",
+			cal:   cal,
+			now:   clock.Date(2019, clock.December, 31, 0, 0, 0, 0, clock.UTC),
+			begin: clock.Date(2019, clock.December, 15, 0, 0, 0, 0, clock.UTC),
+			end:   clock.Date(2020, clock.January, 15, 0, 0, 0, 0, clock.UTC),
+		},
+		{
+			name:  "short months begin on their last day",
+			cal:   gubernator.GregorianCalendar{MonthStartDay: 31},
+			now:   clock.Date(2019, clock.February, 28, 12, 0, 0, 0, clock.UTC),
+			begin: clock.Date(2019, clock.February, 28, 0, 0, 0, 0, clock.UTC),
+			end:   clock.Date(2019, clock.March, 31, 0, 0, 0, 0, clock.UTC),
+		},
+		{
+			name:  "before the last day of a short month",
+			cal:   gubernator.GregorianCalendar{MonthStartDay: 31},
+			now:   clock.Date(2019, clock.February, 27, 0, 0, 0, 0, clock.UTC),
+			begin: clock.Date(2019, clock.January, 31, 0, 0, 0, 0, clock.UTC),
+			end:   clock.Date(2019, clock.February, 28, 0, 0, 0, 0, clock.UTC),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			begin, end, err := tt.cal.Bounds(tt.now, gubernator.GregorianMonths)
+			require.NoError(t, err)
+			assert.Equal(t, tt.begin, begin)
+			assert.Equal(t, tt.end, end)
+
+			duration, err := tt.cal.Duration(tt.now, gubernator.GregorianMonths)
+			require.NoError(t, err)
+			assert.Equal(t, tt.end.Sub(tt.begin).Milliseconds(), duration)
+		})
+	}
+}
+
+func TestGregorianDuration(t *testing.T) {
+	now := clock.Date(2019, clock.November, 11, 22, 2, 23, 0, clock.UTC)
+	for _, tt := range []struct {
+		d        int64
+		duration clock.Duration
+	}{
+		{d: gubernator.GregorianMinutes, duration: clock.Minute},
+		{d: gubernator.GregorianHours, duration: clock.Hour},
+		{d: gubernator.GregorianDays, duration: 24 * clock.Hour},
+		{d: gubernator.GregorianMonths, duration: 30 * 24 * clock.Hour},
+		{d: gubernator.GregorianYears, duration: 365 * 24 * clock.Hour},
+	} {
+		duration, err := gubernator.GregorianDuration(now, tt.d)
+		require.NoError(t, err)
+		assert.Equal(t, tt.duration.Milliseconds(), duration, tt.d)
+	}
+}
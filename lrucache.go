@@ -30,10 +30,20 @@ import (
 // LRUCache is an LRU cache that supports expiration and is not thread-safe
 // Be sure to use a mutex to prevent concurrent method calls.
 type LRUCache struct {
-	cache     map[string]*list.Element
-	ll        *list.List
-	cacheSize int
-	cacheLen  int64
+	cache      map[string]*list.Element
+	ll         *list.List
+	cacheSize  int
+	cacheLen   int64
+	maxBytes   int64
+	cacheBytes int64
+	// The element the next call to Sweep() starts from, or nil to start from the oldest item
+	sweepNext *list.Element
+}
+
+type lruEntry struct {
+	item *CacheItem
+	// The estimated size of the item when it was last added or accessed
+	bytes int64
 }
 
 // LRUCacheCollector provides prometheus metrics collector for LRUCache.
@@ -42,13 +52,18 @@ type LRUCacheCollector struct {
 	caches []Cache
 }
 
-var _ Cache = &LRUCache{}
+var _ MemoryBoundedCache = &LRUCache{}
+var _ SweepableCache = &LRUCache{}
 var _ prometheus.Collector = &LRUCacheCollector{}
 
 var metricCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
 	Name: "gubernator_cache_size",
 	Help: "The number of items in LRU Cache which holds the rate limits.",
 })
+var metricCacheBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "gubernator_cache_bytes",
+	Help: "The estimated memory used by the rate limits held in the cache in bytes.",
+})
 var metricCacheAccess = prometheus.NewCounterVec(prometheus.CounterOpts{
 	Name: "gubernator_cache_access_count",
 	Help: "Cache access counts.  Label \"type\" = hit|miss.",
@@ -69,6 +84,7 @@ func NewLRUCache(maxSize int) *LRUCache {
 	}
 }
 
+// Each returns the items in the cache, skipping those which have expired or been invalidated.
 // Each is not thread-safe. Each() maintains a goroutine that iterates.
 // Other go routines cannot safely access the Cache while iterating.
 // It would be safer if this were done using an iterator or delegate pattern
@@ -76,8 +92,13 @@ func NewLRUCache(maxSize int) *LRUCache {
 func (c *LRUCache) Each() chan *CacheItem {
 	out := make(chan *CacheItem)
 	go func() {
+		now := MillisecondNow()
 		for _, ele := range c.cache {
-			out <- ele.Value.(*CacheItem)
+			item := ele.Value.(*lruEntry).item
+			if item.isExpired(now) {
+				continue
+			}
+			out <- item
 		}
 		close(out)
 	}()
@@ -88,18 +109,41 @@ func (c *LRUCache) Each() chan *CacheItem {
 func (c *LRUCache) Add(item *CacheItem) bool {
 	// If the key already exist, set the new value
 	if ee, ok := c.cache[item.Key]; ok {
-		c.ll.MoveToFront(ee)
-		ee.Value = item
+		c.moveToFront(ee)
+		entry := ee.Value.(*lruEntry)
+		entry.item = item
+		c.resize(entry)
+		c.evict()
 		return true
 	}
 
-	ele := c.ll.PushFront(item)
-	c.cache[item.Key] = ele
+	entry := &lruEntry{item: item, bytes: estimateCacheItemBytes(item)}
+	c.cache[item.Key] = c.ll.PushFront(entry)
+	atomic.AddInt64(&c.cacheBytes, entry.bytes)
+	c.evict()
+	atomic.StoreInt64(&c.cacheLen, int64(c.ll.Len()))
+	return false
+}
+
+// evict removes the oldest items until the cache is within its limits. When limited by
+// memory, the most recently used item is kept even if it exceeds the limit on its own.
+func (c *LRUCache) evict() {
+	if c.maxBytes > 0 {
+		for c.ll.Len() > 1 && atomic.LoadInt64(&c.cacheBytes) > c.maxBytes {
+			c.removeOldest()
+		}
+		return
+	}
 	if c.cacheSize != 0 && c.ll.Len() > c.cacheSize {
 		c.removeOldest()
 	}
-	atomic.StoreInt64(&c.cacheLen, int64(c.ll.Len()))
-	return false
+}
+
+// resize updates the estimated size of an item, which may have grown since it was added
+func (c *LRUCache) resize(entry *lruEntry) {
+	bytes := estimateCacheItemBytes(entry.item)
+	atomic.AddInt64(&c.cacheBytes, bytes-entry.bytes)
+	entry.bytes = bytes
 }
 
 // MillisecondNow returns unix epoch in milliseconds
@@ -110,7 +154,7 @@ func MillisecondNow() int64 {
 // GetItem returns the item stored in the cache
 func (c *LRUCache) GetItem(key string) (item *CacheItem, ok bool) {
 	if ele, hit := c.cache[key]; hit {
-		entry := ele.Value.(*CacheItem)
+		entry := ele.Value.(*lruEntry).item
 
 		now := MillisecondNow()
 		// If the entry is invalidated
@@ -128,7 +172,9 @@ func (c *LRUCache) GetItem(key string) (item *CacheItem, ok bool) {
 		}
 
 		metricCacheAccess.WithLabelValues("hit").Add(1)
-		c.ll.MoveToFront(ele)
+		c.moveToFront(ele)
+		c.resize(ele.Value.(*lruEntry))
+		c.evict()
 		return entry, true
 	}
 
@@ -147,7 +193,7 @@ func (c *LRUCache) Remove(key string) {
 func (c *LRUCache) removeOldest() {
 	ele := c.ll.Back()
 	if ele != nil {
-		entry := ele.Value.(*CacheItem)
+		entry := ele.Value.(*lruEntry).item
 
 		if MillisecondNow() < entry.ExpireAt {
 			metricCacheUnexpiredEvictions.Add(1)
@@ -157,10 +203,23 @@ func (c *LRUCache) removeOldest() {
 	}
 }
 
+// moveToFront marks the element as the most recently used, without moving the sweep past
+// the items between the element and the front of the list
+func (c *LRUCache) moveToFront(e *list.Element) {
+	if e == c.sweepNext {
+		c.sweepNext = e.Prev()
+	}
+	c.ll.MoveToFront(e)
+}
+
 func (c *LRUCache) removeElement(e *list.Element) {
+	if e == c.sweepNext {
+		c.sweepNext = e.Prev()
+	}
 	c.ll.Remove(e)
-	kv := e.Value.(*CacheItem)
-	delete(c.cache, kv.Key)
+	entry := e.Value.(*lruEntry)
+	delete(c.cache, entry.item.Key)
+	atomic.AddInt64(&c.cacheBytes, -entry.bytes)
 	atomic.StoreInt64(&c.cacheLen, int64(c.ll.Len()))
 }
 
@@ -172,8 +231,7 @@ func (c *LRUCache) Size() int64 {
 // UpdateExpiration updates the expiration time for the key
 func (c *LRUCache) UpdateExpiration(key string, expireAt int64) bool {
 	if ele, hit := c.cache[key]; hit {
-		entry := ele.Value.(*CacheItem)
-		entry.ExpireAt = expireAt
+		ele.Value.(*lruEntry).item.ExpireAt = expireAt
 		return true
 	}
 	return false
@@ -183,9 +241,46 @@ func (c *LRUCache) Close() error {
 	c.cache = nil
 	c.ll = nil
 	c.cacheLen = 0
+	c.cacheBytes = 0
+	c.sweepNext = nil
 	return nil
 }
 
+// Sweep removes expired and invalidated items, starting from the least recently used item.
+// Items are examined from where the previous sweep stopped, and once the most recently used
+// item is reached the next sweep starts over from the least recently used item.
+func (c *LRUCache) Sweep(max int) int {
+	now := MillisecondNow()
+	ele := c.sweepNext
+	if ele == nil {
+		ele = c.ll.Back()
+	}
+
+	var removed int
+	for i := 0; i < max && ele != nil; i++ {
+		next := ele.Prev()
+		item := ele.Value.(*lruEntry).item
+		if item.isExpired(now) {
+			c.removeElement(ele)
+			removed++
+		}
+		ele = next
+	}
+	c.sweepNext = ele
+	return removed
+}
+
+// SetMaxBytes limits the estimated memory used by the items in the cache, after which the
+// maximum number of items is ignored.
+func (c *LRUCache) SetMaxBytes(maxBytes int64) {
+	c.maxBytes = maxBytes
+}
+
+// Bytes returns the estimated memory used by the items in the cache.
+func (c *LRUCache) Bytes() int64 {
+	return atomic.LoadInt64(&c.cacheBytes)
+}
+
 func NewLRUCacheCollector() *LRUCacheCollector {
 	return &LRUCacheCollector{
 		caches: []Cache{},
@@ -202,6 +297,7 @@ func (collector *LRUCacheCollector) Describe(ch chan<- *prometheus.Desc) {
 	metricCacheSize.Describe(ch)
 	metricCacheAccess.Describe(ch)
 	metricCacheUnexpiredEvictions.Describe(ch)
+	metricCacheBytes.Describe(ch)
 }
 
 // Collect fetches metric counts and gauges from the cache
@@ -210,6 +306,8 @@ func (collector *LRUCacheCollector) Collect(ch chan<- prometheus.Metric) {
 	metricCacheSize.Collect(ch)
 	metricCacheAccess.Collect(ch)
 	metricCacheUnexpiredEvictions.Collect(ch)
+	metricCacheBytes.Set(collector.getBytes())
+	metricCacheBytes.Collect(ch)
 }
 
 func (collector *LRUCacheCollector) getSize() float64 {
@@ -221,3 +319,15 @@ func (collector *LRUCacheCollector) getSize() float64 {
 
 	return size
 }
+
+func (collector *LRUCacheCollector) getBytes() float64 {
+	var bytes float64
+
+	for _, cache := range collector.caches {
+		if c, ok := cache.(MemoryBoundedCache); ok {
+			bytes += float64(c.Bytes())
+		}
+	}
+
+	return bytes
+}
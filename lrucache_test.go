@@ -426,6 +426,99 @@ func TestLRUCache(t *testing.T) {
 		assert.Contains(t, m.Desc().String(), "gubernator_unexpired_evictions_count")
 		assert.Equal(t, 1, int(*met.Counter.Value))
 	})
+
+	t.Run("Evicts by estimated bytes when SetMaxBytes is called", func(t *testing.T) {
+		cache := gubernator.NewLRUCache(0)
+		cache.SetMaxBytes(10_000)
+
+		for i := 0; i < 1000; i++ {
+			cache.Add(&gubernator.CacheItem{
+				Key:      fmt.Sprintf("bucket-%d", i),
+				Value:    &gubernator.TokenBucketItem{Limit: 100},
+				ExpireAt: expireAt,
+			})
+			require.LessOrEqual(t, cache.Bytes(), int64(10_000))
+		}
+		size := cache.Size()
+		assert.Greater(t, size, int64(10))
+		assert.Less(t, size, int64(1000))
+
+		// An item which grows is accounted for when it is accessed
+		item, ok := cache.GetItem("bucket-999")
+		require.True(t, ok)
+		item.Value = &gubernator.ConcurrencyItem{Leases: make([]gubernator.ConcurrencyLease, 0, 100)}
+		_, ok = cache.GetItem("bucket-999")
+		require.True(t, ok)
+		assert.LessOrEqual(t, cache.Bytes(), int64(10_000))
+		assert.Less(t, cache.Size(), size)
+
+		// The most recently used item is never evicted to make room for itself
+		_, ok = cache.GetItem("bucket-999")
+		assert.True(t, ok)
+
+		var keys []string
+		for item := range cache.Each() {
+			keys = append(keys, item.Key)
+		}
+		for _, key := range keys {
+			cache.Remove(key)
+		}
+		assert.Zero(t, cache.Bytes())
+	})
+
+	t.Run("Each skips expired and invalidated items", func(t *testing.T) {
+		cache := gubernator.NewLRUCache(0)
+		past := clock.Now().Add(-time.Minute).UnixMilli()
+		cache.Add(&gubernator.CacheItem{Key: "live", ExpireAt: expireAt})
+		cache.Add(&gubernator.CacheItem{Key: "expired", ExpireAt: past})
+		cache.Add(&gubernator.CacheItem{Key: "invalidated", ExpireAt: expireAt, InvalidAt: past})
+
+		var keys []string
+		for item := range cache.Each() {
+			keys = append(keys, item.Key)
+		}
+		assert.Equal(t, []string{"live"}, keys)
+	})
+
+	t.Run("Sweep removes expired items incrementally", func(t *testing.T) {
+		cache := gubernator.NewLRUCache(0)
+		now := clock.Now()
+		for i := 0; i < 10; i++ {
+			item := &gubernator.CacheItem{Key: strconv.Itoa(i), ExpireAt: expireAt}
+			if i%2 == 0 {
+				item.ExpireAt = now.Add(-time.Minute).UnixMilli()
+			}
+			if i == 9 {
+				item.InvalidAt = now.Add(-time.Minute).UnixMilli()
+			}
+			cache.Add(item)
+		}
+
+		// Each sweep resumes from where the previous sweep stopped
+		assert.Equal(t, 3, cache.Sweep(5))
+		assert.Equal(t, int64(7), cache.Size())
+		// Moving the next item to be swept does not skip the remaining items
+		_, ok := cache.GetItem("5")
+		require.True(t, ok)
+		assert.Equal(t, 3, cache.Sweep(100))
+		assert.Equal(t, int64(4), cache.Size())
+		assert.Zero(t, cache.Sweep(100))
+
+		for _, key := range []string{"1", "3", "5", "7"} {
+			_, ok := cache.GetItem(key)
+			assert.True(t, ok, key)
+		}
+	})
+
+	t.Run("Reports gubernator_cache_bytes", func(t *testing.T) {
+		cache := gubernator.NewLRUCache(10)
+		collector := gubernator.NewLRUCacheCollector()
+		collector.AddCache(cache)
+
+		cache.Add(&gubernator.CacheItem{Key: "foo", Value: &gubernator.LeakyBucketItem{}, ExpireAt: expireAt})
+		assert.NotZero(t, cache.Bytes())
+		assert.Equal(t, float64(cache.Bytes()), collectorValue(t, collector, "gubernator_cache_bytes"))
+	})
 }
 
 func BenchmarkLRUCache(b *testing.B) {
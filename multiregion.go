@@ -0,0 +1,198 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+// multiRegionManager manages the async hit queue for rate limits marked as
+// MULTI_REGION and periodically sends the aggregated hits to the owning peer
+// of each rate limit in every other region.
+type multiRegionManager struct {
+	hitsQueue                     chan *RateLimitReq
+	wg                            syncutil.WaitGroup
+	conf                          BehaviorConfig
+	log                           FieldLogger
+	instance                      *V1Instance
+	metricMultiRegionSendDuration prometheus.Summary
+	metricMultiRegionQueueLength  prometheus.Gauge
+	metricMultiRegionSendErrors   *prometheus.CounterVec
+}
+
+func newMultiRegionManager(conf BehaviorConfig, instance *V1Instance) *multiRegionManager {
+	mm := multiRegionManager{
+		log:       instance.log,
+		hitsQueue: make(chan *RateLimitReq, conf.MultiRegionBatchLimit),
+		instance:  instance,
+		conf:      conf,
+		metricMultiRegionSendDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:       "gubernator_multi_region_send_duration",
+			Help:       "The duration of MULTI_REGION async sends in seconds.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+		}),
+		metricMultiRegionQueueLength: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gubernator_multi_region_queue_length",
+			Help: "The count of rate limits with hits queued up to be sent to other regions.",
+		}),
+		metricMultiRegionSendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gubernator_multi_region_send_errors",
+			Help: "The count of errors while sending MULTI_REGION hits to peers in other regions.",
+		}, []string{"region"}),
+	}
+	mm.runAsyncHits()
+	return &mm
+}
+
+// QueueHit queues the hits of a MULTI_REGION rate limit which were applied
+// locally, so they can be applied by the owning peers in the other regions.
+func (mm *multiRegionManager) QueueHit(r *RateLimitReq) {
+	// The hits are aggregated, so we must not modify the request the caller gave us.
+	cpy := proto.Clone(r).(*RateLimitReq)
+	mm.hitsQueue <- cpy
+}
+
+// runAsyncHits collects async hit requests in a forever loop,
+// aggregates them in one request per rate limit, and sends them
+// to the owning peers in each of the other regions.
+// The hits are sent both when the batch limit is hit
+// and in a periodic frequency determined by MultiRegionSyncWait.
+func (mm *multiRegionManager) runAsyncHits() {
+	var interval = NewInterval(mm.conf.MultiRegionSyncWait)
+	hits := make(map[string]*RateLimitReq)
+
+	mm.wg.Until(func(done chan struct{}) bool {
+		select {
+		case r := <-mm.hitsQueue:
+			// Aggregate the hits into a single request
+			key := r.HashKey()
+			_, ok := hits[key]
+			if ok {
+				// If any of our hits includes a request to RESET_REMAINING
+				// ensure the owning peers get this behavior
+				if HasBehavior(r.Behavior, Behavior_RESET_REMAINING) {
+					SetBehavior(&hits[key].Behavior, Behavior_RESET_REMAINING, true)
+				}
+				hits[key].Hits += r.Hits
+			} else {
+				hits[key] = r
+			}
+
+			// Send the hits if we reached our batch limit
+			if len(hits) == mm.conf.MultiRegionBatchLimit {
+				mm.sendHits(hits)
+				hits = make(map[string]*RateLimitReq)
+				return true
+			}
+
+			// If this is our first queued hit since last send
+			// queue the next interval
+			if len(hits) == 1 {
+				interval.Next()
+			}
+
+		case <-interval.C:
+			if len(hits) != 0 {
+				mm.sendHits(hits)
+				hits = make(map[string]*RateLimitReq)
+			}
+		case <-done:
+			interval.Stop()
+			return false
+		}
+		return true
+	})
+}
+
+// sendHits takes the hits collected by runAsyncHits and sends them to
+// the owning peer in each of the other regions
+func (mm *multiRegionManager) sendHits(hits map[string]*RateLimitReq) {
+	type pair struct {
+		client *PeerClient
+		req    GetPeerRateLimitsReq
+	}
+	defer prometheus.NewTimer(mm.metricMultiRegionSendDuration).ObserveDuration()
+	mm.metricMultiRegionQueueLength.Set(float64(len(hits)))
+	peerRequests := make(map[string]*pair)
+
+	// Assign each request to the owning peer in every region
+	for _, r := range hits {
+		// The receiving peers must apply the hits as if they own them,
+		// and must not forward them on to other regions again.
+		SetBehavior(&r.Behavior, Behavior_MULTI_REGION, false)
+
+		peers, err := mm.instance.GetRegionPeers(r.HashKey())
+		if err != nil {
+			mm.log.WithError(err).Errorf("while getting region peers for hash key '%s'", r.HashKey())
+			continue
+		}
+		for _, peer := range peers {
+			p, ok := peerRequests[peer.Info().GRPCAddress]
+			if ok {
+				p.req.Requests = append(p.req.Requests, r)
+			} else {
+				peerRequests[peer.Info().GRPCAddress] = &pair{
+					client: peer,
+					req:    GetPeerRateLimitsReq{Requests: []*RateLimitReq{r}},
+				}
+			}
+		}
+	}
+
+	fan := syncutil.NewFanOut(mm.conf.GlobalPeerRequestsConcurrency)
+	// Send the rate limit requests to their respective owning peers.
+	for _, p := range peerRequests {
+		fan.Run(func(in interface{}) error {
+			p := in.(*pair)
+			ctx, cancel := context.WithTimeout(context.Background(), mm.conf.MultiRegionTimeout)
+			resp, err := p.client.GetPeerRateLimits(ctx, &p.req)
+			cancel()
+
+			if err != nil {
+				mm.metricMultiRegionSendErrors.WithLabelValues(p.client.Info().DataCenter).Inc()
+				mm.log.WithError(err).
+					Errorf("while sending multi region hits to '%s' in region '%s'",
+						p.client.Info().GRPCAddress, p.client.Info().DataCenter)
+				return nil
+			}
+
+			for i, rl := range resp.RateLimits {
+				if rl.Error != "" {
+					mm.metricMultiRegionSendErrors.WithLabelValues(p.client.Info().DataCenter).Inc()
+					mm.log.Errorf("while applying multi region hits for '%s' on '%s': %s",
+						p.req.Requests[i].HashKey(), p.client.Info().GRPCAddress, rl.Error)
+				}
+			}
+			return nil
+		}, p)
+	}
+	fan.Wait()
+}
+
+// Close stops all goroutines and shuts down all the peers in other regions.
+func (mm *multiRegionManager) Close() {
+	mm.wg.Stop()
+	for _, picker := range mm.instance.GetRegionPickers() {
+		for _, peer := range picker.Peers() {
+			_ = peer.Shutdown(context.Background())
+		}
+	}
+}
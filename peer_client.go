@@ -48,6 +48,13 @@ type PeerPicker interface {
 	Add(*PeerClient)
 }
 
+// ReplicaPicker is implemented by a PeerPicker which can choose the successors of the peer a key is
+// assigned to. Required when BehaviorConfig.ReplicationFactor is set.
+type ReplicaPicker interface {
+	// GetN returns up to n distinct peers, starting with the peer the key is assigned to
+	GetN(string, int) ([]*PeerClient, error)
+}
+
 type PeerClient struct {
 	client      PeersV1Client
 	conn        *grpc.ClientConn
@@ -63,12 +70,16 @@ type PeerClient struct {
 type response struct {
 	rl  *RateLimitResp
 	err error
+	// Copies of the cached item before and after the request was applied, only set
+	// when the request was sent by WorkerPool.ReserveRateLimit()
+	before, after *CacheItem
 }
 
 type request struct {
 	request *RateLimitReq
 	resp    chan *response
 	ctx     context.Context
+	reserve bool
 }
 
 type PeerConfig struct {
@@ -202,6 +213,153 @@ func (c *PeerClient) UpdatePeerGlobals(ctx context.Context, r *UpdatePeerGlobals
 	return resp, err
 }
 
+// InspectPeerRateLimits requests the state of a list of rate limits owned by a peer
+func (c *PeerClient) InspectPeerRateLimits(ctx context.Context, r *InspectRateLimitsReq) (resp *InspectRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.InspectPeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.InspectPeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+
+	// Unlikely, but this avoids a panic if something wonky happens
+	if len(resp.States) != len(r.Keys) {
+		err = errors.New("number of states in peer response does not match request")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// DeletePeerRateLimits deletes a list of rate limits owned by a peer
+func (c *PeerClient) DeletePeerRateLimits(ctx context.Context, r *DeleteRateLimitsReq) (resp *DeleteRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.DeletePeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.DeletePeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+
+	// Unlikely, but this avoids a panic if something wonky happens
+	if len(resp.States) != len(r.Keys) {
+		err = errors.New("number of states in peer response does not match request")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// SetPeerRateLimits overwrites the state of a list of rate limits owned by a peer
+func (c *PeerClient) SetPeerRateLimits(ctx context.Context, r *SetRateLimitsReq) (resp *SetRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.SetPeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.SetPeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+
+	// Unlikely, but this avoids a panic if something wonky happens
+	if len(resp.States) != len(r.States) {
+		err = errors.New("number of states in peer response does not match request")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// ListPeerRateLimits requests a page of the rate limits held by a peer
+func (c *PeerClient) ListPeerRateLimits(ctx context.Context, r *ListRateLimitsReq) (resp *ListRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.ListPeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.ListPeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// TransferPeerRateLimits hands off rate limits to the peer which now owns them
+func (c *PeerClient) TransferPeerRateLimits(ctx context.Context, r *TransferPeerRateLimitsReq) (resp *TransferPeerRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.TransferPeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.TransferPeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// ReplicatePeerRateLimits sends the state of rate limits we own to a successor peer
+func (c *PeerClient) ReplicatePeerRateLimits(ctx context.Context, r *ReplicatePeerRateLimitsReq) (resp *ReplicatePeerRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.ReplicatePeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.ReplicatePeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// ReservePeerRateLimits applies the hits of an atomic request to rate limits owned by the peer,
+// holding them until CommitPeerRateLimits commits or aborts the reservation
+func (c *PeerClient) ReservePeerRateLimits(ctx context.Context, r *ReservePeerRateLimitsReq) (resp *ReservePeerRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.ReservePeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.ReservePeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
+// CommitPeerRateLimits commits or aborts a reservation made by ReservePeerRateLimits
+func (c *PeerClient) CommitPeerRateLimits(ctx context.Context, r *CommitPeerRateLimitsReq) (resp *CommitPeerRateLimitsResp, err error) {
+	// See NOTE above about RLock and wg.Add(1)
+	c.wgMutex.Lock()
+	c.wg.Add(1)
+	c.wgMutex.Unlock()
+	defer c.wg.Done()
+
+	resp, err = c.client.CommitPeerRateLimits(ctx, r)
+	if err != nil {
+		err = errors.Wrap(err, "Error in client.CommitPeerRateLimits")
+		return nil, c.setLastErr(err)
+	}
+	return resp, nil
+}
+
 func (c *PeerClient) setLastErr(err error) error {
 	// If we get a nil error return without caching it
 	if err == nil {
@@ -34,8 +34,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	PeersV1_GetPeerRateLimits_FullMethodName = "/pb.gubernator.PeersV1/GetPeerRateLimits"
-	PeersV1_UpdatePeerGlobals_FullMethodName = "/pb.gubernator.PeersV1/UpdatePeerGlobals"
+	PeersV1_GetPeerRateLimits_FullMethodName       = "/pb.gubernator.PeersV1/GetPeerRateLimits"
+	PeersV1_UpdatePeerGlobals_FullMethodName       = "/pb.gubernator.PeersV1/UpdatePeerGlobals"
+	PeersV1_InspectPeerRateLimits_FullMethodName   = "/pb.gubernator.PeersV1/InspectPeerRateLimits"
+	PeersV1_DeletePeerRateLimits_FullMethodName    = "/pb.gubernator.PeersV1/DeletePeerRateLimits"
+	PeersV1_SetPeerRateLimits_FullMethodName       = "/pb.gubernator.PeersV1/SetPeerRateLimits"
+	PeersV1_ListPeerRateLimits_FullMethodName      = "/pb.gubernator.PeersV1/ListPeerRateLimits"
+	PeersV1_TransferPeerRateLimits_FullMethodName  = "/pb.gubernator.PeersV1/TransferPeerRateLimits"
+	PeersV1_ReplicatePeerRateLimits_FullMethodName = "/pb.gubernator.PeersV1/ReplicatePeerRateLimits"
+	PeersV1_ReservePeerRateLimits_FullMethodName   = "/pb.gubernator.PeersV1/ReservePeerRateLimits"
+	PeersV1_CommitPeerRateLimits_FullMethodName    = "/pb.gubernator.PeersV1/CommitPeerRateLimits"
 )
 
 // PeersV1Client is the client API for PeersV1 service.
@@ -46,6 +54,24 @@ type PeersV1Client interface {
 	GetPeerRateLimits(ctx context.Context, in *GetPeerRateLimitsReq, opts ...grpc.CallOption) (*GetPeerRateLimitsResp, error)
 	// Used by owner peers to send global rate limit updates to non-owner peers
 	UpdatePeerGlobals(ctx context.Context, in *UpdatePeerGlobalsReq, opts ...grpc.CallOption) (*UpdatePeerGlobalsResp, error)
+	// Used by peers to relay admin requests to the owner peer. The peer that receives these
+	// requests MUST be authoritative for each of the rate limits provided.
+	InspectPeerRateLimits(ctx context.Context, in *InspectRateLimitsReq, opts ...grpc.CallOption) (*InspectRateLimitsResp, error)
+	DeletePeerRateLimits(ctx context.Context, in *DeleteRateLimitsReq, opts ...grpc.CallOption) (*DeleteRateLimitsResp, error)
+	SetPeerRateLimits(ctx context.Context, in *SetRateLimitsReq, opts ...grpc.CallOption) (*SetRateLimitsResp, error)
+	// Used by peers to list the rate limits held by each peer in the cluster
+	ListPeerRateLimits(ctx context.Context, in *ListRateLimitsReq, opts ...grpc.CallOption) (*ListRateLimitsResp, error)
+	// Used by peers to hand off the rate limits they no longer own to the new owner after the
+	// peer ring changed, such that the new owner does not start again from a full bucket
+	TransferPeerRateLimits(ctx context.Context, in *TransferPeerRateLimitsReq, opts ...grpc.CallOption) (*TransferPeerRateLimitsResp, error)
+	// Used by owner peers to replicate the state of their rate limits to the successor peers on the
+	// ring, such that a successor can take over with near current counts if the owner fails
+	ReplicatePeerRateLimits(ctx context.Context, in *ReplicatePeerRateLimitsReq, opts ...grpc.CallOption) (*ReplicatePeerRateLimitsResp, error)
+	// Used by the peer which received an atomic GetRateLimitsReq to apply the hits of the rate limits
+	// owned by another peer, which are held as a reservation until CommitPeerRateLimits commits or
+	// aborts them. The peer that receives these requests MUST be authoritative for each rate limit.
+	ReservePeerRateLimits(ctx context.Context, in *ReservePeerRateLimitsReq, opts ...grpc.CallOption) (*ReservePeerRateLimitsResp, error)
+	CommitPeerRateLimits(ctx context.Context, in *CommitPeerRateLimitsReq, opts ...grpc.CallOption) (*CommitPeerRateLimitsResp, error)
 }
 
 type peersV1Client struct {
@@ -74,6 +100,78 @@ func (c *peersV1Client) UpdatePeerGlobals(ctx context.Context, in *UpdatePeerGlo
 	return out, nil
 }
 
+func (c *peersV1Client) InspectPeerRateLimits(ctx context.Context, in *InspectRateLimitsReq, opts ...grpc.CallOption) (*InspectRateLimitsResp, error) {
+	out := new(InspectRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_InspectPeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) DeletePeerRateLimits(ctx context.Context, in *DeleteRateLimitsReq, opts ...grpc.CallOption) (*DeleteRateLimitsResp, error) {
+	out := new(DeleteRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_DeletePeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) SetPeerRateLimits(ctx context.Context, in *SetRateLimitsReq, opts ...grpc.CallOption) (*SetRateLimitsResp, error) {
+	out := new(SetRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_SetPeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) ListPeerRateLimits(ctx context.Context, in *ListRateLimitsReq, opts ...grpc.CallOption) (*ListRateLimitsResp, error) {
+	out := new(ListRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_ListPeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) TransferPeerRateLimits(ctx context.Context, in *TransferPeerRateLimitsReq, opts ...grpc.CallOption) (*TransferPeerRateLimitsResp, error) {
+	out := new(TransferPeerRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_TransferPeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) ReplicatePeerRateLimits(ctx context.Context, in *ReplicatePeerRateLimitsReq, opts ...grpc.CallOption) (*ReplicatePeerRateLimitsResp, error) {
+	out := new(ReplicatePeerRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_ReplicatePeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) ReservePeerRateLimits(ctx context.Context, in *ReservePeerRateLimitsReq, opts ...grpc.CallOption) (*ReservePeerRateLimitsResp, error) {
+	out := new(ReservePeerRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_ReservePeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peersV1Client) CommitPeerRateLimits(ctx context.Context, in *CommitPeerRateLimitsReq, opts ...grpc.CallOption) (*CommitPeerRateLimitsResp, error) {
+	out := new(CommitPeerRateLimitsResp)
+	err := c.cc.Invoke(ctx, PeersV1_CommitPeerRateLimits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PeersV1Server is the server API for PeersV1 service.
 // All implementations should embed UnimplementedPeersV1Server
 // for forward compatibility
@@ -82,6 +180,24 @@ type PeersV1Server interface {
 	GetPeerRateLimits(context.Context, *GetPeerRateLimitsReq) (*GetPeerRateLimitsResp, error)
 	// Used by owner peers to send global rate limit updates to non-owner peers
 	UpdatePeerGlobals(context.Context, *UpdatePeerGlobalsReq) (*UpdatePeerGlobalsResp, error)
+	// Used by peers to relay admin requests to the owner peer. The peer that receives these
+	// requests MUST be authoritative for each of the rate limits provided.
+	InspectPeerRateLimits(context.Context, *InspectRateLimitsReq) (*InspectRateLimitsResp, error)
+	DeletePeerRateLimits(context.Context, *DeleteRateLimitsReq) (*DeleteRateLimitsResp, error)
+	SetPeerRateLimits(context.Context, *SetRateLimitsReq) (*SetRateLimitsResp, error)
+	// Used by peers to list the rate limits held by each peer in the cluster
+	ListPeerRateLimits(context.Context, *ListRateLimitsReq) (*ListRateLimitsResp, error)
+	// Used by peers to hand off the rate limits they no longer own to the new owner after the
+	// peer ring changed, such that the new owner does not start again from a full bucket
+	TransferPeerRateLimits(context.Context, *TransferPeerRateLimitsReq) (*TransferPeerRateLimitsResp, error)
+	// Used by owner peers to replicate the state of their rate limits to the successor peers on the
+	// ring, such that a successor can take over with near current counts if the owner fails
+	ReplicatePeerRateLimits(context.Context, *ReplicatePeerRateLimitsReq) (*ReplicatePeerRateLimitsResp, error)
+	// Used by the peer which received an atomic GetRateLimitsReq to apply the hits of the rate limits
+	// owned by another peer, which are held as a reservation until CommitPeerRateLimits commits or
+	// aborts them. The peer that receives these requests MUST be authoritative for each rate limit.
+	ReservePeerRateLimits(context.Context, *ReservePeerRateLimitsReq) (*ReservePeerRateLimitsResp, error)
+	CommitPeerRateLimits(context.Context, *CommitPeerRateLimitsReq) (*CommitPeerRateLimitsResp, error)
 }
 
 // UnimplementedPeersV1Server should be embedded to have forward compatible implementations.
@@ -94,6 +210,30 @@ func (UnimplementedPeersV1Server) GetPeerRateLimits(context.Context, *GetPeerRat
 func (UnimplementedPeersV1Server) UpdatePeerGlobals(context.Context, *UpdatePeerGlobalsReq) (*UpdatePeerGlobalsResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdatePeerGlobals not implemented")
 }
+func (UnimplementedPeersV1Server) InspectPeerRateLimits(context.Context, *InspectRateLimitsReq) (*InspectRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectPeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) DeletePeerRateLimits(context.Context, *DeleteRateLimitsReq) (*DeleteRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) SetPeerRateLimits(context.Context, *SetRateLimitsReq) (*SetRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) ListPeerRateLimits(context.Context, *ListRateLimitsReq) (*ListRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) TransferPeerRateLimits(context.Context, *TransferPeerRateLimitsReq) (*TransferPeerRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferPeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) ReplicatePeerRateLimits(context.Context, *ReplicatePeerRateLimitsReq) (*ReplicatePeerRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicatePeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) ReservePeerRateLimits(context.Context, *ReservePeerRateLimitsReq) (*ReservePeerRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReservePeerRateLimits not implemented")
+}
+func (UnimplementedPeersV1Server) CommitPeerRateLimits(context.Context, *CommitPeerRateLimitsReq) (*CommitPeerRateLimitsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitPeerRateLimits not implemented")
+}
 
 // UnsafePeersV1Server may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to PeersV1Server will
@@ -142,6 +282,150 @@ func _PeersV1_UpdatePeerGlobals_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PeersV1_InspectPeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).InspectPeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_InspectPeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).InspectPeerRateLimits(ctx, req.(*InspectRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_DeletePeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).DeletePeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_DeletePeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).DeletePeerRateLimits(ctx, req.(*DeleteRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_SetPeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).SetPeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_SetPeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).SetPeerRateLimits(ctx, req.(*SetRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_ListPeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).ListPeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_ListPeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).ListPeerRateLimits(ctx, req.(*ListRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_TransferPeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferPeerRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).TransferPeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_TransferPeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).TransferPeerRateLimits(ctx, req.(*TransferPeerRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_ReplicatePeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicatePeerRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).ReplicatePeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_ReplicatePeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).ReplicatePeerRateLimits(ctx, req.(*ReplicatePeerRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_ReservePeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservePeerRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).ReservePeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_ReservePeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).ReservePeerRateLimits(ctx, req.(*ReservePeerRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeersV1_CommitPeerRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitPeerRateLimitsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeersV1Server).CommitPeerRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeersV1_CommitPeerRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeersV1Server).CommitPeerRateLimits(ctx, req.(*CommitPeerRateLimitsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PeersV1_ServiceDesc is the grpc.ServiceDesc for PeersV1 service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,6 +441,38 @@ var PeersV1_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdatePeerGlobals",
 			Handler:    _PeersV1_UpdatePeerGlobals_Handler,
 		},
+		{
+			MethodName: "InspectPeerRateLimits",
+			Handler:    _PeersV1_InspectPeerRateLimits_Handler,
+		},
+		{
+			MethodName: "DeletePeerRateLimits",
+			Handler:    _PeersV1_DeletePeerRateLimits_Handler,
+		},
+		{
+			MethodName: "SetPeerRateLimits",
+			Handler:    _PeersV1_SetPeerRateLimits_Handler,
+		},
+		{
+			MethodName: "ListPeerRateLimits",
+			Handler:    _PeersV1_ListPeerRateLimits_Handler,
+		},
+		{
+			MethodName: "TransferPeerRateLimits",
+			Handler:    _PeersV1_TransferPeerRateLimits_Handler,
+		},
+		{
+			MethodName: "ReplicatePeerRateLimits",
+			Handler:    _PeersV1_ReplicatePeerRateLimits_Handler,
+		},
+		{
+			MethodName: "ReservePeerRateLimits",
+			Handler:    _PeersV1_ReservePeerRateLimits_Handler,
+		},
+		{
+			MethodName: "CommitPeerRateLimits",
+			Handler:    _PeersV1_CommitPeerRateLimits_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "peers.proto",
@@ -0,0 +1,387 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a named rate limit definition held by the server. Requests which
+// match the policy name and do not provide a limit or duration have the
+// rest of the rate limit filled in from the policy.
+type Policy struct {
+	// The name of the policy, matched against `RateLimitReq.Name`
+	Name      string
+	Algorithm Algorithm
+	Behavior  Behavior
+	Limit     int64
+	Duration  int64
+	Burst     int64
+	// The calendar of gregorian durations, see `RateLimitReq.TimeZone`, `WeekStart` and `MonthStartDay`
+	TimeZone      string
+	WeekStart     time.Weekday
+	MonthStartDay int32
+	// Overrides for specific `RateLimitReq.UniqueKey` values
+	Overrides map[string]PolicyOverride
+}
+
+// PolicyOverride replaces the limit, duration, burst, time zone, week start or
+// month start day of a Policy for a single unique key. Fields left at zero keep
+// the value from the Policy.
+type PolicyOverride struct {
+	Limit    int64
+	Duration int64
+	Burst    int64
+	TimeZone string
+	// A pointer as Sunday is the zero time.Weekday
+	WeekStart     *time.Weekday
+	MonthStartDay int32
+}
+
+// policyFile is the on disk representation of a list of policies
+//
+//	policies:
+//	  - name: requests_per_sec
+//	    algorithm: TOKEN_BUCKET
+//	    behavior: [GLOBAL]
+//	    limit: 100
+//	    duration: 1000
+//	    overrides:
+//	      - unique_key: account:1234
+//	        limit: 1000
+//	  - name: requests_per_billing_month
+//	    behavior: [DURATION_IS_GREGORIAN]
+//	    limit: 100000
+//	    duration: 4
+//	    time_zone: America/New_York
+//	    month_start_day: 15
+type policyFile struct {
+	Policies []struct {
+		Name          string   `yaml:"name"`
+		Algorithm     string   `yaml:"algorithm"`
+		Behavior      []string `yaml:"behavior"`
+		Limit         int64    `yaml:"limit"`
+		Duration      int64    `yaml:"duration"`
+		Burst         int64    `yaml:"burst"`
+		TimeZone      string   `yaml:"time_zone"`
+		WeekStart     string   `yaml:"week_start"`
+		MonthStartDay int32    `yaml:"month_start_day"`
+		Overrides     []struct {
+			UniqueKey     string `yaml:"unique_key"`
+			Limit         int64  `yaml:"limit"`
+			Duration      int64  `yaml:"duration"`
+			Burst         int64  `yaml:"burst"`
+			TimeZone      string `yaml:"time_zone"`
+			WeekStart     string `yaml:"week_start"`
+			MonthStartDay int32  `yaml:"month_start_day"`
+		} `yaml:"overrides"`
+	} `yaml:"policies"`
+}
+
+// ReadPolicies parses a YAML list of policies from the provided reader
+func ReadPolicies(r io.Reader) ([]Policy, error) {
+	var file policyFile
+	if err := yaml.NewDecoder(r).Decode(&file); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "while decoding policies")
+	}
+
+	seen := make(map[string]struct{}, len(file.Policies))
+	policies := make([]Policy, 0, len(file.Policies))
+	for i, p := range file.Policies {
+		if p.Name == "" {
+			return nil, errors.Errorf("policy at index '%d' is missing a 'name'", i)
+		}
+		if _, ok := seen[p.Name]; ok {
+			return nil, errors.Errorf("policy '%s' is defined more than once", p.Name)
+		}
+		seen[p.Name] = struct{}{}
+
+		policy := Policy{
+			Name:          p.Name,
+			Limit:         p.Limit,
+			Duration:      p.Duration,
+			Burst:         p.Burst,
+			TimeZone:      p.TimeZone,
+			MonthStartDay: p.MonthStartDay,
+			Overrides:     make(map[string]PolicyOverride, len(p.Overrides)),
+		}
+
+		if err := validateCalendar(p.TimeZone, p.MonthStartDay); err != nil {
+			return nil, errors.Wrapf(err, "policy '%s' has an invalid calendar", p.Name)
+		}
+		if p.WeekStart != "" {
+			d, ok := parseWeekday(p.WeekStart)
+			if !ok {
+				return nil, errors.Errorf("policy '%s' has invalid week_start '%s'", p.Name, p.WeekStart)
+			}
+			policy.WeekStart = d
+		}
+
+		if p.Algorithm != "" {
+			a, ok := Algorithm_value[p.Algorithm]
+			if !ok {
+				return nil, errors.Errorf("policy '%s' has invalid algorithm '%s'", p.Name, p.Algorithm)
+			}
+			policy.Algorithm = Algorithm(a)
+		}
+
+		for _, name := range p.Behavior {
+			b, ok := Behavior_value[name]
+			if !ok {
+				return nil, errors.Errorf("policy '%s' has invalid behavior '%s'", p.Name, name)
+			}
+			SetBehavior(&policy.Behavior, Behavior(b), true)
+		}
+
+		for _, o := range p.Overrides {
+			if o.UniqueKey == "" {
+				return nil, errors.Errorf("policy '%s' has an override which is missing a 'unique_key'", p.Name)
+			}
+			if err := validateCalendar(o.TimeZone, o.MonthStartDay); err != nil {
+				return nil, errors.Wrapf(err, "policy '%s' override '%s' has an invalid calendar", p.Name, o.UniqueKey)
+			}
+			override := PolicyOverride{
+				Limit:         o.Limit,
+				Duration:      o.Duration,
+				Burst:         o.Burst,
+				TimeZone:      o.TimeZone,
+				MonthStartDay: o.MonthStartDay,
+			}
+			if o.WeekStart != "" {
+				d, ok := parseWeekday(o.WeekStart)
+				if !ok {
+					return nil, errors.Errorf("policy '%s' override '%s' has invalid week_start '%s'",
+						p.Name, o.UniqueKey, o.WeekStart)
+				}
+				override.WeekStart = &d
+			}
+			policy.Overrides[o.UniqueKey] = override
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// validateCalendar returns an error if the time zone or month start day of a policy are invalid
+func validateCalendar(timeZone string, monthStartDay int32) error {
+	_, err := gregorianCalendar(&RateLimitReq{TimeZone: timeZone})
+	if err != nil {
+		return err
+	}
+	if monthStartDay < 0 || monthStartDay > 31 {
+		return errors.Errorf("month_start_day '%d' is not a valid day of the month", monthStartDay)
+	}
+	return nil
+}
+
+// parseWeekday returns the day of the week with the provided name, such as 'Monday'
+func parseWeekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// ReadPolicyFile parses a YAML list of policies from the provided file
+func ReadPolicyFile(path string) ([]Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "while opening policy file")
+	}
+	defer f.Close()
+	return ReadPolicies(f)
+}
+
+// PolicyRegistry holds the current set of named policies. It is safe for
+// concurrent use, such that policies can be replaced while serving requests.
+type PolicyRegistry struct {
+	mutex    sync.RWMutex
+	policies map[string]Policy
+}
+
+func NewPolicyRegistry(policies []Policy) *PolicyRegistry {
+	r := &PolicyRegistry{}
+	r.Set(policies)
+	return r
+}
+
+// Set replaces all the policies in the registry
+func (r *PolicyRegistry) Set(policies []Policy) {
+	m := make(map[string]Policy, len(policies))
+	for _, p := range policies {
+		m[p.Name] = p
+	}
+	r.mutex.Lock()
+	r.policies = m
+	r.mutex.Unlock()
+}
+
+// Get returns the policy with the provided name
+func (r *PolicyRegistry) Get(name string) (Policy, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	p, ok := r.policies[name]
+	return p, ok
+}
+
+// Len returns the number of policies in the registry
+func (r *PolicyRegistry) Len() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.policies)
+}
+
+// Apply fills in the algorithm, limit, duration and burst of the request from
+// the policy matching the request name. Behaviors from the policy are added to
+// those of the request, and the calendar of the policy is used unless the request
+// provides its own. Requests which provide their own limit or duration are
+// left untouched. Returns true if a policy was applied.
+func (r *PolicyRegistry) Apply(req *RateLimitReq) bool {
+	if req.Limit != 0 || req.Duration != 0 {
+		return false
+	}
+
+	p, ok := r.Get(req.Name)
+	if !ok {
+		return false
+	}
+
+	req.Algorithm = p.Algorithm
+	req.Limit = p.Limit
+	req.Duration = p.Duration
+	req.Burst = p.Burst
+	req.Behavior |= p.Behavior
+	timeZone, weekStart, monthStartDay := p.TimeZone, p.WeekStart, p.MonthStartDay
+
+	if o, ok := p.Overrides[req.UniqueKey]; ok {
+		if o.Limit != 0 {
+			req.Limit = o.Limit
+		}
+		if o.Duration != 0 {
+			req.Duration = o.Duration
+		}
+		if o.Burst != 0 {
+			req.Burst = o.Burst
+		}
+		if o.TimeZone != "" {
+			timeZone = o.TimeZone
+		}
+		if o.WeekStart != nil {
+			weekStart = *o.WeekStart
+		}
+		if o.MonthStartDay != 0 {
+			monthStartDay = o.MonthStartDay
+		}
+	}
+
+	if req.TimeZone == "" {
+		req.TimeZone = timeZone
+	}
+	if req.WeekStart == 0 {
+		req.WeekStart = int32(weekStart)
+	}
+	if req.MonthStartDay == 0 {
+		req.MonthStartDay = monthStartDay
+	}
+	return true
+}
+
+// PolicyFileWatcher reloads the policies in a PolicyRegistry whenever
+// the policy file it was loaded from is modified.
+type PolicyFileWatcher struct {
+	path     string
+	log      FieldLogger
+	registry *PolicyRegistry
+	wg       syncutil.WaitGroup
+	modTime  time.Time
+	size     int64
+}
+
+// NewPolicyFileWatcher loads the policies in the file at path into the registry and then checks
+// the file for changes every interval. If a modified file fails to load, the error is logged
+// and the registry keeps the policies which were last loaded successfully.
+func NewPolicyFileWatcher(log FieldLogger, path string, interval time.Duration, registry *PolicyRegistry) (*PolicyFileWatcher, error) {
+	w := &PolicyFileWatcher{
+		path:     path,
+		log:      log,
+		registry: registry,
+	}
+
+	if _, err := w.reload(); err != nil {
+		return nil, err
+	}
+
+	tick := clock.NewTicker(interval)
+	w.wg.Until(func(done chan struct{}) bool {
+		select {
+		case <-tick.C():
+			changed, err := w.reload()
+			if err != nil {
+				w.log.WithError(err).Errorf("while reloading policy file '%s'", w.path)
+				return true
+			}
+			if changed {
+				w.log.Infof("Reloaded %d policies from '%s'", w.registry.Len(), w.path)
+			}
+		case <-done:
+			tick.Stop()
+			return false
+		}
+		return true
+	})
+	return w, nil
+}
+
+// reload loads the policy file into the registry if it changed since the last load
+func (w *PolicyFileWatcher) reload() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, errors.Wrap(err, "while reading policy file info")
+	}
+
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false, nil
+	}
+
+	// Remember the file info even if the load fails, so a broken file is only reported once
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	policies, err := ReadPolicyFile(w.path)
+	if err != nil {
+		return false, errors.Wrapf(err, "while loading policy file '%s'", w.path)
+	}
+
+	w.registry.Set(policies)
+	return true, nil
+}
+
+// Close stops watching the policy file for changes
+func (w *PolicyFileWatcher) Close() {
+	w.wg.Stop()
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicies = `
+policies:
+  - name: requests_per_sec
+    algorithm: LEAKY_BUCKET
+    behavior: [GLOBAL, NO_BATCHING]
+    limit: 10
+    duration: 1000
+    burst: 20
+    overrides:
+      - unique_key: account:1234
+        limit: 100
+  - name: requests_per_min
+    limit: 5
+    duration: 60000
+`
+
+func TestReadPolicies(t *testing.T) {
+	policies, err := gubernator.ReadPolicies(strings.NewReader(testPolicies))
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+
+	assert.Equal(t, gubernator.Policy{
+		Name:      "requests_per_sec",
+		Algorithm: gubernator.Algorithm_LEAKY_BUCKET,
+		Behavior:  gubernator.Behavior_GLOBAL | gubernator.Behavior_NO_BATCHING,
+		Limit:     10,
+		Duration:  1000,
+		Burst:     20,
+		Overrides: map[string]gubernator.PolicyOverride{
+			"account:1234": {Limit: 100},
+		},
+	}, policies[0])
+	assert.Equal(t, gubernator.Algorithm_TOKEN_BUCKET, policies[1].Algorithm)
+	assert.Equal(t, int64(5), policies[1].Limit)
+
+	policies, err = gubernator.ReadPolicies(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Len(t, policies, 0)
+}
+
+func TestReadPoliciesErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		policies string
+		err      string
+	}{
+		{
+			name:     "missing name",
+			policies: "policies:\n  - limit: 10\n",
+			err:      "policy at index '0' is missing a 'name'",
+		},
+		{
+			name:     "duplicate name",
+			policies: "policies:\n  - name: a\n  - name: a\n",
+			err:      "policy 'a' is defined more than once",
+		},
+		{
+			name:     "invalid algorithm",
+			policies: "policies:\n  - name: a\n    algorithm: UNKNOWN\n",
+			err:      "policy 'a' has invalid algorithm 'UNKNOWN'",
+		},
+		{
+			name:     "invalid behavior",
+			policies: "policies:\n  - name: a\n    behavior: [UNKNOWN]\n",
+			err:      "policy 'a' has invalid behavior 'UNKNOWN'",
+		},
+		{
+			name:     "override missing unique key",
+			policies: "policies:\n  - name: a\n    overrides:\n      - limit: 10\n",
+			err:      "policy 'a' has an override which is missing a 'unique_key'",
+		},
+		{
+			name:     "invalid time zone",
+			policies: "policies:\n  - name: a\n    time_zone: Mars/Olympus_Mons\n",
+			err:      "policy 'a' has an invalid calendar: time zone 'Mars/Olympus_Mons' is not a valid IANA time zone",
+		},
+		{
+			name:     "invalid week start",
+			policies: "policies:\n  - name: a\n    week_start: Someday\n",
+			err:      "policy 'a' has invalid week_start 'Someday'",
+		},
+		{
+			name:     "invalid override month start day",
+			policies: "policies:\n  - name: a\n    overrides:\n      - unique_key: b\n        month_start_day: 32\n",
+			err:      "policy 'a' override 'b' has an invalid calendar: month_start_day '32' is not a valid day of the month",
+		},
+		{
+			name:     "invalid override week start",
+			policies: "policies:\n  - name: a\n    overrides:\n      - unique_key: b\n        week_start: Someday\n",
+			err:      "policy 'a' override 'b' has invalid week_start 'Someday'",
+		},
+		{
+			name:     "invalid yaml",
+			policies: "policies: [",
+			err:      "while decoding policies",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gubernator.ReadPolicies(strings.NewReader(tt.policies))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestPolicyRegistryApply(t *testing.T) {
+	policies, err := gubernator.ReadPolicies(strings.NewReader(testPolicies))
+	require.NoError(t, err)
+	registry := gubernator.NewPolicyRegistry(policies)
+
+	t.Run("fills in the request", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{
+			Name:      "requests_per_sec",
+			UniqueKey: "account:5678",
+			Hits:      1,
+			Behavior:  gubernator.Behavior_DRAIN_OVER_LIMIT,
+		}
+		assert.True(t, registry.Apply(req))
+		assert.Equal(t, gubernator.Algorithm_LEAKY_BUCKET, req.Algorithm)
+		assert.Equal(t, int64(10), req.Limit)
+		assert.Equal(t, int64(1000), req.Duration)
+		assert.Equal(t, int64(20), req.Burst)
+		assert.Equal(t, gubernator.Behavior_GLOBAL|gubernator.Behavior_NO_BATCHING|
+			gubernator.Behavior_DRAIN_OVER_LIMIT, req.Behavior)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{
+			Name:      "requests_per_sec",
+			UniqueKey: "account:1234",
+			Hits:      1,
+		}
+		assert.True(t, registry.Apply(req))
+		assert.Equal(t, int64(100), req.Limit)
+		assert.Equal(t, int64(1000), req.Duration)
+		assert.Equal(t, int64(20), req.Burst)
+	})
+
+	t.Run("request provides a limit", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{
+			Name:      "requests_per_sec",
+			UniqueKey: "account:5678",
+			Hits:      1,
+			Limit:     2,
+			Duration:  500,
+		}
+		assert.False(t, registry.Apply(req))
+		assert.Equal(t, gubernator.Algorithm_TOKEN_BUCKET, req.Algorithm)
+		assert.Equal(t, int64(2), req.Limit)
+		assert.Equal(t, int64(500), req.Duration)
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		policies, err := gubernator.ReadPolicies(strings.NewReader(`
+policies:
+  - name: requests_per_billing_month
+    behavior: [DURATION_IS_GREGORIAN]
+    limit: 1000
+    duration: 4
+    time_zone: America/New_York
+    week_start: monday
+    month_start_day: 15
+    overrides:
+      - unique_key: account:1234
+        time_zone: Europe/London
+        month_start_day: 3
+      - unique_key: account:9012
+        week_start: sunday
+`))
+		require.NoError(t, err)
+		registry := gubernator.NewPolicyRegistry(policies)
+
+		req := &gubernator.RateLimitReq{Name: "requests_per_billing_month", UniqueKey: "account:5678"}
+		assert.True(t, registry.Apply(req))
+		assert.Equal(t, "America/New_York", req.TimeZone)
+		assert.Equal(t, int32(time.Monday), req.WeekStart)
+		assert.Equal(t, int32(15), req.MonthStartDay)
+
+		req = &gubernator.RateLimitReq{Name: "requests_per_billing_month", UniqueKey: "account:1234"}
+		assert.True(t, registry.Apply(req))
+		assert.Equal(t, "Europe/London", req.TimeZone)
+		assert.Equal(t, int32(time.Monday), req.WeekStart)
+		assert.Equal(t, int32(3), req.MonthStartDay)
+
+		// An override may start weeks on Sunday, which is the zero weekday
+		req = &gubernator.RateLimitReq{Name: "requests_per_billing_month", UniqueKey: "account:9012"}
+		assert.True(t, registry.Apply(req))
+		assert.Equal(t, "America/New_York", req.TimeZone)
+		assert.Equal(t, int32(time.Sunday), req.WeekStart)
+		assert.Equal(t, int32(15), req.MonthStartDay)
+
+		// The calendar of the request takes precedence
+		req = &gubernator.RateLimitReq{Name: "requests_per_billing_month", UniqueKey: "account:1234", TimeZone: "UTC"}
+		assert.True(t, registry.Apply(req))
+		assert.Equal(t, "UTC", req.TimeZone)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{
+			Name:      "unknown",
+			UniqueKey: "account:5678",
+			Hits:      1,
+		}
+		assert.False(t, registry.Apply(req))
+		assert.Equal(t, int64(0), req.Limit)
+	})
+}
+
+func TestPolicyFileWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicies), 0600))
+
+	registry := gubernator.NewPolicyRegistry(nil)
+	w, err := gubernator.NewPolicyFileWatcher(logrus.WithField("category", "test"),
+		path, time.Millisecond*10, registry)
+	require.NoError(t, err)
+	defer w.Close()
+
+	p, ok := registry.Get("requests_per_min")
+	require.True(t, ok)
+	assert.Equal(t, int64(5), p.Limit)
+
+	// Invalid policy files should not replace the currently loaded policies
+	require.NoError(t, os.WriteFile(path, []byte("policies: ["), 0600))
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, 2, registry.Len())
+
+	require.NoError(t, os.WriteFile(path, []byte("policies:\n  - name: requests_per_min\n    limit: 50\n    duration: 60000\n"), 0600))
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		p, ok := registry.Get("requests_per_min")
+		assert.True(t, ok)
+		assert.Equal(t, int64(50), p.Limit)
+		assert.Equal(t, 1, registry.Len())
+	})
+
+	_, err = gubernator.NewPolicyFileWatcher(logrus.WithField("category", "test"),
+		filepath.Join(t.TempDir(), "missing.yaml"), time.Second, registry)
+	require.Error(t, err)
+}
+
+func TestGetRateLimitsWithPolicy(t *testing.T) {
+	policies, err := gubernator.ReadPolicies(strings.NewReader(testPolicies))
+	require.NoError(t, err)
+
+	srv := newV1Server(t, "localhost:0", gubernator.Config{
+		Policies: gubernator.NewPolicyRegistry(policies),
+	})
+	defer srv.Close()
+
+	client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		uniqueKey string
+		limit     int64
+	}{
+		{uniqueKey: "account:5678", limit: 5},
+		{uniqueKey: "account:1234", limit: 5},
+	} {
+		resp, err := client.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{
+				{
+					Name:      "requests_per_min",
+					UniqueKey: tt.uniqueKey,
+					Hits:      1,
+				},
+			},
+		})
+		require.NoError(t, err)
+		rl := resp.Responses[0]
+		assert.Equal(t, "", rl.Error)
+		assert.Equal(t, gubernator.Status_UNDER_LIMIT, rl.Status)
+		assert.Equal(t, tt.limit, rl.Limit)
+		assert.Equal(t, tt.limit-1, rl.Remaining)
+	}
+
+	// The per unique key override
+	resp, err := client.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+		Requests: []*gubernator.RateLimitReq{
+			{
+				Name:      "requests_per_sec",
+				UniqueKey: "account:1234",
+				Hits:      1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", resp.Responses[0].Error)
+	assert.Equal(t, int64(100), resp.Responses[0].Limit)
+}
+
+func TestReleaseRateLimitsWithPolicy(t *testing.T) {
+	policies, err := gubernator.ReadPolicies(strings.NewReader(`
+policies:
+  - name: requests_in_flight
+    algorithm: CONCURRENCY
+    limit: 2
+    duration: 60000
+`))
+	require.NoError(t, err)
+
+	srv := newV1Server(t, "localhost:0", gubernator.Config{
+		Policies: gubernator.NewPolicyRegistry(policies),
+	})
+	defer srv.Close()
+
+	client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	// Requests which only provide the name of the policy
+	req := &gubernator.RateLimitReq{Name: "requests_in_flight", UniqueKey: "account:1234", Hits: 2}
+	resp, err := client.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+		Requests: []*gubernator.RateLimitReq{req},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", resp.Responses[0].Error)
+	assert.Equal(t, int64(0), resp.Responses[0].Remaining)
+
+	release, err := client.ReleaseRateLimits(context.Background(), &gubernator.ReleaseRateLimitsReq{
+		Requests: []*gubernator.RateLimitReq{
+			{Name: "requests_in_flight", UniqueKey: "account:1234", Hits: 1},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", release.Responses[0].Error)
+	assert.Equal(t, int64(1), release.Responses[0].Remaining)
+}
@@ -15,7 +15,7 @@ _sym_db = _symbol_database.Default()
 from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x10gubernator.proto\x12\rpb.gubernator\x1a\x1cgoogle/api/annotations.proto\"K\n\x10GetRateLimitsReq\x12\x37\n\x08requests\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitReqR\x08requests\"O\n\x11GetRateLimitsResp\x12:\n\tresponses\x18\x01 \x03(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\tresponses\"\x8e\x03\n\x0cRateLimitReq\x12\x12\n\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n\nunique_key\x18\x02 \x01(\tR\tuniqueKey\x12\x12\n\x04hits\x18\x03 \x01(\x03R\x04hits\x12\x14\n\x05limit\x18\x04 \x01(\x03R\x05limit\x12\x1a\n\x08\x64uration\x18\x05 \x01(\x03R\x08\x64uration\x12\x36\n\talgorithm\x18\x06 \x01(\x0e\x32\x18.pb.gubernator.AlgorithmR\talgorithm\x12\x33\n\x08\x62\x65havior\x18\x07 \x01(\x0e\x32\x17.pb.gubernator.BehaviorR\x08\x62\x65havior\x12\x14\n\x05\x62urst\x18\x08 \x01(\x03R\x05\x62urst\x12\x45\n\x08metadata\x18\t \x03(\x0b\x32).pb.gubernator.RateLimitReq.MetadataEntryR\x08metadata\x1a;\n\rMetadataEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"\xac\x02\n\rRateLimitResp\x12-\n\x06status\x18\x01 \x01(\x0e\x32\x15.pb.gubernator.StatusR\x06status\x12\x14\n\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x1c\n\tremaining\x18\x03 \x01(\x03R\tremaining\x12\x1d\n\nreset_time\x18\x04 \x01(\x03R\tresetTime\x12\x14\n\x05\x65rror\x18\x05 \x01(\tR\x05\x65rror\x12\x46\n\x08metadata\x18\x06 \x03(\x0b\x32*.pb.gubernator.RateLimitResp.MetadataEntryR\x08metadata\x1a;\n\rMetadataEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"\x10\n\x0eHealthCheckReq\"b\n\x0fHealthCheckResp\x12\x16\n\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n\x07message\x18\x02 \x01(\tR\x07message\x12\x1d\n\npeer_count\x18\x03 \x01(\x05R\tpeerCount*/\n\tAlgorithm\x12\x10\n\x0cTOKEN_BUCKET\x10\x00\x12\x10\n\x0cLEAKY_BUCKET\x10\x01*\x8d\x01\n\x08\x42\x65havior\x12\x0c\n\x08\x42\x41TCHING\x10\x00\x12\x0f\n\x0bNO_BATCHING\x10\x01\x12\n\n\x06GLOBAL\x10\x02\x12\x19\n\x15\x44URATION_IS_GREGORIAN\x10\x04\x12\x13\n\x0fRESET_REMAINING\x10\x08\x12\x10\n\x0cMULTI_REGION\x10\x10\x12\x14\n\x10\x44RAIN_OVER_LIMIT\x10 *)\n\x06Status\x12\x0f\n\x0bUNDER_LIMIT\x10\x00\x12\x0e\n\nOVER_LIMIT\x10\x01\x32\xdd\x01\n\x02V1\x12p\n\rGetRateLimits\x12\x1f.pb.gubernator.GetRateLimitsReq\x1a .pb.gubernator.GetRateLimitsResp\"\x1c\x82\xd3\xe4\x93\x02\x16\"\x11/v1/GetRateLimits:\x01*\x12\x65\n\x0bHealthCheck\x12\x1d.pb.gubernator.HealthCheckReq\x1a\x1e.pb.gubernator.HealthCheckResp\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/HealthCheckB\"Z\x1dgithub.com/mailgun/gubernator\x80\x01\x01\x62\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x10gubernator.proto\x12\rpb.gubernator\x1a\x1cgoogle/api/annotations.proto\"c\n\x10GetRateLimitsReq\x12\x37\n\x08requests\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitReqR\x08requests\x12\x16\n\x06\x61tomic\x18\x02 \x01(\x08R\x06\x61tomic\"O\n\x11GetRateLimitsResp\x12:\n\tresponses\x18\x01 \x03(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\tresponses\"O\n\x14ReleaseRateLimitsReq\x12\x37\n\x08requests\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitReqR\x08requests\"S\n\x15ReleaseRateLimitsResp\x12:\n\tresponses\x18\x01 \x03(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\tresponses\"\xf2\x03\n\x0cRateLimitReq\x12\x12\n\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n\nunique_key\x18\x02 \x01(\tR\tuniqueKey\x12\x12\n\x04hits\x18\x03 \x01(\x03R\x04hits\x12\x14\n\x05limit\x18\x04 \x01(\x03R\x05limit\x12\x1a\n\x08\x64uration\x18\x05 \x01(\x03R\x08\x64uration\x12\x36\n\talgorithm\x18\x06 \x01(\x0e\x32\x18.pb.gubernator.AlgorithmR\talgorithm\x12\x33\n\x08\x62\x65havior\x18\x07 \x01(\x0e\x32\x17.pb.gubernator.BehaviorR\x08\x62\x65havior\x12\x14\n\x05\x62urst\x18\x08 \x01(\x03R\x05\x62urst\x12\x45\n\x08metadata\x18\t \x03(\x0b\x32).pb.gubernator.RateLimitReq.MetadataEntryR\x08metadata\x12\x1b\n\ttime_zone\x18\n \x01(\tR\x08timeZone\x12\x1d\n\nweek_start\x18\x0b \x01(\x05R\tweekStart\x12&\n\x0fmonth_start_day\x18\x0c \x01(\x05R\rmonthStartDay\x1a;\n\rMetadataEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"\xcd\x02\n\rRateLimitResp\x12-\n\x06status\x18\x01 \x01(\x0e\x32\x15.pb.gubernator.StatusR\x06status\x12\x14\n\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x1c\n\tremaining\x18\x03 \x01(\x03R\tremaining\x12\x1d\n\nreset_time\x18\x04 \x01(\x03R\tresetTime\x12\x14\n\x05\x65rror\x18\x05 \x01(\tR\x05\x65rror\x12\x46\n\x08metadata\x18\x06 \x03(\x0b\x32*.pb.gubernator.RateLimitResp.MetadataEntryR\x08metadata\x12\x1f\n\x0bretry_after\x18\x07 \x01(\x03R\nretryAfter\x1a;\n\rMetadataEntry\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n\x05value\x18\x02 \x01(\tR\x05value:\x02\x38\x01\"\x89\x01\n\x0cRateLimitKey\x12\x12\n\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n\nunique_key\x18\x02 \x01(\tR\tuniqueKey\x12\x14\n\x05limit\x18\x03 \x01(\x03R\x05limit\x12\x1a\n\x08\x64uration\x18\x04 \x01(\x03R\x08\x64uration\x12\x14\n\x05\x62urst\x18\x05 \x01(\x03R\x05\x62urst\"\xed\x02\n\x0eRateLimitState\x12\x12\n\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n\nunique_key\x18\x02 \x01(\tR\tuniqueKey\x12\x14\n\x05\x66ound\x18\x03 \x01(\x08R\x05\x66ound\x12\x36\n\talgorithm\x18\x04 \x01(\x0e\x32\x18.pb.gubernator.AlgorithmR\talgorithm\x12\x14\n\x05limit\x18\x05 \x01(\x03R\x05limit\x12\x1a\n\x08\x64uration\x18\x06 \x01(\x03R\x08\x64uration\x12\x14\n\x05\x62urst\x18\x07 \x01(\x03R\x05\x62urst\x12\x1c\n\tremaining\x18\x08 \x01(\x03R\tremaining\x12\x1d\n\nreset_time\x18\t \x01(\x03R\tresetTime\x12\x14\n\x05\x65rror\x18\n \x01(\tR\x05\x65rror\x12\x10\n\x03key\x18\x0b \x01(\tR\x03key\x12-\n\x06status\x18\x0c \x01(\x0e\x32\x15.pb.gubernator.StatusR\x06status\"G\n\x14InspectRateLimitsReq\x12/\n\x04keys\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitKeyR\x04keys\"N\n\x15InspectRateLimitsResp\x12\x35\n\x06states\x18\x01 \x03(\x0b\x32\x1d.pb.gubernator.RateLimitStateR\x06states\"F\n\x13\x44\x65leteRateLimitsReq\x12/\n\x04keys\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitKeyR\x04keys\"M\n\x14\x44\x65leteRateLimitsResp\x12\x35\n\x06states\x18\x01 \x03(\x0b\x32\x1d.pb.gubernator.RateLimitStateR\x06states\"I\n\x10SetRateLimitsReq\x12\x35\n\x06states\x18\x01 \x03(\x0b\x32\x1d.pb.gubernator.RateLimitStateR\x06states\"J\n\x11SetRateLimitsResp\x12\x35\n\x06states\x18\x01 \x03(\x0b\x32\x1d.pb.gubernator.RateLimitStateR\x06states\"\x86\x01\n\x11ListRateLimitsReq\x12\x16\n\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x1d\n\nover_limit\x18\x02 \x01(\x08R\toverLimit\x12\x1b\n\tpage_size\x18\x03 \x01(\x05R\x08pageSize\x12\x1d\n\npage_token\x18\x04 \x01(\tR\tpageToken\"s\n\x12ListRateLimitsResp\x12\x35\n\x06states\x18\x01 \x03(\x0b\x32\x1d.pb.gubernator.RateLimitStateR\x06states\x12&\n\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x10\n\x0eHealthCheckReq\"b\n\x0fHealthCheckResp\x12\x16\n\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n\x07message\x18\x02 \x01(\tR\x07message\x12\x1d\n\npeer_count\x18\x03 \x01(\x05R\tpeerCount*{\n\tAlgorithm\x12\x10\n\x0cTOKEN_BUCKET\x10\x00\x12\x10\n\x0cLEAKY_BUCKET\x10\x01\x12\x12\n\x0eSLIDING_WINDOW\x10\x02\x12\x08\n\x04GCRA\x10\x03\x12\x0f\n\x0b\x43ONCURRENCY\x10\x04\x12\x1b\n\x17\x43ONTINUOUS_TOKEN_BUCKET\x10\x05*\x99\x01\n\x08\x42\x65havior\x12\x0c\n\x08\x42\x41TCHING\x10\x00\x12\x0f\n\x0bNO_BATCHING\x10\x01\x12\n\n\x06GLOBAL\x10\x02\x12\x19\n\x15\x44URATION_IS_GREGORIAN\x10\x04\x12\x13\n\x0fRESET_REMAINING\x10\x08\x12\x10\n\x0cMULTI_REGION\x10\x10\x12\x14\n\x10\x44RAIN_OVER_LIMIT\x10 \x12\n\n\x06SHADOW\x10@*)\n\x06Status\x12\x0f\n\x0bUNDER_LIMIT\x10\x00\x12\x0e\n\nOVER_LIMIT\x10\x01\x32\xe2\x06\n\x02V1\x12p\n\rGetRateLimits\x12\x1f.pb.gubernator.GetRateLimitsReq\x1a .pb.gubernator.GetRateLimitsResp\"\x1c\x82\xd3\xe4\x93\x02\x16\"\x11/v1/GetRateLimits:\x01*\x12\x80\x01\n\x11ReleaseRateLimits\x12#.pb.gubernator.ReleaseRateLimitsReq\x1a$.pb.gubernator.ReleaseRateLimitsResp\" \x82\xd3\xe4\x93\x02\x1a\"\x15/v1/ReleaseRateLimits:\x01*\x12\x86\x01\n\x11InspectRateLimits\x12#.pb.gubernator.InspectRateLimitsReq\x1a$.pb.gubernator.InspectRateLimitsResp\"&\x82\xd3\xe4\x93\x02 \"\x1b/v1/admin/InspectRateLimits:\x01*\x12\x82\x01\n\x10\x44\x65leteRateLimits\x12\".pb.gubernator.DeleteRateLimitsReq\x1a#.pb.gubernator.DeleteRateLimitsResp\"%\x82\xd3\xe4\x93\x02\x1f\"\x1a/v1/admin/DeleteRateLimits:\x01*\x12v\n\rSetRateLimits\x12\x1f.pb.gubernator.SetRateLimitsReq\x1a .pb.gubernator.SetRateLimitsResp\"\"\x82\xd3\xe4\x93\x02\x1c\"\x17/v1/admin/SetRateLimits:\x01*\x12z\n\x0eListRateLimits\x12 .pb.gubernator.ListRateLimitsReq\x1a!.pb.gubernator.ListRateLimitsResp\"#\x82\xd3\xe4\x93\x02\x1d\"\x18/v1/admin/ListRateLimits:\x01*\x12\x65\n\x0bHealthCheck\x12\x1d.pb.gubernator.HealthCheckReq\x1a\x1e.pb.gubernator.HealthCheckResp\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/HealthCheckB\"Z\x1dgithub.com/mailgun/gubernator\x80\x01\x01\x62\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -29,30 +29,64 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['_RATELIMITRESP_METADATAENTRY']._serialized_options = b'8\001'
   _globals['_V1'].methods_by_name['GetRateLimits']._options = None
   _globals['_V1'].methods_by_name['GetRateLimits']._serialized_options = b'\202\323\344\223\002\026\"\021/v1/GetRateLimits:\001*'
+  _globals['_V1'].methods_by_name['ReleaseRateLimits']._options = None
+  _globals['_V1'].methods_by_name['ReleaseRateLimits']._serialized_options = b'\202\323\344\223\002\032\"\025/v1/ReleaseRateLimits:\001*'
+  _globals['_V1'].methods_by_name['InspectRateLimits']._options = None
+  _globals['_V1'].methods_by_name['InspectRateLimits']._serialized_options = b'\202\323\344\223\002 \"\033/v1/admin/InspectRateLimits:\001*'
+  _globals['_V1'].methods_by_name['DeleteRateLimits']._options = None
+  _globals['_V1'].methods_by_name['DeleteRateLimits']._serialized_options = b'\202\323\344\223\002\037\"\032/v1/admin/DeleteRateLimits:\001*'
+  _globals['_V1'].methods_by_name['SetRateLimits']._options = None
+  _globals['_V1'].methods_by_name['SetRateLimits']._serialized_options = b'\202\323\344\223\002\034\"\027/v1/admin/SetRateLimits:\001*'
+  _globals['_V1'].methods_by_name['ListRateLimits']._options = None
+  _globals['_V1'].methods_by_name['ListRateLimits']._serialized_options = b'\202\323\344\223\002\035\"\030/v1/admin/ListRateLimits:\001*'
   _globals['_V1'].methods_by_name['HealthCheck']._options = None
   _globals['_V1'].methods_by_name['HealthCheck']._serialized_options = b'\202\323\344\223\002\021\022\017/v1/HealthCheck'
-  _globals['_ALGORITHM']._serialized_start=1045
-  _globals['_ALGORITHM']._serialized_end=1092
-  _globals['_BEHAVIOR']._serialized_start=1095
-  _globals['_BEHAVIOR']._serialized_end=1236
-  _globals['_STATUS']._serialized_start=1238
-  _globals['_STATUS']._serialized_end=1279
+  _globals['_ALGORITHM']._serialized_start=2585
+  _globals['_ALGORITHM']._serialized_end=2708
+  _globals['_BEHAVIOR']._serialized_start=2711
+  _globals['_BEHAVIOR']._serialized_end=2864
+  _globals['_STATUS']._serialized_start=2866
+  _globals['_STATUS']._serialized_end=2907
   _globals['_GETRATELIMITSREQ']._serialized_start=65
-  _globals['_GETRATELIMITSREQ']._serialized_end=140
-  _globals['_GETRATELIMITSRESP']._serialized_start=142
-  _globals['_GETRATELIMITSRESP']._serialized_end=221
-  _globals['_RATELIMITREQ']._serialized_start=224
-  _globals['_RATELIMITREQ']._serialized_end=622
-  _globals['_RATELIMITREQ_METADATAENTRY']._serialized_start=563
-  _globals['_RATELIMITREQ_METADATAENTRY']._serialized_end=622
-  _globals['_RATELIMITRESP']._serialized_start=625
-  _globals['_RATELIMITRESP']._serialized_end=925
-  _globals['_RATELIMITRESP_METADATAENTRY']._serialized_start=563
-  _globals['_RATELIMITRESP_METADATAENTRY']._serialized_end=622
-  _globals['_HEALTHCHECKREQ']._serialized_start=927
-  _globals['_HEALTHCHECKREQ']._serialized_end=943
-  _globals['_HEALTHCHECKRESP']._serialized_start=945
-  _globals['_HEALTHCHECKRESP']._serialized_end=1043
-  _globals['_V1']._serialized_start=1282
-  _globals['_V1']._serialized_end=1503
+  _globals['_GETRATELIMITSREQ']._serialized_end=164
+  _globals['_GETRATELIMITSRESP']._serialized_start=166
+  _globals['_GETRATELIMITSRESP']._serialized_end=245
+  _globals['_RELEASERATELIMITSREQ']._serialized_start=247
+  _globals['_RELEASERATELIMITSREQ']._serialized_end=326
+  _globals['_RELEASERATELIMITSRESP']._serialized_start=328
+  _globals['_RELEASERATELIMITSRESP']._serialized_end=411
+  _globals['_RATELIMITREQ']._serialized_start=414
+  _globals['_RATELIMITREQ']._serialized_end=912
+  _globals['_RATELIMITREQ_METADATAENTRY']._serialized_start=853
+  _globals['_RATELIMITREQ_METADATAENTRY']._serialized_end=912
+  _globals['_RATELIMITRESP']._serialized_start=915
+  _globals['_RATELIMITRESP']._serialized_end=1248
+  _globals['_RATELIMITRESP_METADATAENTRY']._serialized_start=853
+  _globals['_RATELIMITRESP_METADATAENTRY']._serialized_end=912
+  _globals['_RATELIMITKEY']._serialized_start=1251
+  _globals['_RATELIMITKEY']._serialized_end=1388
+  _globals['_RATELIMITSTATE']._serialized_start=1391
+  _globals['_RATELIMITSTATE']._serialized_end=1756
+  _globals['_INSPECTRATELIMITSREQ']._serialized_start=1758
+  _globals['_INSPECTRATELIMITSREQ']._serialized_end=1829
+  _globals['_INSPECTRATELIMITSRESP']._serialized_start=1831
+  _globals['_INSPECTRATELIMITSRESP']._serialized_end=1909
+  _globals['_DELETERATELIMITSREQ']._serialized_start=1911
+  _globals['_DELETERATELIMITSREQ']._serialized_end=1981
+  _globals['_DELETERATELIMITSRESP']._serialized_start=1983
+  _globals['_DELETERATELIMITSRESP']._serialized_end=2060
+  _globals['_SETRATELIMITSREQ']._serialized_start=2062
+  _globals['_SETRATELIMITSREQ']._serialized_end=2135
+  _globals['_SETRATELIMITSRESP']._serialized_start=2137
+  _globals['_SETRATELIMITSRESP']._serialized_end=2211
+  _globals['_LISTRATELIMITSREQ']._serialized_start=2214
+  _globals['_LISTRATELIMITSREQ']._serialized_end=2348
+  _globals['_LISTRATELIMITSRESP']._serialized_start=2350
+  _globals['_LISTRATELIMITSRESP']._serialized_end=2465
+  _globals['_HEALTHCHECKREQ']._serialized_start=2467
+  _globals['_HEALTHCHECKREQ']._serialized_end=2483
+  _globals['_HEALTHCHECKRESP']._serialized_start=2485
+  _globals['_HEALTHCHECKRESP']._serialized_end=2583
+  _globals['_V1']._serialized_start=2910
+  _globals['_V1']._serialized_end=3776
 # @@protoc_insertion_point(module_scope)
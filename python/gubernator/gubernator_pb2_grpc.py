@@ -19,6 +19,31 @@ class V1Stub(object):
                 request_serializer=gubernator__pb2.GetRateLimitsReq.SerializeToString,
                 response_deserializer=gubernator__pb2.GetRateLimitsResp.FromString,
                 )
+        self.ReleaseRateLimits = channel.unary_unary(
+                '/pb.gubernator.V1/ReleaseRateLimits',
+                request_serializer=gubernator__pb2.ReleaseRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.ReleaseRateLimitsResp.FromString,
+                )
+        self.InspectRateLimits = channel.unary_unary(
+                '/pb.gubernator.V1/InspectRateLimits',
+                request_serializer=gubernator__pb2.InspectRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.InspectRateLimitsResp.FromString,
+                )
+        self.DeleteRateLimits = channel.unary_unary(
+                '/pb.gubernator.V1/DeleteRateLimits',
+                request_serializer=gubernator__pb2.DeleteRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.DeleteRateLimitsResp.FromString,
+                )
+        self.SetRateLimits = channel.unary_unary(
+                '/pb.gubernator.V1/SetRateLimits',
+                request_serializer=gubernator__pb2.SetRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.SetRateLimitsResp.FromString,
+                )
+        self.ListRateLimits = channel.unary_unary(
+                '/pb.gubernator.V1/ListRateLimits',
+                request_serializer=gubernator__pb2.ListRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.ListRateLimitsResp.FromString,
+                )
         self.HealthCheck = channel.unary_unary(
                 '/pb.gubernator.V1/HealthCheck',
                 request_serializer=gubernator__pb2.HealthCheckReq.SerializeToString,
@@ -36,6 +61,46 @@ class V1Servicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def ReleaseRateLimits(self, request, context):
+        """Given a list of CONCURRENCY rate limit requests, release the number of slots given
+        by `hits` which were previously acquired by GetRateLimits. Leases are not tracked per
+        caller, so the oldest slots are released first regardless of which caller acquired them.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def InspectRateLimits(self, request, context):
+        """Admin: Return the current state of the given rate limits from their owning peers
+        without consuming any hits.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteRateLimits(self, request, context):
+        """Admin: Remove the state of the given rate limits from their owning peers, such that the
+        next hit starts a new rate limit.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetRateLimits(self, request, context):
+        """Admin: Overwrite the state of the given rate limits on their owning peers.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListRateLimits(self, request, context):
+        """Admin: List the state of the rate limits held by every peer in the local cluster which match
+        the provided filters, ordered by their key.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def HealthCheck(self, request, context):
         """This method is for round trip benchmarking and can be used by
         the client to determine connectivity to the server
@@ -52,6 +117,31 @@ def add_V1Servicer_to_server(servicer, server):
                     request_deserializer=gubernator__pb2.GetRateLimitsReq.FromString,
                     response_serializer=gubernator__pb2.GetRateLimitsResp.SerializeToString,
             ),
+            'ReleaseRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReleaseRateLimits,
+                    request_deserializer=gubernator__pb2.ReleaseRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.ReleaseRateLimitsResp.SerializeToString,
+            ),
+            'InspectRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.InspectRateLimits,
+                    request_deserializer=gubernator__pb2.InspectRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.InspectRateLimitsResp.SerializeToString,
+            ),
+            'DeleteRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteRateLimits,
+                    request_deserializer=gubernator__pb2.DeleteRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.DeleteRateLimitsResp.SerializeToString,
+            ),
+            'SetRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetRateLimits,
+                    request_deserializer=gubernator__pb2.SetRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.SetRateLimitsResp.SerializeToString,
+            ),
+            'ListRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListRateLimits,
+                    request_deserializer=gubernator__pb2.ListRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.ListRateLimitsResp.SerializeToString,
+            ),
             'HealthCheck': grpc.unary_unary_rpc_method_handler(
                     servicer.HealthCheck,
                     request_deserializer=gubernator__pb2.HealthCheckReq.FromString,
@@ -84,6 +174,91 @@ class V1(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def ReleaseRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.V1/ReleaseRateLimits',
+            gubernator__pb2.ReleaseRateLimitsReq.SerializeToString,
+            gubernator__pb2.ReleaseRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def InspectRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.V1/InspectRateLimits',
+            gubernator__pb2.InspectRateLimitsReq.SerializeToString,
+            gubernator__pb2.InspectRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.V1/DeleteRateLimits',
+            gubernator__pb2.DeleteRateLimitsReq.SerializeToString,
+            gubernator__pb2.DeleteRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SetRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.V1/SetRateLimits',
+            gubernator__pb2.SetRateLimitsReq.SerializeToString,
+            gubernator__pb2.SetRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.V1/ListRateLimits',
+            gubernator__pb2.ListRateLimitsReq.SerializeToString,
+            gubernator__pb2.ListRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def HealthCheck(request,
             target,
@@ -15,7 +15,7 @@ _sym_db = _symbol_database.Default()
 import gubernator_pb2 as gubernator__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0bpeers.proto\x12\rpb.gubernator\x1a\x10gubernator.proto\"O\n\x14GetPeerRateLimitsReq\x12\x37\n\x08requests\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitReqR\x08requests\"V\n\x15GetPeerRateLimitsResp\x12=\n\x0brate_limits\x18\x01 \x03(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\nrateLimits\"Q\n\x14UpdatePeerGlobalsReq\x12\x39\n\x07globals\x18\x01 \x03(\x0b\x32\x1f.pb.gubernator.UpdatePeerGlobalR\x07globals\"\x92\x01\n\x10UpdatePeerGlobal\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x34\n\x06status\x18\x02 \x01(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\x06status\x12\x36\n\talgorithm\x18\x03 \x01(\x0e\x32\x18.pb.gubernator.AlgorithmR\talgorithm\"\x17\n\x15UpdatePeerGlobalsResp2\xcd\x01\n\x07PeersV1\x12`\n\x11GetPeerRateLimits\x12#.pb.gubernator.GetPeerRateLimitsReq\x1a$.pb.gubernator.GetPeerRateLimitsResp\"\x00\x12`\n\x11UpdatePeerGlobals\x12#.pb.gubernator.UpdatePeerGlobalsReq\x1a$.pb.gubernator.UpdatePeerGlobalsResp\"\x00\x42\"Z\x1dgithub.com/mailgun/gubernator\x80\x01\x01\x62\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0bpeers.proto\x12\rpb.gubernator\x1a\x10gubernator.proto\"O\n\x14GetPeerRateLimitsReq\x12\x37\n\x08requests\x18\x01 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitReqR\x08requests\"V\n\x15GetPeerRateLimitsResp\x12=\n\x0brate_limits\x18\x01 \x03(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\nrateLimits\"Q\n\x14UpdatePeerGlobalsReq\x12\x39\n\x07globals\x18\x01 \x03(\x0b\x32\x1f.pb.gubernator.UpdatePeerGlobalR\x07globals\"\xc4\x01\n\x10UpdatePeerGlobal\x12\x10\n\x03key\x18\x01 \x01(\tR\x03key\x12\x34\n\x06status\x18\x02 \x01(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\x06status\x12\x36\n\talgorithm\x18\x03 \x01(\x0e\x32\x18.pb.gubernator.AlgorithmR\talgorithm\x12\x1a\n\x08\x64uration\x18\x04 \x01(\x03R\x08\x64uration\x12\x14\n\x05\x62urst\x18\x05 \x01(\x03R\x05\x62urst\"\x17\n\x15UpdatePeerGlobalsResp\"K\n\x19TransferPeerRateLimitsReq\x12\x14\n\x05items\x18\x01 \x03(\x0cR\x05items\x12\x18\n\x07version\x18\x02 \x01(\rR\x07version\"8\n\x1aTransferPeerRateLimitsResp\x12\x1a\n\x08\x61\x63\x63\x65pted\x18\x01 \x01(\x05R\x08\x61\x63\x63\x65pted\"L\n\x1aReplicatePeerRateLimitsReq\x12\x14\n\x05items\x18\x01 \x03(\x0cR\x05items\x12\x18\n\x07version\x18\x02 \x01(\rR\x07version\"9\n\x1bReplicatePeerRateLimitsResp\x12\x1a\n\x08\x61\x63\x63\x65pted\x18\x01 \x01(\x05R\x08\x61\x63\x63\x65pted\"z\n\x18ReservePeerRateLimitsReq\x12%\n\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\x12\x37\n\x08requests\x18\x02 \x03(\x0b\x32\x1b.pb.gubernator.RateLimitReqR\x08requests\"Z\n\x19ReservePeerRateLimitsResp\x12=\n\x0brate_limits\x18\x01 \x03(\x0b\x32\x1c.pb.gubernator.RateLimitRespR\nrateLimits\"V\n\x17\x43ommitPeerRateLimitsReq\x12%\n\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\x12\x14\n\x05\x61\x62ort\x18\x02 \x01(\x08R\x05\x61\x62ort\"\x1a\n\x18\x43ommitPeerRateLimitsResp2\x8b\x08\n\x07PeersV1\x12`\n\x11GetPeerRateLimits\x12#.pb.gubernator.GetPeerRateLimitsReq\x1a$.pb.gubernator.GetPeerRateLimitsResp\"\x00\x12`\n\x11UpdatePeerGlobals\x12#.pb.gubernator.UpdatePeerGlobalsReq\x1a$.pb.gubernator.UpdatePeerGlobalsResp\"\x00\x12\x64\n\x15InspectPeerRateLimits\x12#.pb.gubernator.InspectRateLimitsReq\x1a$.pb.gubernator.InspectRateLimitsResp\"\x00\x12\x61\n\x14\x44\x65letePeerRateLimits\x12\".pb.gubernator.DeleteRateLimitsReq\x1a#.pb.gubernator.DeleteRateLimitsResp\"\x00\x12X\n\x11SetPeerRateLimits\x12\x1f.pb.gubernator.SetRateLimitsReq\x1a .pb.gubernator.SetRateLimitsResp\"\x00\x12[\n\x12ListPeerRateLimits\x12 .pb.gubernator.ListRateLimitsReq\x1a!.pb.gubernator.ListRateLimitsResp\"\x00\x12o\n\x16TransferPeerRateLimits\x12(.pb.gubernator.TransferPeerRateLimitsReq\x1a).pb.gubernator.TransferPeerRateLimitsResp\"\x00\x12r\n\x17ReplicatePeerRateLimits\x12).pb.gubernator.ReplicatePeerRateLimitsReq\x1a*.pb.gubernator.ReplicatePeerRateLimitsResp\"\x00\x12l\n\x15ReservePeerRateLimits\x12\'.pb.gubernator.ReservePeerRateLimitsReq\x1a(.pb.gubernator.ReservePeerRateLimitsResp\"\x00\x12i\n\x14\x43ommitPeerRateLimits\x12&.pb.gubernator.CommitPeerRateLimitsReq\x1a\'.pb.gubernator.CommitPeerRateLimitsResp\"\x00\x42\"Z\x1dgithub.com/mailgun/gubernator\x80\x01\x01\x62\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -30,9 +30,25 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['_UPDATEPEERGLOBALSREQ']._serialized_start=217
   _globals['_UPDATEPEERGLOBALSREQ']._serialized_end=298
   _globals['_UPDATEPEERGLOBAL']._serialized_start=301
-  _globals['_UPDATEPEERGLOBAL']._serialized_end=447
-  _globals['_UPDATEPEERGLOBALSRESP']._serialized_start=449
-  _globals['_UPDATEPEERGLOBALSRESP']._serialized_end=472
-  _globals['_PEERSV1']._serialized_start=475
-  _globals['_PEERSV1']._serialized_end=680
+  _globals['_UPDATEPEERGLOBAL']._serialized_end=497
+  _globals['_UPDATEPEERGLOBALSRESP']._serialized_start=499
+  _globals['_UPDATEPEERGLOBALSRESP']._serialized_end=522
+  _globals['_TRANSFERPEERRATELIMITSREQ']._serialized_start=524
+  _globals['_TRANSFERPEERRATELIMITSREQ']._serialized_end=599
+  _globals['_TRANSFERPEERRATELIMITSRESP']._serialized_start=601
+  _globals['_TRANSFERPEERRATELIMITSRESP']._serialized_end=657
+  _globals['_REPLICATEPEERRATELIMITSREQ']._serialized_start=659
+  _globals['_REPLICATEPEERRATELIMITSREQ']._serialized_end=735
+  _globals['_REPLICATEPEERRATELIMITSRESP']._serialized_start=737
+  _globals['_REPLICATEPEERRATELIMITSRESP']._serialized_end=794
+  _globals['_RESERVEPEERRATELIMITSREQ']._serialized_start=796
+  _globals['_RESERVEPEERRATELIMITSREQ']._serialized_end=918
+  _globals['_RESERVEPEERRATELIMITSRESP']._serialized_start=920
+  _globals['_RESERVEPEERRATELIMITSRESP']._serialized_end=1010
+  _globals['_COMMITPEERRATELIMITSREQ']._serialized_start=1012
+  _globals['_COMMITPEERRATELIMITSREQ']._serialized_end=1098
+  _globals['_COMMITPEERRATELIMITSRESP']._serialized_start=1100
+  _globals['_COMMITPEERRATELIMITSRESP']._serialized_end=1126
+  _globals['_PEERSV1']._serialized_start=1129
+  _globals['_PEERSV1']._serialized_end=2164
 # @@protoc_insertion_point(module_scope)
@@ -2,6 +2,7 @@
 """Client and server classes corresponding to protobuf-defined services."""
 import grpc
 
+import gubernator_pb2 as gubernator__pb2
 import peers_pb2 as peers__pb2
 
 
@@ -25,6 +26,46 @@ class PeersV1Stub(object):
                 request_serializer=peers__pb2.UpdatePeerGlobalsReq.SerializeToString,
                 response_deserializer=peers__pb2.UpdatePeerGlobalsResp.FromString,
                 )
+        self.InspectPeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/InspectPeerRateLimits',
+                request_serializer=gubernator__pb2.InspectRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.InspectRateLimitsResp.FromString,
+                )
+        self.DeletePeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/DeletePeerRateLimits',
+                request_serializer=gubernator__pb2.DeleteRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.DeleteRateLimitsResp.FromString,
+                )
+        self.SetPeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/SetPeerRateLimits',
+                request_serializer=gubernator__pb2.SetRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.SetRateLimitsResp.FromString,
+                )
+        self.ListPeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/ListPeerRateLimits',
+                request_serializer=gubernator__pb2.ListRateLimitsReq.SerializeToString,
+                response_deserializer=gubernator__pb2.ListRateLimitsResp.FromString,
+                )
+        self.TransferPeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/TransferPeerRateLimits',
+                request_serializer=peers__pb2.TransferPeerRateLimitsReq.SerializeToString,
+                response_deserializer=peers__pb2.TransferPeerRateLimitsResp.FromString,
+                )
+        self.ReplicatePeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/ReplicatePeerRateLimits',
+                request_serializer=peers__pb2.ReplicatePeerRateLimitsReq.SerializeToString,
+                response_deserializer=peers__pb2.ReplicatePeerRateLimitsResp.FromString,
+                )
+        self.ReservePeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/ReservePeerRateLimits',
+                request_serializer=peers__pb2.ReservePeerRateLimitsReq.SerializeToString,
+                response_deserializer=peers__pb2.ReservePeerRateLimitsResp.FromString,
+                )
+        self.CommitPeerRateLimits = channel.unary_unary(
+                '/pb.gubernator.PeersV1/CommitPeerRateLimits',
+                request_serializer=peers__pb2.CommitPeerRateLimitsReq.SerializeToString,
+                response_deserializer=peers__pb2.CommitPeerRateLimitsResp.FromString,
+                )
 
 
 class PeersV1Servicer(object):
@@ -45,6 +86,64 @@ class PeersV1Servicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def InspectPeerRateLimits(self, request, context):
+        """Used by peers to relay admin requests to the owner peer. The peer that receives these
+        requests MUST be authoritative for each of the rate limits provided.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeletePeerRateLimits(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetPeerRateLimits(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListPeerRateLimits(self, request, context):
+        """Used by peers to list the rate limits held by each peer in the cluster
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def TransferPeerRateLimits(self, request, context):
+        """Used by peers to hand off the rate limits they no longer own to the new owner after the
+        peer ring changed, such that the new owner does not start again from a full bucket
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReplicatePeerRateLimits(self, request, context):
+        """Used by owner peers to replicate the state of their rate limits to the successor peers on the
+        ring, such that a successor can take over with near current counts if the owner fails
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReservePeerRateLimits(self, request, context):
+        """Used by the peer which received an atomic GetRateLimitsReq to apply the hits of the rate limits
+        owned by another peer, which are held as a reservation until CommitPeerRateLimits commits or
+        aborts them. The peer that receives these requests MUST be authoritative for each rate limit.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CommitPeerRateLimits(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_PeersV1Servicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -58,6 +157,46 @@ def add_PeersV1Servicer_to_server(servicer, server):
                     request_deserializer=peers__pb2.UpdatePeerGlobalsReq.FromString,
                     response_serializer=peers__pb2.UpdatePeerGlobalsResp.SerializeToString,
             ),
+            'InspectPeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.InspectPeerRateLimits,
+                    request_deserializer=gubernator__pb2.InspectRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.InspectRateLimitsResp.SerializeToString,
+            ),
+            'DeletePeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeletePeerRateLimits,
+                    request_deserializer=gubernator__pb2.DeleteRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.DeleteRateLimitsResp.SerializeToString,
+            ),
+            'SetPeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetPeerRateLimits,
+                    request_deserializer=gubernator__pb2.SetRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.SetRateLimitsResp.SerializeToString,
+            ),
+            'ListPeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListPeerRateLimits,
+                    request_deserializer=gubernator__pb2.ListRateLimitsReq.FromString,
+                    response_serializer=gubernator__pb2.ListRateLimitsResp.SerializeToString,
+            ),
+            'TransferPeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.TransferPeerRateLimits,
+                    request_deserializer=peers__pb2.TransferPeerRateLimitsReq.FromString,
+                    response_serializer=peers__pb2.TransferPeerRateLimitsResp.SerializeToString,
+            ),
+            'ReplicatePeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReplicatePeerRateLimits,
+                    request_deserializer=peers__pb2.ReplicatePeerRateLimitsReq.FromString,
+                    response_serializer=peers__pb2.ReplicatePeerRateLimitsResp.SerializeToString,
+            ),
+            'ReservePeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReservePeerRateLimits,
+                    request_deserializer=peers__pb2.ReservePeerRateLimitsReq.FromString,
+                    response_serializer=peers__pb2.ReservePeerRateLimitsResp.SerializeToString,
+            ),
+            'CommitPeerRateLimits': grpc.unary_unary_rpc_method_handler(
+                    servicer.CommitPeerRateLimits,
+                    request_deserializer=peers__pb2.CommitPeerRateLimitsReq.FromString,
+                    response_serializer=peers__pb2.CommitPeerRateLimitsResp.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'pb.gubernator.PeersV1', rpc_method_handlers)
@@ -102,3 +241,139 @@ class PeersV1(object):
             peers__pb2.UpdatePeerGlobalsResp.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def InspectPeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/InspectPeerRateLimits',
+            gubernator__pb2.InspectRateLimitsReq.SerializeToString,
+            gubernator__pb2.InspectRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeletePeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/DeletePeerRateLimits',
+            gubernator__pb2.DeleteRateLimitsReq.SerializeToString,
+            gubernator__pb2.DeleteRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SetPeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/SetPeerRateLimits',
+            gubernator__pb2.SetRateLimitsReq.SerializeToString,
+            gubernator__pb2.SetRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListPeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/ListPeerRateLimits',
+            gubernator__pb2.ListRateLimitsReq.SerializeToString,
+            gubernator__pb2.ListRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def TransferPeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/TransferPeerRateLimits',
+            peers__pb2.TransferPeerRateLimitsReq.SerializeToString,
+            peers__pb2.TransferPeerRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ReplicatePeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/ReplicatePeerRateLimits',
+            peers__pb2.ReplicatePeerRateLimitsReq.SerializeToString,
+            peers__pb2.ReplicatePeerRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ReservePeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/ReservePeerRateLimits',
+            peers__pb2.ReservePeerRateLimitsReq.SerializeToString,
+            peers__pb2.ReservePeerRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CommitPeerRateLimits(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.gubernator.PeersV1/CommitPeerRateLimits',
+            peers__pb2.CommitPeerRateLimitsReq.SerializeToString,
+            peers__pb2.CommitPeerRateLimitsResp.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -0,0 +1,316 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mailgun/holster/v4/setter"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// The number of keys read or written to redis in a single round trip by Load() and Save()
+const redisBatchSize = 500
+
+type RedisConfig struct {
+	// (Required) The `address:port` of the redis server
+	Address string
+
+	// (Optional) Credentials used to connect to the redis server
+	Username string
+	Password string
+
+	// (Optional) The redis database to store rate limits in. Defaults to 0
+	DB int
+
+	// (Optional) The prefix of every key stored in redis. Defaults to 'gubernator:'
+	KeyPrefix string
+
+	// (Optional) How long to wait for each redis operation. Defaults to 500ms
+	Timeout time.Duration
+
+	// (Optional) A Logger which implements the declared logger interface (typically *logrus.Entry)
+	Logger FieldLogger
+}
+
+// RedisStore persists rate limits in redis. It implements both the StoreV2 interface, which
+// keeps redis up to date as rate limits change, and the Loader interface, which loads
+// all the rate limits in redis into the cache on startup and saves the cache on shutdown.
+type RedisStore struct {
+	conf   RedisConfig
+	client *redis.Client
+}
+
+var _ StoreV2 = &RedisStore{}
+var _ Loader = &RedisStore{}
+var _ IncrementalLoader = &RedisStore{}
+var _ StoreBatchWriter = &RedisStore{}
+
+// NewRedisStore connects to the redis server and returns a RedisStore
+func NewRedisStore(conf RedisConfig) (*RedisStore, error) {
+	setter.SetDefault(&conf.KeyPrefix, "gubernator:")
+	setter.SetDefault(&conf.Timeout, time.Millisecond*500)
+	setter.SetDefault(&conf.Logger, logrus.WithField("category", "gubernator"))
+
+	if conf.Address == "" {
+		return nil, errors.New("RedisConfig.Address is required")
+	}
+
+	s := &RedisStore{
+		conf: conf,
+		client: redis.NewClient(&redis.Options{
+			Addr:         conf.Address,
+			Username:     conf.Username,
+			Password:     conf.Password,
+			DB:           conf.DB,
+			DialTimeout:  conf.Timeout,
+			ReadTimeout:  conf.Timeout,
+			WriteTimeout: conf.Timeout,
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Timeout)
+	defer cancel()
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		_ = s.client.Close()
+		return nil, errors.Wrapf(err, "while connecting to redis at '%s'", conf.Address)
+	}
+	return s, nil
+}
+
+// OnChange writes the rate limit to redis, expiring it when the rate limit expires
+func (s *RedisStore) OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) error {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	ttl := s.ttl(item)
+	if ttl <= 0 {
+		return s.Remove(ctx, item.Key)
+	}
+
+	b, err := marshalCacheItem(item)
+	if err != nil {
+		return errors.Wrapf(err, "while encoding rate limit '%s'", item.Key)
+	}
+
+	if err := s.client.Set(ctx, s.conf.KeyPrefix+item.Key, b, ttl).Err(); err != nil {
+		return errors.Wrapf(err, "while storing rate limit '%s' in redis", item.Key)
+	}
+	return nil
+}
+
+// Get reads the rate limit from redis
+func (s *RedisStore) Get(ctx context.Context, r *RateLimitReq) (*CacheItem, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	b, err := s.client.Get(ctx, s.conf.KeyPrefix+r.HashKey()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "while reading rate limit '%s' from redis", r.HashKey())
+	}
+
+	item, err := unmarshalCacheItem(b)
+	if err != nil {
+		// A rate limit we can not decode is as good as missing
+		s.conf.Logger.WithError(err).Errorf("while decoding rate limit '%s' from redis", r.HashKey())
+		return nil, false, nil
+	}
+
+	if item.ExpireAt < MillisecondNow() {
+		return nil, false, nil
+	}
+	return item, true, nil
+}
+
+// Remove deletes the rate limit from redis
+func (s *RedisStore) Remove(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	if err := s.client.Del(ctx, s.conf.KeyPrefix+key).Err(); err != nil {
+		return errors.Wrapf(err, "while removing rate limit '%s' from redis", key)
+	}
+	return nil
+}
+
+// WriteBatch writes or removes the rate limits in a single pipeline
+func (s *RedisStore) WriteBatch(ctx context.Context, batch []StoreChange) error {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, c := range batch {
+			var ttl time.Duration
+			if c.Item != nil {
+				ttl = s.ttl(c.Item)
+			}
+			if ttl <= 0 {
+				pipe.Del(ctx, s.conf.KeyPrefix+c.Key)
+				continue
+			}
+			b, err := marshalCacheItem(c.Item)
+			if err != nil {
+				return errors.Wrapf(err, "while encoding rate limit '%s'", c.Key)
+			}
+			pipe.Set(ctx, s.conf.KeyPrefix+c.Key, b, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "while writing rate limits to redis")
+	}
+	return nil
+}
+
+// Load reads every rate limit with our key prefix from redis
+func (s *RedisStore) Load() (chan *CacheItem, error) {
+	// Ensure we can reach redis before returning the channel
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout)
+	err := s.client.Ping(ctx).Err()
+	cancel()
+	if err != nil {
+		return nil, errors.Wrap(err, "while connecting to redis")
+	}
+
+	out := make(chan *CacheItem, redisBatchSize)
+	go func() {
+		defer close(out)
+		match := redisEscapePattern(s.conf.KeyPrefix) + "*"
+		now := MillisecondNow()
+		var cursor uint64
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout)
+			keys, next, err := s.client.Scan(ctx, cursor, match, redisBatchSize).Result()
+			if err != nil {
+				cancel()
+				s.conf.Logger.WithError(err).Error("while scanning rate limits in redis")
+				return
+			}
+
+			var values []interface{}
+			if len(keys) != 0 {
+				values, err = s.client.MGet(ctx, keys...).Result()
+				if err != nil {
+					cancel()
+					s.conf.Logger.WithError(err).Error("while loading rate limits from redis")
+					return
+				}
+			}
+			cancel()
+
+			for i, v := range values {
+				str, ok := v.(string)
+				if !ok {
+					// The key expired since we scanned it
+					continue
+				}
+				item, err := unmarshalCacheItem([]byte(str))
+				if err != nil {
+					s.conf.Logger.WithError(err).Errorf("while decoding rate limit '%s' from redis", keys[i])
+					continue
+				}
+				if item.ExpireAt < now {
+					continue
+				}
+				out <- item
+			}
+
+			if next == 0 {
+				return
+			}
+			cursor = next
+		}
+	}()
+	return out, nil
+}
+
+// Save writes every rate limit from the channel to redis
+func (s *RedisStore) Save(in chan *CacheItem) error {
+	var errs []string
+	pipe := s.client.Pipeline()
+
+	exec := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout)
+		defer cancel()
+		if _, err := pipe.Exec(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	for item := range in {
+		ttl := s.ttl(item)
+		if ttl <= 0 {
+			continue
+		}
+
+		b, err := marshalCacheItem(item)
+		if err != nil {
+			s.conf.Logger.WithError(err).Errorf("while encoding rate limit '%s'", item.Key)
+			continue
+		}
+		pipe.Set(context.Background(), s.conf.KeyPrefix+item.Key, b, ttl)
+
+		if pipe.Len() >= redisBatchSize {
+			exec()
+		}
+	}
+	if pipe.Len() != 0 {
+		exec()
+	}
+
+	if len(errs) != 0 {
+		return errors.Errorf("while saving rate limits to redis: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// SaveChanges writes the rate limits which changed since the last checkpoint to redis. Rate limits
+// removed from the cache are left to expire from redis on their own.
+func (s *RedisStore) SaveChanges(in chan *CacheItem) error {
+	return s.Save(in)
+}
+
+// Close closes the connection to redis
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// ttl returns how long until the rate limit expires
+func (s *RedisStore) ttl(item *CacheItem) time.Duration {
+	return time.Duration(item.ExpireAt-MillisecondNow()) * time.Millisecond
+}
+
+// redisEscapePattern escapes the characters with special meaning in a redis glob pattern
+func redisEscapePattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
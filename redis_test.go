@@ -0,0 +1,288 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisStore(t *testing.T, mr *miniredis.Miniredis) *gubernator.RedisStore {
+	store, err := gubernator.NewRedisStore(gubernator.RedisConfig{Address: mr.Addr()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newRedisStore(t, mr)
+	ctx := context.Background()
+	expireAt := gubernator.MillisecondNow() + gubernator.Minute
+
+	for _, tt := range []struct {
+		algorithm gubernator.Algorithm
+		value     interface{}
+	}{
+		{
+			algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+			value: &gubernator.TokenBucketItem{
+				Status:    gubernator.Status_OVER_LIMIT,
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Remaining: 0,
+				CreatedAt: 1234,
+			},
+		},
+		{
+			algorithm: gubernator.Algorithm_LEAKY_BUCKET,
+			value: &gubernator.LeakyBucketItem{
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Remaining: 2.5,
+				UpdatedAt: 1234,
+				Burst:     20,
+			},
+		},
+		{
+			algorithm: gubernator.Algorithm_SLIDING_WINDOW,
+			value: &gubernator.SlidingWindowItem{
+				Limit:       10,
+				Duration:    gubernator.Minute,
+				WindowStart: 1234,
+				Current:     3,
+				Previous:    7,
+			},
+		},
+		{
+			algorithm: gubernator.Algorithm_GCRA,
+			value: &gubernator.GCRAItem{
+				TAT: 1234,
+			},
+		},
+		{
+			algorithm: gubernator.Algorithm_CONTINUOUS_TOKEN_BUCKET,
+			value: &gubernator.ContinuousTokenBucketItem{
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Burst:     20,
+				Tokens:    2.5,
+				UpdatedAt: 1234,
+			},
+		},
+		{
+			algorithm: gubernator.Algorithm_CONCURRENCY,
+			value: &gubernator.ConcurrencyItem{
+				Limit:  10,
+				Leases: []gubernator.ConcurrencyLease{{ExpireAt: expireAt, Count: 2}},
+			},
+		},
+	} {
+		t.Run(tt.algorithm.String(), func(t *testing.T) {
+			req := &gubernator.RateLimitReq{
+				Name:      "test_redis_store",
+				UniqueKey: tt.algorithm.String(),
+				Algorithm: tt.algorithm,
+			}
+
+			require.NoError(t, store.OnChange(ctx, req, &gubernator.CacheItem{
+				Algorithm: tt.algorithm,
+				Key:       req.HashKey(),
+				Value:     tt.value,
+				ExpireAt:  expireAt,
+			}))
+			assert.True(t, mr.Exists("gubernator:"+req.HashKey()))
+			ttl := mr.TTL("gubernator:" + req.HashKey())
+			assert.True(t, ttl > 0 && ttl <= time.Minute, "unexpected ttl %s", ttl)
+
+			item, ok, err := store.Get(ctx, req)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, tt.algorithm, item.Algorithm)
+			assert.Equal(t, req.HashKey(), item.Key)
+			assert.Equal(t, expireAt, item.ExpireAt)
+			assert.Equal(t, tt.value, item.Value)
+
+			require.NoError(t, store.Remove(ctx, req.HashKey()))
+			assert.False(t, mr.Exists("gubernator:"+req.HashKey()))
+			_, ok, err = store.Get(ctx, req)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{Name: "test_redis_store", UniqueKey: "expired"}
+		require.NoError(t, store.OnChange(ctx, req, &gubernator.CacheItem{
+			Key:      req.HashKey(),
+			Value:    &gubernator.TokenBucketItem{Limit: 10},
+			ExpireAt: gubernator.MillisecondNow() - 1,
+		}))
+		assert.False(t, mr.Exists("gubernator:"+req.HashKey()))
+
+		require.NoError(t, store.OnChange(ctx, req, &gubernator.CacheItem{
+			Key:      req.HashKey(),
+			Value:    &gubernator.TokenBucketItem{Limit: 10},
+			ExpireAt: gubernator.MillisecondNow() + gubernator.Second,
+		}))
+		mr.FastForward(time.Second * 2)
+		_, ok, err := store.Get(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid item", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{Name: "test_redis_store", UniqueKey: "invalid"}
+		require.NoError(t, mr.Set("gubernator:"+req.HashKey(), "not json"))
+		_, ok, err := store.Get(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		keep := &gubernator.RateLimitReq{Name: "test_redis_store", UniqueKey: "batch:keep"}
+		remove := &gubernator.RateLimitReq{Name: "test_redis_store", UniqueKey: "batch:remove"}
+		require.NoError(t, store.OnChange(ctx, remove, &gubernator.CacheItem{
+			Key:      remove.HashKey(),
+			Value:    &gubernator.TokenBucketItem{Limit: 10},
+			ExpireAt: expireAt,
+		}))
+
+		item := &gubernator.CacheItem{
+			Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+			Key:       keep.HashKey(),
+			Value:     &gubernator.TokenBucketItem{Limit: 10, Remaining: 5},
+			ExpireAt:  expireAt,
+		}
+		require.NoError(t, store.WriteBatch(ctx, []gubernator.StoreChange{
+			{Key: keep.HashKey(), Req: keep, Item: item},
+			{Key: remove.HashKey()},
+		}))
+
+		got, ok, err := store.Get(ctx, keep)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, item, got)
+		_, ok, err = store.Get(ctx, remove)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		store := newRedisStore(t, mr)
+		mr.SetError("LOADING redis is loading the dataset in memory")
+
+		req := &gubernator.RateLimitReq{Name: "test_redis_store", UniqueKey: "unavailable"}
+		_, _, err := store.Get(ctx, req)
+		assert.Error(t, err)
+		assert.Error(t, store.Remove(ctx, req.HashKey()))
+	})
+}
+
+func TestRedisStoreLoader(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newRedisStore(t, mr)
+	expireAt := gubernator.MillisecondNow() + gubernator.Minute
+
+	// Enough items to span more than a single batch
+	const count = 1200
+	in := make(chan *gubernator.CacheItem, count)
+	for i := 0; i < count; i++ {
+		in <- &gubernator.CacheItem{
+			Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+			Key:       fmt.Sprintf("test_redis_loader_account:%d", i),
+			Value:     &gubernator.TokenBucketItem{Limit: 10, Remaining: int64(i % 10)},
+			ExpireAt:  expireAt,
+		}
+	}
+	close(in)
+	require.NoError(t, store.Save(in))
+
+	// Keys outside our prefix should not be loaded
+	require.NoError(t, mr.Set("other:key", "value"))
+
+	out, err := store.Load()
+	require.NoError(t, err)
+
+	loaded := make(map[string]*gubernator.CacheItem)
+	for item := range out {
+		loaded[item.Key] = item
+	}
+	require.Len(t, loaded, count)
+
+	item := loaded["test_redis_loader_account:13"]
+	require.NotNil(t, item)
+	assert.Equal(t, expireAt, item.ExpireAt)
+	assert.Equal(t, &gubernator.TokenBucketItem{Limit: 10, Remaining: 3}, item.Value)
+}
+
+func TestRedisStoreConnectError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+
+	_, err := gubernator.NewRedisStore(gubernator.RedisConfig{Address: addr})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "while connecting to redis")
+
+	_, err = gubernator.NewRedisStore(gubernator.RedisConfig{})
+	require.Error(t, err)
+}
+
+func TestRedisStorePersistsAcrossServers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	req := &gubernator.RateLimitReq{
+		Name:      "test_redis_persist",
+		UniqueKey: "account:1234",
+		Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+		Duration:  gubernator.Minute,
+		Limit:     10,
+		Hits:      4,
+	}
+
+	hit := func() *gubernator.RateLimitResp {
+		store := newRedisStore(t, mr)
+		srv := newV1Server(t, "localhost:0", gubernator.Config{StoreV2: store})
+		defer srv.Close()
+
+		client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+		require.NoError(t, err)
+
+		resp, err := client.GetRateLimits(ctx, &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{req},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 1)
+		require.Equal(t, "", resp.Responses[0].Error)
+		return resp.Responses[0]
+	}
+
+	assert.Equal(t, int64(6), hit().Remaining)
+
+	// A new server with an empty cache should read the rate limit back from redis
+	assert.Equal(t, int64(2), hit().Remaining)
+}
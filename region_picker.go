@@ -31,14 +31,11 @@ type RegionPicker struct {
 
 	// A map of all the pickers by region
 	regions map[string]PeerPicker
-	// The implementation of picker we will use for each region
-	reqQueue chan *RateLimitReq
 }
 
 func NewRegionPicker(fn HashString64) *RegionPicker {
 	rp := &RegionPicker{
 		regions:                  make(map[string]PeerPicker),
-		reqQueue:                 make(chan *RateLimitReq),
 		ReplicatedConsistentHash: NewReplicatedConsistentHash(fn, defaultReplicas),
 	}
 	return rp
@@ -48,7 +45,6 @@ func (rp *RegionPicker) New() RegionPeerPicker {
 	hash := rp.ReplicatedConsistentHash.New().(*ReplicatedConsistentHash)
 	return &RegionPicker{
 		regions:                  make(map[string]PeerPicker),
-		reqQueue:                 make(chan *RateLimitReq),
 		ReplicatedConsistentHash: hash,
 	}
 }
@@ -117,3 +117,35 @@ func (ch *ReplicatedConsistentHash) Get(key string) (*PeerClient, error) {
 
 	return ch.peerKeys[idx].peer, nil
 }
+
+// Given a key, return up to n distinct peers starting with the peer the key is assigned to,
+// followed by the peers which succeed it on the ring
+func (ch *ReplicatedConsistentHash) GetN(key string, n int) ([]*PeerClient, error) {
+	if ch.Size() == 0 {
+		return nil, errors.New("unable to pick a peer; pool is empty")
+	}
+	if n > ch.Size() {
+		n = ch.Size()
+	}
+	hash := ch.hashFunc(key)
+
+	idx := sort.Search(len(ch.peerKeys), func(i int) bool { return ch.peerKeys[i].hash >= hash })
+
+	results := make([]*PeerClient, 0, n)
+	for i := 0; len(results) < n; i++ {
+		peer := ch.peerKeys[(idx+i)%len(ch.peerKeys)].peer
+		if !containsPeer(results, peer) {
+			results = append(results, peer)
+		}
+	}
+	return results, nil
+}
+
+func containsPeer(peers []*PeerClient, peer *PeerClient) bool {
+	for _, p := range peers {
+		if p == peer {
+			return true
+		}
+	}
+	return false
+}
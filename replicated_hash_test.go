@@ -53,6 +53,44 @@ func TestReplicatedConsistentHash(t *testing.T) {
 		}
 	})
 
+	t.Run("GetN", func(t *testing.T) {
+		hash := NewReplicatedConsistentHash(nil, defaultReplicas)
+		for _, h := range hosts {
+			hash.Add(&PeerClient{conf: PeerConfig{Info: PeerInfo{GRPCAddress: h}}})
+		}
+
+		for _, key := range []string{"account:1", "account:2", "account:3"} {
+			owner, err := hash.Get(key)
+			assert.NoError(t, err)
+
+			peers, err := hash.GetN(key, 2)
+			assert.NoError(t, err)
+			assert.Len(t, peers, 2)
+			assert.Equal(t, owner, peers[0])
+			assert.NotEqual(t, peers[0], peers[1])
+
+			// Never returns more peers than are in the ring
+			peers, err = hash.GetN(key, 5)
+			assert.NoError(t, err)
+			assert.Len(t, peers, len(hosts))
+			assert.Equal(t, owner, peers[0])
+
+			// The successor owns the key once the owner leaves the ring
+			successors := hash.New().(*ReplicatedConsistentHash)
+			for _, p := range hash.Peers() {
+				if p != owner {
+					successors.Add(p)
+				}
+			}
+			next, err := successors.Get(key)
+			assert.NoError(t, err)
+			assert.Equal(t, peers[1], next)
+		}
+
+		_, err := NewReplicatedConsistentHash(nil, defaultReplicas).GetN("account:1", 2)
+		assert.Error(t, err)
+	})
+
 	t.Run("distribution", func(t *testing.T) {
 		strings := make([]string, 10000)
 		for i := range strings {
@@ -0,0 +1,247 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replicationManager sends the state of the rate limits we own to the successors of this instance
+// on the ring. When the owner fails, requests fail over to the first successor which holds a replica,
+// and once the owner leaves the ring the successor becomes the owner with near current counts.
+type replicationManager struct {
+	updateQueue chan string
+	wg          syncutil.WaitGroup
+	conf        BehaviorConfig
+	log         FieldLogger
+	instance    *V1Instance
+
+	metricReplicationDuration prometheus.Summary
+	metricReplicationCounter  *prometheus.CounterVec
+	metricFailoverCounter     prometheus.Counter
+}
+
+func newReplicationManager(conf BehaviorConfig, instance *V1Instance) *replicationManager {
+	rm := replicationManager{
+		log:         instance.log,
+		updateQueue: make(chan string, maxBatchSize),
+		instance:    instance,
+		conf:        conf,
+		metricReplicationDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:       "gubernator_replication_duration",
+			Help:       "The duration of sending rate limits to their replicas in seconds.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+		}),
+		metricReplicationCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gubernator_replication_counter",
+			Help: "The count of rate limits replicated to successor peers.  Label \"result\" may be \"sent\" or \"failed\" for rate limits sent to a replica, \"dropped\" for updates discarded because the queue was full, or \"accepted\" or \"rejected\" for replicas received from the owner.",
+		}, []string{"result"}),
+		metricFailoverCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gubernator_replication_failover_counter",
+			Help: "The count of rate limit requests which failed over to a replica because the owner was unreachable.",
+		}),
+	}
+	if conf.ReplicationFactor > 0 {
+		rm.runUpdates()
+	}
+	return &rm
+}
+
+// QueueUpdate queues the rate limit to be sent to its replicas. The update is dropped if the
+// queue is full, such that a slow replica never stalls the requests of clients; the replica
+// catches up with the next update of the rate limit.
+func (rm *replicationManager) QueueUpdate(r *RateLimitReq) {
+	// GLOBAL rate limits are already broadcast to every peer
+	if rm.conf.ReplicationFactor <= 0 || HasBehavior(r.Behavior, Behavior_GLOBAL) {
+		return
+	}
+	select {
+	case rm.updateQueue <- r.HashKey():
+	default:
+		rm.metricReplicationCounter.WithLabelValues("dropped").Inc()
+	}
+}
+
+// runUpdates collects the keys of changed rate limits in a forever loop, and sends their
+// current state to the replicas. The updates are sent both when the batch limit is hit
+// and in a periodic frequency determined by ReplicationSyncWait.
+func (rm *replicationManager) runUpdates() {
+	var interval = NewInterval(rm.conf.ReplicationSyncWait)
+	updates := make(map[string]struct{})
+
+	rm.wg.Until(func(done chan struct{}) bool {
+		select {
+		case key := <-rm.updateQueue:
+			updates[key] = struct{}{}
+
+			if len(updates) >= maxBatchSize {
+				rm.sendUpdates(updates)
+				updates = make(map[string]struct{})
+				return true
+			}
+
+			// If this is our first queued update since last send
+			// queue the next interval
+			if len(updates) == 1 {
+				interval.Next()
+			}
+
+		case <-interval.C:
+			if len(updates) != 0 {
+				rm.sendUpdates(updates)
+				updates = make(map[string]struct{})
+			}
+		case <-done:
+			interval.Stop()
+			return false
+		}
+		return true
+	})
+}
+
+// sendUpdates sends the current state of each rate limit we still own to its replicas
+func (rm *replicationManager) sendUpdates(updates map[string]struct{}) {
+	defer prometheus.NewTimer(rm.metricReplicationDuration).ObserveDuration()
+	ctx, cancel := context.WithTimeout(context.Background(), rm.conf.ReplicationTimeout)
+	defer cancel()
+
+	type pair struct {
+		client *PeerClient
+		req    ReplicatePeerRateLimitsReq
+	}
+	peerRequests := make(map[string]*pair)
+	now := MillisecondNow()
+
+	for key := range updates {
+		peers := rm.instance.getReplicaPeers(key)
+		if len(peers) < 2 || !peers[0].Info().IsOwner {
+			continue
+		}
+		item, ok, err := rm.instance.workerPool.GetCacheItem(ctx, key)
+		if err != nil {
+			rm.log.WithError(err).Errorf("while getting rate limit '%s' to replicate", key)
+			continue
+		}
+		if !ok || item.ExpireAt <= now {
+			continue
+		}
+		b, err := appendSnapshotItem(nil, item)
+		if err != nil {
+			rm.log.WithError(err).Errorf("while encoding rate limit '%s' to replicate", key)
+			continue
+		}
+
+		for _, peer := range peers[1:] {
+			p, ok := peerRequests[peer.Info().GRPCAddress]
+			if !ok {
+				p = &pair{client: peer, req: ReplicatePeerRateLimitsReq{Version: uint32(snapshotVersion)}}
+				peerRequests[peer.Info().GRPCAddress] = p
+			}
+			p.req.Items = append(p.req.Items, b)
+		}
+	}
+
+	fan := syncutil.NewFanOut(rm.conf.GlobalPeerRequestsConcurrency)
+	for _, p := range peerRequests {
+		fan.Run(func(in interface{}) error {
+			p := in.(*pair)
+			if _, err := p.client.ReplicatePeerRateLimits(ctx, &p.req); err != nil {
+				rm.metricReplicationCounter.WithLabelValues("failed").Add(float64(len(p.req.Items)))
+				if !errors.Is(err, context.Canceled) {
+					rm.log.WithError(err).
+						Errorf("while replicating rate limits to '%s'", p.client.Info().GRPCAddress)
+				}
+				return nil
+			}
+			rm.metricReplicationCounter.WithLabelValues("sent").Add(float64(len(p.req.Items)))
+			return nil
+		}, p)
+	}
+	fan.Wait()
+}
+
+// receive stores the replicas sent by the owner in our cache, replacing any previous replica.
+// Replicas of rate limits we believe we own are rejected, as our state is authoritative.
+func (rm *replicationManager) receive(ctx context.Context, r *ReplicatePeerRateLimitsReq) (*ReplicatePeerRateLimitsResp, error) {
+	if len(r.Items) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'ReplicatePeerRateLimitsReq.items' list too large; max size is '%d'", maxBatchSize)
+	}
+	if r.Version != uint32(snapshotVersion) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"unsupported 'ReplicatePeerRateLimitsReq.version' '%d'; expected '%d'", r.Version, snapshotVersion)
+	}
+	if rm.instance.IsDraining() {
+		return nil, status.Error(codes.Unavailable, "instance is draining")
+	}
+
+	resp := &ReplicatePeerRateLimitsResp{}
+	var rejected int
+	for _, b := range r.Items {
+		item, err := decodeSnapshotItem(b)
+		if err != nil {
+			rejected++
+			rm.log.WithError(err).Error("while decoding rate limit replicated by peer")
+			continue
+		}
+		if item.ExpireAt <= MillisecondNow() {
+			rejected++
+			continue
+		}
+		owner, err := rm.instance.GetPeer(ctx, item.Key)
+		if err != nil || owner.Info().IsOwner {
+			rejected++
+			continue
+		}
+
+		if err := rm.instance.workerPool.AddCacheItem(ctx, item.Key, item); err != nil {
+			return nil, errors.Wrap(err, "Error in workerPool.AddCacheItem")
+		}
+		resp.Accepted++
+	}
+
+	rm.metricReplicationCounter.WithLabelValues("accepted").Add(float64(resp.Accepted))
+	rm.metricReplicationCounter.WithLabelValues("rejected").Add(float64(rejected))
+	return resp, nil
+}
+
+// failoverPeer returns the replica which should handle requests for the key after the peer
+// failed, or nil if there is none.
+func (rm *replicationManager) failoverPeer(key string, failed *PeerClient, err error) *PeerClient {
+	if rm.conf.ReplicationFactor <= 0 || status.Code(err) != codes.Unavailable {
+		return nil
+	}
+
+	peers := rm.instance.getReplicaPeers(key)
+	for i, p := range peers {
+		if p == failed && i+1 < len(peers) {
+			rm.metricFailoverCounter.Inc()
+			return peers[i+1]
+		}
+	}
+	return nil
+}
+
+func (rm *replicationManager) Close() {
+	rm.wg.Stop()
+}
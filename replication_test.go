@@ -0,0 +1,141 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplication(t *testing.T) {
+	conf := gubernator.Config{
+		Behaviors: gubernator.BehaviorConfig{
+			ReplicationFactor:   1,
+			ReplicationSyncWait: time.Millisecond * 10,
+		},
+	}
+	servers := []*v1Server{
+		newV1Server(t, "localhost:0", conf),
+		newV1Server(t, "localhost:0", conf),
+		newV1Server(t, "localhost:0", conf),
+	}
+	defer func() {
+		for _, srv := range servers {
+			_ = srv.Close()
+		}
+	}()
+
+	setPeers := func(servers []*v1Server) {
+		for _, srv := range servers {
+			var peers []gubernator.PeerInfo
+			for _, p := range servers {
+				peers = append(peers, gubernator.PeerInfo{GRPCAddress: p.listener.Addr().String(), IsOwner: p == srv})
+			}
+			srv.srv.SetPeers(peers)
+		}
+	}
+	setPeers(servers)
+
+	const count = 20
+	for i := 0; i < count; i++ {
+		servers[0].hit(t, "test_replication", fmt.Sprintf("account:%d", i), 3)
+	}
+
+	// Each owner sends every rate limit to a single successor
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		var sent, accepted float64
+		for _, srv := range servers {
+			sent += collectorValue(t, srv.srv, "gubernator_replication_counter", "sent")
+			accepted += collectorValue(t, srv.srv, "gubernator_replication_counter", "accepted")
+		}
+		assert.Equal(t, float64(count), sent)
+		assert.Equal(t, float64(count), accepted)
+	})
+
+	// Stop the owner of the first rate limit
+	owner, err := servers[0].srv.GetPeer(context.Background(), "test_replication_account:0")
+	require.NoError(t, err)
+	var remaining []*v1Server
+	for _, srv := range servers {
+		if srv.listener.Addr().String() == owner.Info().GRPCAddress {
+			require.NoError(t, srv.Close())
+			continue
+		}
+		remaining = append(remaining, srv)
+	}
+	require.Len(t, remaining, 2)
+
+	// While the owner is unreachable, requests fail over to the replica
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("account:%d", i)
+		assert.Equal(t, int64(6), remaining[0].hit(t, "test_replication", key, 1).Remaining, key)
+	}
+	assert.NotZero(t, collectorValue(t, remaining[0].srv, "gubernator_replication_failover_counter"))
+
+	// Once the owner leaves the ring, the replica becomes the owner
+	setPeers(remaining)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("account:%d", i)
+		assert.Equal(t, int64(5), remaining[1].hit(t, "test_replication", key, 1).Remaining, key)
+	}
+}
+
+func TestReplicationVersion(t *testing.T) {
+	a := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	peer, err := gubernator.NewPeerClient(gubernator.PeerConfig{
+		Info: gubernator.PeerInfo{GRPCAddress: a.listener.Addr().String()},
+	})
+	require.NoError(t, err)
+	defer peer.Shutdown(ctx)
+
+	// Replicas encoded in a snapshot format we do not understand are rejected
+	_, err = peer.ReplicatePeerRateLimits(ctx, &gubernator.ReplicatePeerRateLimitsReq{Version: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported 'ReplicatePeerRateLimitsReq.version'")
+
+	resp, err := peer.ReplicatePeerRateLimits(ctx, &gubernator.ReplicatePeerRateLimitsReq{Version: 1})
+	require.NoError(t, err)
+	assert.Zero(t, resp.Accepted)
+}
+
+func TestReplicationDisabled(t *testing.T) {
+	a := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer a.Close()
+	b := newV1Server(t, "localhost:0", gubernator.Config{})
+	defer b.Close()
+
+	addrA, addrB := a.listener.Addr().String(), b.listener.Addr().String()
+	a.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA, IsOwner: true}, {GRPCAddress: addrB}})
+	b.srv.SetPeers([]gubernator.PeerInfo{{GRPCAddress: addrA}, {GRPCAddress: addrB, IsOwner: true}})
+
+	for i := 0; i < 10; i++ {
+		a.hit(t, "test_replication", fmt.Sprintf("account:%d", i), 1)
+	}
+	assert.Zero(t, collectorValue(t, a.srv, "gubernator_replication_counter", "sent"))
+	assert.Zero(t, collectorValue(t, b.srv, "gubernator_replication_counter", "sent"))
+}
@@ -0,0 +1,391 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// errAtomicNotApplied is reported for the rate limits of an atomic group which were not applied
+// because another rate limit in the group failed
+const errAtomicNotApplied = "not applied; another rate limit in the atomic group failed"
+
+// reservationManager holds the hits applied to the rate limits we own on behalf of an atomic
+// GetRateLimitsReq, until the peer which received the request commits or aborts them. Aborted
+// reservations restore each rate limit to its state before the hits were applied. Reservations
+// which are neither committed nor aborted within ReservationTimeout are aborted, as the client
+// never learned whether its hits were applied.
+type reservationManager struct {
+	wg       syncutil.WaitGroup
+	log      FieldLogger
+	instance *V1Instance
+	timeout  clock.Duration
+
+	mutex        sync.Mutex
+	reservations map[string]*reservation
+
+	metricReservationCounter *prometheus.CounterVec
+}
+
+type reservation struct {
+	// The rate limits changed by the reservation, which are restored if it is aborted
+	items    []reservedItem
+	expireAt clock.Time
+}
+
+type reservedItem struct {
+	req *RateLimitReq
+	// Copies of the cached rate limit before and after the hits were applied
+	before, after *CacheItem
+	// If the hits were accepted, and so are refunded if the rate limit cannot be restored
+	accepted bool
+}
+
+func newReservationManager(conf BehaviorConfig, instance *V1Instance) *reservationManager {
+	rm := reservationManager{
+		log:          instance.log,
+		instance:     instance,
+		timeout:      conf.ReservationTimeout,
+		reservations: make(map[string]*reservation),
+		metricReservationCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gubernator_reservation_counter",
+			Help: "The count of reservations held for atomic rate limit requests.  Label \"result\" may be \"committed\", \"aborted\" or \"expired\".",
+		}, []string{"result"}),
+	}
+	rm.runExpiration()
+	return &rm
+}
+
+// reserve applies the hits of the requests, and holds the hits which were accepted until
+// the reservation is committed or aborted.
+func (rm *reservationManager) reserve(ctx context.Context, r *ReservePeerRateLimitsReq) (*ReservePeerRateLimitsResp, error) {
+	if len(r.Requests) > maxBatchSize {
+		return nil, status.Errorf(codes.OutOfRange,
+			"'ReservePeerRateLimitsReq.requests' list too large; max size is '%d'", maxBatchSize)
+	}
+	if r.ReservationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "field 'reservation_id' cannot be empty")
+	}
+
+	rm.mutex.Lock()
+	_, exists := rm.reservations[r.ReservationId]
+	if !exists {
+		// Claim the id before applying any hits, such that an abort which arrives while the hits
+		// are applied refunds them once they are added to the reservation.
+		rm.reservations[r.ReservationId] = &reservation{expireAt: clock.Now().Add(rm.timeout)}
+	}
+	rm.mutex.Unlock()
+	if exists {
+		return nil, status.Errorf(codes.AlreadyExists, "reservation '%s' already exists", r.ReservationId)
+	}
+
+	resp := &ReservePeerRateLimitsResp{RateLimits: make([]*RateLimitResp, len(r.Requests))}
+	var items []reservedItem
+	for i, req := range r.Requests {
+		if HasBehavior(req.Behavior, Behavior_GLOBAL) {
+			resp.RateLimits[i] = &RateLimitResp{Error: "behavior GLOBAL is not supported by atomic requests"}
+			continue
+		}
+
+		rl, before, after, err := rm.instance.workerPool.ReserveRateLimit(ctx, req)
+		if err != nil {
+			resp.RateLimits[i] = &RateLimitResp{Error: errors.Wrap(err, "during workerPool.ReserveRateLimit").Error()}
+			continue
+		}
+		rm.instance.queueLocalUpdate(req, rl)
+		resp.RateLimits[i] = rl
+		if req.Hits != 0 {
+			items = append(items, reservedItem{
+				req:      req,
+				before:   before,
+				after:    after,
+				accepted: rl.Status == Status_UNDER_LIMIT && req.Hits > 0,
+			})
+		}
+	}
+
+	rm.mutex.Lock()
+	res, ok := rm.reservations[r.ReservationId]
+	if ok {
+		res.items = items
+	}
+	rm.mutex.Unlock()
+
+	// The reservation was aborted or expired while we applied the hits
+	if !ok {
+		rm.restore(ctx, items)
+	}
+	return resp, nil
+}
+
+// finish commits or aborts the reservation. Finishing a reservation which does not exist does
+// nothing, as the reservation either expired or the hits were never reserved.
+func (rm *reservationManager) finish(ctx context.Context, r *CommitPeerRateLimitsReq) (*CommitPeerRateLimitsResp, error) {
+	rm.mutex.Lock()
+	res, ok := rm.reservations[r.ReservationId]
+	delete(rm.reservations, r.ReservationId)
+	rm.mutex.Unlock()
+
+	if !ok {
+		return &CommitPeerRateLimitsResp{}, nil
+	}
+
+	if r.Abort {
+		rm.restore(ctx, res.items)
+		rm.metricReservationCounter.WithLabelValues("aborted").Inc()
+		return &CommitPeerRateLimitsResp{}, nil
+	}
+	rm.metricReservationCounter.WithLabelValues("committed").Inc()
+	return &CommitPeerRateLimitsResp{}, nil
+}
+
+// restore returns each rate limit to its state before the hits were reserved. A rate limit which
+// was changed by another request since is refunded the accepted hits instead, such that the hits
+// of the other request are kept.
+func (rm *reservationManager) restore(ctx context.Context, items []reservedItem) {
+	for _, item := range items {
+		refund := proto.Clone(item.req).(*RateLimitReq)
+		refund.Hits = -item.req.Hits
+		SetBehavior(&refund.Behavior, Behavior_RESET_REMAINING, false)
+		SetBehavior(&refund.Behavior, Behavior_DRAIN_OVER_LIMIT, false)
+
+		restored, err := rm.instance.workerPool.RestoreCacheItem(ctx, item.req, item.before, item.after)
+		if err != nil {
+			rm.log.WithError(err).Errorf("while restoring rate limit '%s'", item.req.HashKey())
+			continue
+		}
+		if restored {
+			// The hits sent to the other regions cannot be restored, so they are refunded
+			rm.instance.replication.QueueUpdate(item.req)
+			if HasBehavior(item.req.Behavior, Behavior_MULTI_REGION) {
+				rm.instance.multiRegion.QueueHit(refund)
+			}
+			continue
+		}
+
+		if !item.accepted {
+			continue
+		}
+		if _, err := rm.instance.getLocalRateLimit(ctx, refund); err != nil {
+			rm.log.WithError(err).Errorf("while refunding hits of rate limit '%s'", item.req.HashKey())
+		}
+	}
+}
+
+// runExpiration refunds the reservations which were neither committed nor aborted in time
+func (rm *reservationManager) runExpiration() {
+	tick := clock.NewTicker(rm.timeout / 2)
+	rm.wg.Until(func(done chan struct{}) bool {
+		select {
+		case <-tick.C():
+			var expired []*reservation
+			now := clock.Now()
+			rm.mutex.Lock()
+			for id, res := range rm.reservations {
+				if now.After(res.expireAt) {
+					expired = append(expired, res)
+					delete(rm.reservations, id)
+				}
+			}
+			rm.mutex.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), rm.timeout)
+			for _, res := range expired {
+				rm.restore(ctx, res.items)
+			}
+			cancel()
+			rm.metricReservationCounter.WithLabelValues("expired").Add(float64(len(expired)))
+		case <-done:
+			tick.Stop()
+			return false
+		}
+		return true
+	})
+}
+
+func (rm *reservationManager) Close() {
+	rm.wg.Stop()
+}
+
+// reservationGroup holds the requests of an atomic request which are owned by the same peer
+type reservationGroup struct {
+	peer *PeerClient
+	idx  []int
+	req  ReservePeerRateLimitsReq
+}
+
+// getAtomicRateLimits applies the hits of the requests only if every rate limit is under the limit.
+// The hits are reserved with the owner of each rate limit, then committed if every rate limit
+// accepted its hits, or aborted otherwise.
+func (s *V1Instance) getAtomicRateLimits(ctx context.Context, requests []*RateLimitReq) []*RateLimitResp {
+	responses := make([]*RateLimitResp, len(requests))
+	groups := make(map[*PeerClient]*reservationGroup)
+	id := RandomString(20)
+	var failed bool
+
+	for i, req := range requests {
+		if len(req.UniqueKey) == 0 {
+			metricCheckErrorCounter.WithLabelValues("Invalid request").Inc()
+			responses[i] = &RateLimitResp{Error: "field 'unique_key' cannot be empty"}
+			failed = true
+			continue
+		}
+		if len(req.Name) == 0 {
+			metricCheckErrorCounter.WithLabelValues("Invalid request").Inc()
+			responses[i] = &RateLimitResp{Error: "field 'namespace' cannot be empty"}
+			failed = true
+			continue
+		}
+		if s.conf.Policies != nil {
+			s.conf.Policies.Apply(req)
+		}
+		if s.conf.Behaviors.ForceGlobal || HasBehavior(req.Behavior, Behavior_GLOBAL) {
+			metricCheckErrorCounter.WithLabelValues("Invalid request").Inc()
+			responses[i] = &RateLimitResp{Error: "behavior GLOBAL is not supported by atomic requests"}
+			failed = true
+			continue
+		}
+
+		peer, err := s.GetPeer(ctx, req.HashKey())
+		if err != nil {
+			countError(err, "Error in GetPeer")
+			err = errors.Wrapf(err, "Error in GetPeer, looking up peer that owns rate limit '%s'", req.HashKey())
+			responses[i] = &RateLimitResp{Error: err.Error()}
+			failed = true
+			continue
+		}
+		g, ok := groups[peer]
+		if !ok {
+			g = &reservationGroup{peer: peer, req: ReservePeerRateLimitsReq{ReservationId: id}}
+			groups[peer] = g
+		}
+		g.idx = append(g.idx, i)
+		g.req.Requests = append(g.req.Requests, req)
+	}
+
+	if failed {
+		fillAtomicNotApplied(responses)
+		return responses
+	}
+
+	// Reserve the hits with the owner of each rate limit
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		wg.Add(1)
+		go func(g *reservationGroup) {
+			defer wg.Done()
+			var resp *ReservePeerRateLimitsResp
+			var err error
+			if g.peer.Info().IsOwner {
+				resp, err = s.reservation.reserve(ctx, &g.req)
+			} else {
+				resp, err = g.peer.ReservePeerRateLimits(ctx, &g.req)
+			}
+			if err == nil && len(resp.RateLimits) != len(g.idx) {
+				err = fmt.Errorf("expected %d responses from peer, got %d", len(g.idx), len(resp.RateLimits))
+			}
+			for j, i := range g.idx {
+				if err != nil {
+					responses[i] = &RateLimitResp{Error: errors.Wrap(err, "Error in ReservePeerRateLimits").Error()}
+					continue
+				}
+				responses[i] = resp.RateLimits[j]
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	abort := false
+	for i, rl := range responses {
+		if rl.Error != "" {
+			abort = true
+		}
+		// SHADOW rate limits never cause the request to be aborted
+		if rl.Status != Status_UNDER_LIMIT && !HasBehavior(requests[i].Behavior, Behavior_SHADOW) {
+			abort = true
+		}
+	}
+
+	// Commit or abort with every peer, including those which failed to reserve, as the
+	// reservation may have succeeded even though we did not receive the response.
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Behaviors.ReservationTimeout)
+	defer cancel()
+	finish := &CommitPeerRateLimitsReq{ReservationId: id, Abort: abort}
+	for _, g := range groups {
+		wg.Add(1)
+		go func(g *reservationGroup) {
+			defer wg.Done()
+			var err error
+			if g.peer.Info().IsOwner {
+				_, err = s.reservation.finish(ctx, finish)
+			} else {
+				_, err = g.peer.CommitPeerRateLimits(ctx, finish)
+			}
+			if err != nil {
+				s.log.WithError(err).WithField("peer", g.peer.Info().GRPCAddress).
+					Errorf("while finishing reservation '%s'", id)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if abort {
+		rejectAtomic(requests, responses)
+	}
+	for i, req := range requests {
+		shadowResponse(req, responses[i])
+	}
+	return responses
+}
+
+// fillAtomicNotApplied reports every rate limit without an error as not applied
+func fillAtomicNotApplied(responses []*RateLimitResp) {
+	for i, rl := range responses {
+		if rl == nil || rl.Error == "" {
+			responses[i] = &RateLimitResp{Error: errAtomicNotApplied}
+		}
+	}
+}
+
+// rejectAtomic updates the responses of an aborted atomic request, such that every rate limit
+// reports OVER_LIMIT and the hits refunded by the abort are included in the remaining hits.
+func rejectAtomic(requests []*RateLimitReq, responses []*RateLimitResp) {
+	for _, rl := range responses {
+		if rl.Error != "" {
+			fillAtomicNotApplied(responses)
+			return
+		}
+	}
+	for i, rl := range responses {
+		if rl.Status == Status_UNDER_LIMIT && requests[i].Hits > 0 {
+			rl.Remaining += requests[i].Hits
+		}
+		if !HasBehavior(requests[i].Behavior, Behavior_SHADOW) {
+			rl.Status = Status_OVER_LIMIT
+		}
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservations(t *testing.T) {
+	srv := newV1Server(t, "localhost:0", gubernator.Config{
+		Behaviors: gubernator.BehaviorConfig{
+			ReservationTimeout: time.Millisecond * 100,
+		},
+	})
+	defer srv.Close()
+	ctx := context.Background()
+
+	reserve := func(id, key string, hits int64) *gubernator.RateLimitResp {
+		resp, err := srv.srv.ReservePeerRateLimits(ctx, &gubernator.ReservePeerRateLimitsReq{
+			ReservationId: id,
+			Requests: []*gubernator.RateLimitReq{
+				{Name: "test_reservation", UniqueKey: key, Duration: gubernator.Minute, Limit: 10, Hits: hits},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.RateLimits, 1)
+		return resp.RateLimits[0]
+	}
+
+	t.Run("Committed hits are kept", func(t *testing.T) {
+		assert.Equal(t, int64(7), reserve("commit", "account:1", 3).Remaining)
+		_, err := srv.srv.CommitPeerRateLimits(ctx, &gubernator.CommitPeerRateLimitsReq{ReservationId: "commit"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), srv.hit(t, "test_reservation", "account:1", 0).Remaining)
+	})
+
+	t.Run("Aborted hits are refunded", func(t *testing.T) {
+		assert.Equal(t, int64(7), reserve("abort", "account:2", 3).Remaining)
+		_, err := srv.srv.CommitPeerRateLimits(ctx, &gubernator.CommitPeerRateLimitsReq{ReservationId: "abort", Abort: true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), srv.hit(t, "test_reservation", "account:2", 0).Remaining)
+	})
+
+	t.Run("Duplicate reservations are rejected", func(t *testing.T) {
+		reserve("duplicate", "account:3", 1)
+		_, err := srv.srv.ReservePeerRateLimits(ctx, &gubernator.ReservePeerRateLimitsReq{ReservationId: "duplicate"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Expired reservations are refunded", func(t *testing.T) {
+		assert.Equal(t, int64(7), reserve("expire", "account:4", 3).Remaining)
+		testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+			// Includes the duplicate reservation above, which was never committed
+			assert.Equal(t, float64(2), collectorValue(t, srv.srv, "gubernator_reservation_counter", "expired"))
+		})
+		assert.Equal(t, int64(10), srv.hit(t, "test_reservation", "account:4", 0).Remaining)
+
+		// Committing after the reservation expired does nothing
+		_, err := srv.srv.CommitPeerRateLimits(ctx, &gubernator.CommitPeerRateLimitsReq{ReservationId: "expire"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), srv.hit(t, "test_reservation", "account:4", 0).Remaining)
+	})
+
+	t.Run("Changed rate limits are refunded", func(t *testing.T) {
+		assert.Equal(t, int64(7), reserve("changed", "account:5", 3).Remaining)
+		assert.Equal(t, int64(6), srv.hit(t, "test_reservation", "account:5", 1).Remaining)
+		_, err := srv.srv.CommitPeerRateLimits(ctx, &gubernator.CommitPeerRateLimitsReq{ReservationId: "changed", Abort: true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), srv.hit(t, "test_reservation", "account:5", 0).Remaining)
+	})
+
+	t.Run("Aborted hits are restored after the rate limit reset", func(t *testing.T) {
+		defer clock.Freeze(clock.Now()).Unfreeze()
+
+		assert.Equal(t, int64(9), srv.hit(t, "test_reservation", "account:6", 1).Remaining)
+		assert.Equal(t, int64(6), reserve("reset", "account:6", 3).Remaining)
+		clock.Advance(clock.Minute + clock.Millisecond)
+		_, err := srv.srv.CommitPeerRateLimits(ctx, &gubernator.CommitPeerRateLimitsReq{ReservationId: "reset", Abort: true})
+		require.NoError(t, err)
+		// Refunding the hits would have raised the remaining hits above the limit
+		assert.Equal(t, int64(10), srv.hit(t, "test_reservation", "account:6", 0).Remaining)
+	})
+
+	t.Run("Aborted leases do not release other leases", func(t *testing.T) {
+		defer clock.Freeze(clock.Now()).Unfreeze()
+		lease := func(hits int64) *gubernator.RateLimitReq {
+			return &gubernator.RateLimitReq{
+				Name:      "test_reservation",
+				UniqueKey: "account:7",
+				Algorithm: gubernator.Algorithm_CONCURRENCY,
+				Duration:  gubernator.Minute,
+				Limit:     10,
+				Hits:      hits,
+			}
+		}
+
+		first, err := srv.srv.GetRateLimits(ctx, &gubernator.GetRateLimitsReq{Requests: []*gubernator.RateLimitReq{lease(1)}})
+		require.NoError(t, err)
+		clock.Advance(clock.Second)
+		_, err = srv.srv.ReservePeerRateLimits(ctx, &gubernator.ReservePeerRateLimitsReq{
+			ReservationId: "lease",
+			Requests:      []*gubernator.RateLimitReq{lease(2)},
+		})
+		require.NoError(t, err)
+		_, err = srv.srv.CommitPeerRateLimits(ctx, &gubernator.CommitPeerRateLimitsReq{ReservationId: "lease", Abort: true})
+		require.NoError(t, err)
+
+		// The first lease is still held
+		resp, err := srv.srv.GetRateLimits(ctx, &gubernator.GetRateLimitsReq{Requests: []*gubernator.RateLimitReq{lease(0)}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), resp.Responses[0].Remaining)
+		assert.Equal(t, first.Responses[0].ResetTime, resp.Responses[0].ResetTime)
+	})
+
+	assert.Equal(t, float64(1), collectorValue(t, srv.srv, "gubernator_reservation_counter", "committed"))
+	assert.Equal(t, float64(4), collectorValue(t, srv.srv, "gubernator_reservation_counter", "aborted"))
+}
+
+func TestAtomicAcrossPeers(t *testing.T) {
+	servers := []*v1Server{
+		newV1Server(t, "localhost:0", gubernator.Config{}),
+		newV1Server(t, "localhost:0", gubernator.Config{}),
+		newV1Server(t, "localhost:0", gubernator.Config{}),
+	}
+	defer func() {
+		for _, srv := range servers {
+			_ = srv.Close()
+		}
+	}()
+	for _, srv := range servers {
+		var peers []gubernator.PeerInfo
+		for _, p := range servers {
+			peers = append(peers, gubernator.PeerInfo{GRPCAddress: p.listener.Addr().String(), IsOwner: p == srv})
+		}
+		srv.srv.SetPeers(peers)
+	}
+
+	// Find a key owned by each peer
+	owners := make(map[string]*v1Server)
+	keys := make(map[string]string)
+	for i := 0; len(keys) < len(servers); i++ {
+		key := fmt.Sprintf("account:%d", i)
+		peer, err := servers[0].srv.GetPeer(context.Background(), "test_reservation_"+key)
+		require.NoError(t, err)
+		addr := peer.Info().GRPCAddress
+		if _, ok := keys[addr]; ok {
+			continue
+		}
+		keys[addr] = key
+		for _, srv := range servers {
+			if srv.listener.Addr().String() == addr {
+				owners[key] = srv
+			}
+		}
+	}
+
+	// The rate limit owned by the last peer is over the limit
+	overKey := keys[servers[2].listener.Addr().String()]
+	owners[overKey].hit(t, "test_reservation", overKey, 9)
+
+	var requests []*gubernator.RateLimitReq
+	for _, srv := range servers {
+		requests = append(requests, &gubernator.RateLimitReq{
+			Name:      "test_reservation",
+			UniqueKey: keys[srv.listener.Addr().String()],
+			Duration:  gubernator.Minute,
+			Limit:     10,
+			Hits:      2,
+		})
+	}
+	resp, err := servers[0].srv.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+		Atomic:   true,
+		Requests: requests,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Responses, 3)
+	for _, rl := range resp.Responses {
+		assert.Empty(t, rl.Error)
+		assert.Equal(t, gubernator.Status_OVER_LIMIT, rl.Status)
+	}
+
+	// No peer applied the hits
+	for key, srv := range owners {
+		expected := int64(10)
+		if key == overKey {
+			expected = 1
+		}
+		assert.Equal(t, expected, srv.hit(t, "test_reservation", key, 0).Remaining, key)
+	}
+}
@@ -0,0 +1,429 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/mailgun/holster/v4/setter"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// A snapshot file begins with a header of the magic bytes followed by the
+// format version as a big endian uint16. Every record after the header is
+//
+//	uvarint  length of the payload
+//	[]byte   payload
+//	uint32   big endian CRC-32 (IEEE) of the payload
+//
+// The payload of a record is a single CacheItem encoded by appendSnapshotItem()
+var snapshotMagic = []byte("GUBSNAP")
+
+const snapshotVersion uint16 = 1
+
+// The largest record payload we are willing to read, this protects against
+// allocating huge buffers when reading a corrupt length.
+const snapshotMaxRecord = 1 << 20
+
+// The kind of value held by a CacheItem in a snapshot record
+const (
+	snapshotTokenBucket byte = iota + 1
+	snapshotLeakyBucket
+	snapshotSlidingWindow
+	snapshotGCRA
+	snapshotConcurrency
+	snapshotContinuousTokenBucket
+)
+
+type FileLoaderConfig struct {
+	// (Required) The path of the snapshot file
+	Path string
+
+	// (Optional) Skip calling fsync() on the snapshot file and its directory after a save.
+	// Saves are faster, but the snapshot may be lost or incomplete if the host crashes.
+	DisableFsync bool
+
+	// (Optional) A Logger which implements the declared logger interface (typically *logrus.Entry)
+	Logger FieldLogger
+}
+
+// FileLoader implements the Loader interface by saving the cache to a snapshot file
+// on shutdown and loading it back into the cache on startup. Saves are written to a
+// temporary file which replaces the snapshot once complete, such that a failed save
+// never corrupts the previous snapshot.
+type FileLoader struct {
+	conf FileLoaderConfig
+}
+
+var _ Loader = &FileLoader{}
+
+func NewFileLoader(conf FileLoaderConfig) (*FileLoader, error) {
+	setter.SetDefault(&conf.Logger, logrus.WithField("category", "gubernator"))
+
+	if conf.Path == "" {
+		return nil, errors.New("FileLoaderConfig.Path is required")
+	}
+	return &FileLoader{conf: conf}, nil
+}
+
+// Load reads the rate limits in the snapshot file. Rate limits which have expired
+// are skipped. If the snapshot file does not exist no rate limits are loaded.
+func (l *FileLoader) Load() (chan *CacheItem, error) {
+	f, err := os.Open(l.conf.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			l.conf.Logger.Infof("Snapshot file '%s' does not exist; no rate limits loaded", l.conf.Path)
+			out := make(chan *CacheItem)
+			close(out)
+			return out, nil
+		}
+		return nil, errors.Wrap(err, "while opening snapshot file")
+	}
+
+	r := bufio.NewReader(f)
+	if err := readSnapshotHeader(r); err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "while reading snapshot file '%s'", l.conf.Path)
+	}
+
+	out := make(chan *CacheItem, 1000)
+	go func() {
+		defer close(out)
+		defer f.Close()
+
+		now := MillisecondNow()
+		var loaded, expired int
+		for {
+			item, err := readSnapshotRecord(r)
+			if err != nil {
+				if err != io.EOF {
+					l.conf.Logger.WithError(err).Errorf("while reading snapshot file '%s'; "+
+						"remaining rate limits are not loaded", l.conf.Path)
+				}
+				break
+			}
+			if item.ExpireAt < now {
+				expired++
+				continue
+			}
+			loaded++
+			out <- item
+		}
+		l.conf.Logger.Infof("Loaded %d rate limits from snapshot '%s', skipped %d expired",
+			loaded, l.conf.Path, expired)
+	}()
+	return out, nil
+}
+
+// Save writes every rate limit from the channel to the snapshot file
+func (l *FileLoader) Save(in chan *CacheItem) error {
+	tmp, err := os.CreateTemp(filepath.Dir(l.conf.Path), filepath.Base(l.conf.Path)+".*.tmp")
+	if err != nil {
+		// Drain the channel so the workers are not blocked
+		for range in {
+		}
+		return errors.Wrap(err, "while creating snapshot file")
+	}
+	// Remove the temporary file if we do not make it to the rename
+	defer os.Remove(tmp.Name())
+
+	count, err := l.write(tmp, in)
+	if err != nil {
+		tmp.Close()
+		for range in {
+		}
+		return errors.Wrapf(err, "while writing snapshot file '%s'", tmp.Name())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "while closing snapshot file '%s'", tmp.Name())
+	}
+
+	if err := os.Rename(tmp.Name(), l.conf.Path); err != nil {
+		return errors.Wrap(err, "while replacing snapshot file")
+	}
+
+	if !l.conf.DisableFsync {
+		// Ensure the rename is durable
+		if err := syncDir(filepath.Dir(l.conf.Path)); err != nil {
+			return errors.Wrap(err, "while syncing snapshot directory")
+		}
+	}
+	l.conf.Logger.Infof("Saved %d rate limits to snapshot '%s'", count, l.conf.Path)
+	return nil
+}
+
+func (l *FileLoader) write(f *os.File, in chan *CacheItem) (int, error) {
+	w := bufio.NewWriter(f)
+	if _, err := w.Write(snapshotMagic); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.BigEndian, snapshotVersion); err != nil {
+		return 0, err
+	}
+
+	var count int
+	var payload, record []byte
+	for item := range in {
+		var err error
+		payload, err = appendSnapshotItem(payload[:0], item)
+		if err != nil {
+			l.conf.Logger.WithError(err).Errorf("while encoding rate limit '%s'", item.Key)
+			continue
+		}
+		record = binary.AppendUvarint(record[:0], uint64(len(payload)))
+		record = append(record, payload...)
+		record = binary.BigEndian.AppendUint32(record, crc32.ChecksumIEEE(payload))
+		if _, err := w.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	if err := w.Flush(); err != nil {
+		return count, err
+	}
+	if !l.conf.DisableFsync {
+		if err := f.Sync(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func readSnapshotHeader(r *bufio.Reader) error {
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return errors.Wrap(err, "while reading header")
+	}
+	if !bytes.Equal(magic, snapshotMagic) {
+		return errors.New("not a gubernator snapshot file")
+	}
+
+	var version uint16
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return errors.Wrap(err, "while reading header")
+	}
+	if version != snapshotVersion {
+		return errors.Errorf("unsupported snapshot version '%d'", version)
+	}
+	return nil
+}
+
+// readSnapshotRecord returns io.EOF when there are no more records
+func readSnapshotRecord(r *bufio.Reader) (*CacheItem, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, errors.Wrap(err, "while reading record length")
+	}
+	if size > snapshotMaxRecord {
+		return nil, errors.Errorf("record length '%d' exceeds maximum", size)
+	}
+
+	buf := make([]byte, size+4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errors.Wrap(err, "while reading record")
+	}
+	payload := buf[:size]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(buf[size:]) {
+		return nil, errors.New("record checksum mismatch")
+	}
+
+	return decodeSnapshotItem(payload)
+}
+
+func appendSnapshotItem(b []byte, item *CacheItem) ([]byte, error) {
+	b = binary.AppendVarint(b, int64(item.Algorithm))
+	b = binary.AppendUvarint(b, uint64(len(item.Key)))
+	b = append(b, item.Key...)
+	b = binary.AppendVarint(b, item.ExpireAt)
+
+	switch v := item.Value.(type) {
+	case *TokenBucketItem:
+		b = append(b, snapshotTokenBucket)
+		b = appendVarints(b, int64(v.Status), v.Limit, v.Duration, v.Remaining, v.CreatedAt)
+	case *LeakyBucketItem:
+		b = append(b, snapshotLeakyBucket)
+		b = appendVarints(b, v.Limit, v.Duration, v.UpdatedAt, v.Burst)
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(v.Remaining))
+	case *SlidingWindowItem:
+		b = append(b, snapshotSlidingWindow)
+		b = appendVarints(b, v.Limit, v.Duration, v.WindowStart, v.Current, v.Previous)
+	case *GCRAItem:
+		b = append(b, snapshotGCRA)
+		b = appendVarints(b, v.TAT)
+	case *ContinuousTokenBucketItem:
+		b = append(b, snapshotContinuousTokenBucket)
+		b = appendVarints(b, v.Limit, v.Duration, v.Burst, v.UpdatedAt)
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(v.Tokens))
+	case *ConcurrencyItem:
+		b = append(b, snapshotConcurrency)
+		b = appendVarints(b, v.Limit, int64(len(v.Leases)))
+		for _, lease := range v.Leases {
+			b = appendVarints(b, lease.ExpireAt, lease.Count)
+		}
+	default:
+		return b, errors.Errorf("unknown cache item value type '%T'", item.Value)
+	}
+	return b, nil
+}
+
+func appendVarints(b []byte, values ...int64) []byte {
+	for _, v := range values {
+		b = binary.AppendVarint(b, v)
+	}
+	return b
+}
+
+func decodeSnapshotItem(payload []byte) (*CacheItem, error) {
+	d := snapshotDecoder{r: bytes.NewReader(payload)}
+	item := &CacheItem{
+		Algorithm: Algorithm(d.varint()),
+	}
+	item.Key = d.string()
+	item.ExpireAt = d.varint()
+
+	kind := d.byte()
+	switch kind {
+	case snapshotTokenBucket:
+		item.Value = &TokenBucketItem{
+			Status:    Status(d.varint()),
+			Limit:     d.varint(),
+			Duration:  d.varint(),
+			Remaining: d.varint(),
+			CreatedAt: d.varint(),
+		}
+	case snapshotLeakyBucket:
+		item.Value = &LeakyBucketItem{
+			Limit:     d.varint(),
+			Duration:  d.varint(),
+			UpdatedAt: d.varint(),
+			Burst:     d.varint(),
+			Remaining: d.float64(),
+		}
+	case snapshotSlidingWindow:
+		item.Value = &SlidingWindowItem{
+			Limit:       d.varint(),
+			Duration:    d.varint(),
+			WindowStart: d.varint(),
+			Current:     d.varint(),
+			Previous:    d.varint(),
+		}
+	case snapshotGCRA:
+		item.Value = &GCRAItem{
+			TAT: d.varint(),
+		}
+	case snapshotContinuousTokenBucket:
+		item.Value = &ContinuousTokenBucketItem{
+			Limit:     d.varint(),
+			Duration:  d.varint(),
+			Burst:     d.varint(),
+			UpdatedAt: d.varint(),
+			Tokens:    d.float64(),
+		}
+	case snapshotConcurrency:
+		v := &ConcurrencyItem{Limit: d.varint()}
+		n := d.varint()
+		if n < 0 || n > int64(len(payload)) {
+			return nil, errors.Errorf("invalid lease count '%d'", n)
+		}
+		for i := int64(0); i < n && d.err == nil; i++ {
+			v.Leases = append(v.Leases, ConcurrencyLease{ExpireAt: d.varint(), Count: d.varint()})
+		}
+		item.Value = v
+	default:
+		if d.err == nil {
+			return nil, errors.Errorf("unknown record value kind '%d'", kind)
+		}
+	}
+
+	if d.err != nil {
+		return nil, errors.Wrap(d.err, "while decoding record")
+	}
+	return item, nil
+}
+
+// snapshotDecoder reads values from a record payload, remembering the first error
+type snapshotDecoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *snapshotDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	var v int64
+	v, d.err = binary.ReadVarint(d.r)
+	return v
+}
+
+func (d *snapshotDecoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	var v byte
+	v, d.err = d.r.ReadByte()
+	return v
+}
+
+func (d *snapshotDecoder) float64() float64 {
+	if d.err != nil {
+		return 0
+	}
+	var v uint64
+	d.err = binary.Read(d.r, binary.BigEndian, &v)
+	return math.Float64frombits(v)
+}
+
+func (d *snapshotDecoder) string() string {
+	if d.err != nil {
+		return ""
+	}
+	var n uint64
+	n, d.err = binary.ReadUvarint(d.r)
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(d.r.Len()) {
+		d.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return string(b)
+}
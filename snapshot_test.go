@@ -0,0 +1,230 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func saveSnapshot(t *testing.T, loader *gubernator.FileLoader, items []*gubernator.CacheItem) {
+	in := make(chan *gubernator.CacheItem, len(items))
+	for _, item := range items {
+		in <- item
+	}
+	close(in)
+	require.NoError(t, loader.Save(in))
+}
+
+func loadSnapshot(t *testing.T, loader *gubernator.FileLoader) []*gubernator.CacheItem {
+	out, err := loader.Load()
+	require.NoError(t, err)
+	var items []*gubernator.CacheItem
+	for item := range out {
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestFileLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	loader, err := gubernator.NewFileLoader(gubernator.FileLoaderConfig{Path: path})
+	require.NoError(t, err)
+
+	// No snapshot file yet
+	assert.Len(t, loadSnapshot(t, loader), 0)
+
+	expireAt := gubernator.MillisecondNow() + gubernator.Minute
+	items := []*gubernator.CacheItem{
+		{
+			Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+			Key:       "test_file_loader_token",
+			ExpireAt:  expireAt,
+			Value: &gubernator.TokenBucketItem{
+				Status:    gubernator.Status_OVER_LIMIT,
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				CreatedAt: 1234,
+			},
+		},
+		{
+			Algorithm: gubernator.Algorithm_LEAKY_BUCKET,
+			Key:       "test_file_loader_leaky",
+			ExpireAt:  expireAt,
+			Value: &gubernator.LeakyBucketItem{
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Remaining: 2.5,
+				UpdatedAt: 1234,
+				Burst:     20,
+			},
+		},
+		{
+			Algorithm: gubernator.Algorithm_SLIDING_WINDOW,
+			Key:       "test_file_loader_window",
+			ExpireAt:  expireAt,
+			Value: &gubernator.SlidingWindowItem{
+				Limit:       10,
+				Duration:    gubernator.Minute,
+				WindowStart: 1234,
+				Current:     3,
+				Previous:    7,
+			},
+		},
+		{
+			Algorithm: gubernator.Algorithm_GCRA,
+			Key:       "test_file_loader_gcra",
+			ExpireAt:  expireAt,
+			Value: &gubernator.GCRAItem{
+				TAT: -1234,
+			},
+		},
+		{
+			Algorithm: gubernator.Algorithm_CONTINUOUS_TOKEN_BUCKET,
+			Key:       "test_file_loader_continuous",
+			ExpireAt:  expireAt,
+			Value: &gubernator.ContinuousTokenBucketItem{
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Burst:     20,
+				Tokens:    2.5,
+				UpdatedAt: 1234,
+			},
+		},
+		{
+			Algorithm: gubernator.Algorithm_CONCURRENCY,
+			Key:       "test_file_loader_concurrency",
+			ExpireAt:  expireAt,
+			Value: &gubernator.ConcurrencyItem{
+				Limit: 10,
+				Leases: []gubernator.ConcurrencyLease{
+					{ExpireAt: expireAt, Count: 2},
+					{ExpireAt: expireAt + 1, Count: 3},
+				},
+			},
+		},
+	}
+
+	expired := &gubernator.CacheItem{
+		Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+		Key:       "test_file_loader_expired",
+		ExpireAt:  gubernator.MillisecondNow() - 1,
+		Value:     &gubernator.TokenBucketItem{Limit: 10},
+	}
+
+	saveSnapshot(t, loader, append(items, expired))
+	assert.Equal(t, items, loadSnapshot(t, loader))
+
+	// A new save replaces the previous snapshot
+	saveSnapshot(t, loader, items[:1])
+	assert.Equal(t, items[:1], loadSnapshot(t, loader))
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileLoaderCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	loader, err := gubernator.NewFileLoader(gubernator.FileLoaderConfig{Path: path, DisableFsync: true})
+	require.NoError(t, err)
+
+	expireAt := gubernator.MillisecondNow() + gubernator.Minute
+	saveSnapshot(t, loader, []*gubernator.CacheItem{
+		{Key: "a", ExpireAt: expireAt, Value: &gubernator.TokenBucketItem{Limit: 1}},
+		{Key: "b", ExpireAt: expireAt, Value: &gubernator.TokenBucketItem{Limit: 2}},
+	})
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	t.Run("truncated", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, b[:len(b)-2], 0600))
+		items := loadSnapshot(t, loader)
+		require.Len(t, items, 1)
+		assert.Equal(t, "a", items[0].Key)
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		c := append([]byte{}, b...)
+		c[len(c)-5] ^= 0xff
+		require.NoError(t, os.WriteFile(path, c, 0600))
+		items := loadSnapshot(t, loader)
+		require.Len(t, items, 1)
+		assert.Equal(t, "a", items[0].Key)
+	})
+
+	t.Run("version", func(t *testing.T) {
+		c := append([]byte{}, b...)
+		c[8] = 99
+		require.NoError(t, os.WriteFile(path, c, 0600))
+		_, err := loader.Load()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported snapshot version")
+	})
+
+	t.Run("not a snapshot", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("hello world"), 0600))
+		_, err := loader.Load()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a gubernator snapshot file")
+	})
+}
+
+func TestFileLoaderRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	ctx := context.Background()
+
+	req := &gubernator.RateLimitReq{
+		Name:      "test_file_loader_restart",
+		UniqueKey: "account:1234",
+		Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+		Duration:  gubernator.Minute,
+		Limit:     10,
+		Hits:      4,
+	}
+
+	hit := func() *gubernator.RateLimitResp {
+		loader, err := gubernator.NewFileLoader(gubernator.FileLoaderConfig{Path: path})
+		require.NoError(t, err)
+
+		srv := newV1Server(t, "localhost:0", gubernator.Config{Loader: loader})
+		defer func() { require.NoError(t, srv.Close()) }()
+
+		client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+		require.NoError(t, err)
+
+		resp, err := client.GetRateLimits(ctx, &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{req},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 1)
+		require.Equal(t, "", resp.Responses[0].Error)
+		return resp.Responses[0]
+	}
+
+	assert.Equal(t, int64(6), hit().Remaining)
+
+	// The rate limit saved on shutdown should be restored by the next instance
+	assert.Equal(t, int64(2), hit().Remaining)
+}
@@ -0,0 +1,417 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/setter"
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// The number of rate limits written to the database in a single transaction by Save()
+const sqlBatchSize = 500
+
+// sqlMigrations are applied in order to create and update the schema. The version of a migration
+// is its index plus one. Migrations are formatted with the table name and MUST never be changed
+// once released; add a new migration instead. Only use SQL which both PostgreSQL and SQLite support.
+var sqlMigrations = []string{
+	`CREATE TABLE %[1]s (
+		hash_key TEXT NOT NULL PRIMARY KEY,
+		expire_at BIGINT NOT NULL,
+		item TEXT NOT NULL
+	)`,
+	`CREATE INDEX %[1]s_expire_at ON %[1]s (expire_at)`,
+}
+
+var sqlTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type SQLConfig struct {
+	// (Required) The name of a database/sql driver registered by the application, such as
+	// 'postgres' for github.com/lib/pq or 'sqlite3' for github.com/mattn/go-sqlite3
+	Driver string
+
+	// (Required) The driver specific data source name used to connect to the database
+	DSN string
+
+	// (Optional) The table rate limits are stored in. The schema versions applied are recorded
+	// in a table of the same name with a '_migrations' suffix. Defaults to 'gubernator_rate_limits'
+	Table string
+
+	// (Optional) How long to wait for each database operation. Defaults to 500ms
+	Timeout time.Duration
+
+	// (Optional) How often expired rate limits are deleted from the database. Defaults to 1m
+	CleanupInterval time.Duration
+
+	// (Optional) A Logger which implements the declared logger interface (typically *logrus.Entry)
+	Logger FieldLogger
+}
+
+// SQLStore persists rate limits in a relational database through database/sql. Like the RedisStore
+// it implements both the StoreV2 and the Loader interfaces. The schema is created and migrated to
+// the latest version by NewSQLStore(), and expired rate limits are deleted in the background.
+type SQLStore struct {
+	conf SQLConfig
+	db   *sql.DB
+	wg   syncutil.WaitGroup
+}
+
+var _ StoreV2 = &SQLStore{}
+var _ Loader = &SQLStore{}
+var _ IncrementalLoader = &SQLStore{}
+var _ StoreBatchWriter = &SQLStore{}
+
+// NewSQLStore connects to the database, applies any pending schema migrations and returns a SQLStore
+func NewSQLStore(conf SQLConfig) (*SQLStore, error) {
+	setter.SetDefault(&conf.Table, "gubernator_rate_limits")
+	setter.SetDefault(&conf.Timeout, time.Millisecond*500)
+	setter.SetDefault(&conf.CleanupInterval, time.Minute)
+	setter.SetDefault(&conf.Logger, logrus.WithField("category", "gubernator"))
+
+	if conf.Driver == "" {
+		return nil, errors.New("SQLConfig.Driver is required")
+	}
+	if conf.DSN == "" {
+		return nil, errors.New("SQLConfig.DSN is required")
+	}
+	if !sqlTableName.MatchString(conf.Table) {
+		return nil, errors.Errorf("SQLConfig.Table '%s' is not a valid table name", conf.Table)
+	}
+
+	db, err := sql.Open(conf.Driver, conf.DSN)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while opening '%s' database", conf.Driver)
+	}
+
+	s := &SQLStore{conf: conf, db: db}
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Timeout)
+	err = db.PingContext(ctx)
+	cancel()
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrapf(err, "while connecting to '%s' database", conf.Driver)
+	}
+
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s.runCleanup()
+	return s, nil
+}
+
+// migrate applies the schema migrations which have not yet been applied to the database
+func (s *SQLStore) migrate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout*10)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_migrations (
+		version INTEGER NOT NULL PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`, s.conf.Table))
+	if err != nil {
+		return errors.Wrap(err, "while creating migrations table")
+	}
+
+	for {
+		version, err := s.schemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if version >= len(sqlMigrations) {
+			return nil
+		}
+
+		if err := s.applyMigration(ctx, version+1); err != nil {
+			// Another instance may have applied the same migration first
+			current, verr := s.schemaVersion(ctx)
+			if verr == nil && current > version {
+				continue
+			}
+			return err
+		}
+		s.conf.Logger.Infof("Applied migration %d to table '%s'", version+1, s.conf.Table)
+	}
+}
+
+func (s *SQLStore) schemaVersion(ctx context.Context) (int, error) {
+	var version sql.NullInt64
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT MAX(version) FROM %s_migrations`, s.conf.Table)).Scan(&version)
+	if err != nil {
+		return 0, errors.Wrap(err, "while reading schema version")
+	}
+	return int(version.Int64), nil
+}
+
+func (s *SQLStore) applyMigration(ctx context.Context, version int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "while starting migration")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(sqlMigrations[version-1], s.conf.Table)); err != nil {
+		return errors.Wrapf(err, "while applying migration %d", version)
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s_migrations (version, applied_at) VALUES ($1, $2)`,
+		s.conf.Table), version, MillisecondNow())
+	if err != nil {
+		return errors.Wrapf(err, "while recording migration %d", version)
+	}
+	return errors.Wrapf(tx.Commit(), "while committing migration %d", version)
+}
+
+// runCleanup periodically deletes the rate limits which have expired
+func (s *SQLStore) runCleanup() {
+	tick := clock.NewTicker(s.conf.CleanupInterval)
+	s.wg.Until(func(done chan struct{}) bool {
+		select {
+		case <-tick.C():
+			if err := s.cleanup(); err != nil {
+				s.conf.Logger.WithError(err).Error("while deleting expired rate limits")
+			}
+		case <-done:
+			tick.Stop()
+			return false
+		}
+		return true
+	})
+}
+
+func (s *SQLStore) cleanup() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout*10)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expire_at <= $1`, s.conf.Table),
+		MillisecondNow())
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err == nil && count != 0 {
+		s.conf.Logger.Debugf("Deleted %d expired rate limits from table '%s'", count, s.conf.Table)
+	}
+	return nil
+}
+
+// OnChange inserts or updates the rate limit in the database
+func (s *SQLStore) OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) error {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	if item.ExpireAt <= MillisecondNow() {
+		return s.Remove(ctx, item.Key)
+	}
+
+	b, err := marshalCacheItem(item)
+	if err != nil {
+		return errors.Wrapf(err, "while encoding rate limit '%s'", item.Key)
+	}
+
+	if _, err := s.db.ExecContext(ctx, s.upsertQuery(), item.Key, item.ExpireAt, string(b)); err != nil {
+		return errors.Wrapf(err, "while storing rate limit '%s' in database", item.Key)
+	}
+	return nil
+}
+
+// Get reads the rate limit from the database
+func (s *SQLStore) Get(ctx context.Context, r *RateLimitReq) (*CacheItem, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	var b string
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT item FROM %s WHERE hash_key = $1 AND expire_at > $2`,
+		s.conf.Table), r.HashKey(), MillisecondNow()).Scan(&b)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "while reading rate limit '%s' from database", r.HashKey())
+	}
+
+	item, err := unmarshalCacheItem([]byte(b))
+	if err != nil {
+		// A rate limit we can not decode is as good as missing
+		s.conf.Logger.WithError(err).Errorf("while decoding rate limit '%s' from database", r.HashKey())
+		return nil, false, nil
+	}
+	return item, true, nil
+}
+
+// Remove deletes the rate limit from the database
+func (s *SQLStore) Remove(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE hash_key = $1`, s.conf.Table), key); err != nil {
+		return errors.Wrapf(err, "while removing rate limit '%s' from database", key)
+	}
+	return nil
+}
+
+// WriteBatch writes or removes the rate limits in a single transaction
+func (s *SQLStore) WriteBatch(ctx context.Context, batch []StoreChange) error {
+	ctx, cancel := context.WithTimeout(ctx, s.conf.Timeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "while starting transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := MillisecondNow()
+	for _, c := range batch {
+		if c.Item == nil || c.Item.ExpireAt <= now {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE hash_key = $1`,
+				s.conf.Table), c.Key); err != nil {
+				return errors.Wrapf(err, "while removing rate limit '%s' from database", c.Key)
+			}
+			continue
+		}
+		b, err := marshalCacheItem(c.Item)
+		if err != nil {
+			return errors.Wrapf(err, "while encoding rate limit '%s'", c.Key)
+		}
+		if _, err := tx.ExecContext(ctx, s.upsertQuery(), c.Key, c.Item.ExpireAt, string(b)); err != nil {
+			return errors.Wrapf(err, "while storing rate limit '%s' in database", c.Key)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "while committing transaction")
+	}
+	return nil
+}
+
+// Load reads every rate limit which has not expired from the database
+func (s *SQLStore) Load() (chan *CacheItem, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT hash_key, item FROM %s WHERE expire_at > $1`, s.conf.Table),
+		MillisecondNow())
+	if err != nil {
+		return nil, errors.Wrap(err, "while loading rate limits from database")
+	}
+
+	out := make(chan *CacheItem, sqlBatchSize)
+	go func() {
+		defer close(out)
+		defer rows.Close()
+
+		for rows.Next() {
+			var key, b string
+			if err := rows.Scan(&key, &b); err != nil {
+				s.conf.Logger.WithError(err).Error("while loading rate limits from database")
+				return
+			}
+			item, err := unmarshalCacheItem([]byte(b))
+			if err != nil {
+				s.conf.Logger.WithError(err).Errorf("while decoding rate limit '%s' from database", key)
+				continue
+			}
+			out <- item
+		}
+		if err := rows.Err(); err != nil {
+			s.conf.Logger.WithError(err).Error("while loading rate limits from database")
+		}
+	}()
+	return out, nil
+}
+
+// Save writes every rate limit from the channel to the database
+func (s *SQLStore) Save(in chan *CacheItem) error {
+	var errs []string
+	batch := make([]*CacheItem, 0, sqlBatchSize)
+
+	for item := range in {
+		batch = append(batch, item)
+		if len(batch) >= sqlBatchSize {
+			if err := s.saveBatch(batch); err != nil {
+				errs = append(errs, err.Error())
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) != 0 {
+		if err := s.saveBatch(batch); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.Errorf("while saving rate limits to database: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// SaveChanges writes the rate limits which changed since the last checkpoint to the database.
+// Rate limits removed from the cache are deleted from the database once they expire.
+func (s *SQLStore) SaveChanges(in chan *CacheItem) error {
+	return s.Save(in)
+}
+
+// saveBatch writes the rate limits to the database in a single transaction
+func (s *SQLStore) saveBatch(batch []*CacheItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout*10)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, s.upsertQuery())
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := MillisecondNow()
+	for _, item := range batch {
+		if item.ExpireAt <= now {
+			continue
+		}
+		b, err := marshalCacheItem(item)
+		if err != nil {
+			s.conf.Logger.WithError(err).Errorf("while encoding rate limit '%s'", item.Key)
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, item.Key, item.ExpireAt, string(b)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) upsertQuery() string {
+	return fmt.Sprintf(`INSERT INTO %s (hash_key, expire_at, item) VALUES ($1, $2, $3)
+		ON CONFLICT (hash_key) DO UPDATE SET expire_at = excluded.expire_at, item = excluded.item`, s.conf.Table)
+}
+
+// Close stops the cleanup of expired rate limits and closes the database
+func (s *SQLStore) Close() error {
+	s.wg.Stop()
+	return s.db.Close()
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/testutil"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sqliteDSN(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "gubernator.db") + "?_busy_timeout=5000"
+}
+
+func newSQLStore(t *testing.T, dsn string) *gubernator.SQLStore {
+	store, err := gubernator.NewSQLStore(gubernator.SQLConfig{
+		Driver:  "sqlite3",
+		DSN:     dsn,
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestSQLStore(t *testing.T) {
+	store := newSQLStore(t, sqliteDSN(t))
+	ctx := context.Background()
+	expireAt := gubernator.MillisecondNow() + gubernator.Minute
+
+	for _, tt := range []struct {
+		algorithm gubernator.Algorithm
+		value     interface{}
+	}{
+		{
+			algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+			value: &gubernator.TokenBucketItem{
+				Status:    gubernator.Status_OVER_LIMIT,
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Remaining: 0,
+				CreatedAt: 1234,
+			},
+		},
+		{
+			algorithm: gubernator.Algorithm_LEAKY_BUCKET,
+			value: &gubernator.LeakyBucketItem{
+				Limit:     10,
+				Duration:  gubernator.Minute,
+				Remaining: 2.5,
+				UpdatedAt: 1234,
+				Burst:     20,
+			},
+		},
+	} {
+		t.Run(tt.algorithm.String(), func(t *testing.T) {
+			req := &gubernator.RateLimitReq{
+				Name:      "test_sql_store",
+				UniqueKey: tt.algorithm.String(),
+				Algorithm: tt.algorithm,
+			}
+			item := &gubernator.CacheItem{
+				Algorithm: tt.algorithm,
+				Key:       req.HashKey(),
+				Value:     tt.value,
+				ExpireAt:  expireAt,
+			}
+
+			require.NoError(t, store.OnChange(ctx, req, item))
+			// Updates replace the existing row
+			require.NoError(t, store.OnChange(ctx, req, item))
+
+			got, ok, err := store.Get(ctx, req)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, item, got)
+
+			require.NoError(t, store.Remove(ctx, req.HashKey()))
+			_, ok, err = store.Get(ctx, req)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		req := &gubernator.RateLimitReq{Name: "test_sql_store", UniqueKey: "expired"}
+		require.NoError(t, store.OnChange(ctx, req, &gubernator.CacheItem{
+			Key:      req.HashKey(),
+			Value:    &gubernator.TokenBucketItem{Limit: 10},
+			ExpireAt: gubernator.MillisecondNow() + gubernator.Minute,
+		}))
+		require.NoError(t, store.OnChange(ctx, req, &gubernator.CacheItem{
+			Key:      req.HashKey(),
+			Value:    &gubernator.TokenBucketItem{Limit: 10},
+			ExpireAt: gubernator.MillisecondNow() - 1,
+		}))
+		_, ok, err := store.Get(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		keep := &gubernator.RateLimitReq{Name: "test_sql_store", UniqueKey: "batch:keep"}
+		remove := &gubernator.RateLimitReq{Name: "test_sql_store", UniqueKey: "batch:remove"}
+		require.NoError(t, store.OnChange(ctx, remove, &gubernator.CacheItem{
+			Key:      remove.HashKey(),
+			Value:    &gubernator.TokenBucketItem{Limit: 10},
+			ExpireAt: expireAt,
+		}))
+
+		item := &gubernator.CacheItem{
+			Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+			Key:       keep.HashKey(),
+			Value:     &gubernator.TokenBucketItem{Limit: 10, Remaining: 5},
+			ExpireAt:  expireAt,
+		}
+		require.NoError(t, store.WriteBatch(ctx, []gubernator.StoreChange{
+			{Key: keep.HashKey(), Req: keep, Item: item},
+			{Key: remove.HashKey()},
+		}))
+
+		got, ok, err := store.Get(ctx, keep)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, item, got)
+		_, ok, err = store.Get(ctx, remove)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		store := newSQLStore(t, sqliteDSN(t))
+		require.NoError(t, store.Close())
+
+		req := &gubernator.RateLimitReq{Name: "test_sql_store", UniqueKey: "unavailable"}
+		_, _, err := store.Get(ctx, req)
+		assert.Error(t, err)
+		assert.Error(t, store.Remove(ctx, req.HashKey()))
+	})
+}
+
+func TestSQLStoreMigrations(t *testing.T) {
+	dsn := sqliteDSN(t)
+	store := newSQLStore(t, dsn)
+	require.NoError(t, store.Close())
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM gubernator_rate_limits_migrations").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	// Opening an existing database does not apply the migrations again
+	newSQLStore(t, dsn)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM gubernator_rate_limits_migrations").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	_, err = gubernator.NewSQLStore(gubernator.SQLConfig{Driver: "sqlite3", DSN: dsn, Table: "rate_limits; DROP TABLE"})
+	assert.Error(t, err)
+	_, err = gubernator.NewSQLStore(gubernator.SQLConfig{Driver: "unknown", DSN: dsn})
+	assert.Error(t, err)
+}
+
+func TestSQLStoreCleanup(t *testing.T) {
+	dsn := sqliteDSN(t)
+	store, err := gubernator.NewSQLStore(gubernator.SQLConfig{
+		Driver:          "sqlite3",
+		DSN:             dsn,
+		CleanupInterval: time.Millisecond * 50,
+	})
+	require.NoError(t, err)
+	defer store.Close()
+
+	in := make(chan *gubernator.CacheItem, 2)
+	in <- &gubernator.CacheItem{
+		Key:      "test_sql_cleanup_account:1",
+		Value:    &gubernator.TokenBucketItem{Limit: 10},
+		ExpireAt: gubernator.MillisecondNow() + 100,
+	}
+	in <- &gubernator.CacheItem{
+		Key:      "test_sql_cleanup_account:2",
+		Value:    &gubernator.TokenBucketItem{Limit: 10},
+		ExpireAt: gubernator.MillisecondNow() + gubernator.Minute,
+	}
+	close(in)
+	require.NoError(t, store.Save(in))
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	testutil.UntilPass(t, 20, time.Millisecond*50, func(t testutil.TestingT) {
+		var keys []string
+		rows, err := db.Query("SELECT hash_key FROM gubernator_rate_limits")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var key string
+			assert.NoError(t, rows.Scan(&key))
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"test_sql_cleanup_account:2"}, keys)
+	})
+}
+
+func TestSQLStoreLoader(t *testing.T) {
+	store := newSQLStore(t, sqliteDSN(t))
+	expireAt := gubernator.MillisecondNow() + gubernator.Minute
+
+	// Enough items to span more than a single batch
+	const count = 1200
+	in := make(chan *gubernator.CacheItem, count+1)
+	for i := 0; i < count; i++ {
+		in <- &gubernator.CacheItem{
+			Algorithm: gubernator.Algorithm_LEAKY_BUCKET,
+			Key:       fmt.Sprintf("test_sql_loader_account:%d", i),
+			Value:     &gubernator.LeakyBucketItem{Limit: 10, Remaining: float64(i % 10)},
+			ExpireAt:  expireAt,
+		}
+	}
+	in <- &gubernator.CacheItem{
+		Key:      "test_sql_loader_expired",
+		Value:    &gubernator.TokenBucketItem{Limit: 10},
+		ExpireAt: gubernator.MillisecondNow() - 1,
+	}
+	close(in)
+	require.NoError(t, store.Save(in))
+
+	out, err := store.Load()
+	require.NoError(t, err)
+
+	loaded := make(map[string]*gubernator.CacheItem)
+	for item := range out {
+		loaded[item.Key] = item
+	}
+	require.Len(t, loaded, count)
+
+	item := loaded["test_sql_loader_account:13"]
+	require.NotNil(t, item)
+	assert.Equal(t, expireAt, item.ExpireAt)
+	assert.Equal(t, &gubernator.LeakyBucketItem{Limit: 10, Remaining: 3}, item.Value)
+}
+
+func TestSQLStorePersistsAcrossServers(t *testing.T) {
+	dsn := sqliteDSN(t)
+	ctx := context.Background()
+
+	req := &gubernator.RateLimitReq{
+		Name:      "test_sql_persist",
+		UniqueKey: "account:1234",
+		Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+		Behavior:  gubernator.Behavior_DURATION_IS_GREGORIAN,
+		Duration:  gubernator.GregorianMonths,
+		Limit:     10,
+		Hits:      4,
+	}
+
+	hit := func() *gubernator.RateLimitResp {
+		store := newSQLStore(t, dsn)
+		srv := newV1Server(t, "localhost:0", gubernator.Config{StoreV2: store, Loader: store})
+		defer srv.Close()
+
+		client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+		require.NoError(t, err)
+
+		resp, err := client.GetRateLimits(ctx, &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{req},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 1)
+		require.Equal(t, "", resp.Responses[0].Error)
+		return resp.Responses[0]
+	}
+
+	assert.Equal(t, int64(6), hit().Remaining)
+
+	// A new server should load the rate limit back from the database
+	assert.Equal(t, int64(2), hit().Remaining)
+}
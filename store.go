@@ -16,7 +16,13 @@ limitations under the License.
 
 package gubernator
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // PERSISTENT STORE DETAILS
 
@@ -42,6 +48,47 @@ type TokenBucketItem struct {
 	CreatedAt int64
 }
 
+type ContinuousTokenBucketItem struct {
+	Limit int64
+	// The time in milliseconds it takes to refill `Limit` tokens
+	Duration int64
+	// The maximum number of tokens in the bucket
+	Burst int64
+	// The tokens in the bucket at `UpdatedAt`
+	Tokens    float64
+	UpdatedAt int64
+}
+
+type SlidingWindowItem struct {
+	Limit int64
+	// The length of the window in milliseconds
+	Duration int64
+	// The start of the current window in milliseconds
+	WindowStart int64
+	// Hits counted in the current window
+	Current int64
+	// Hits counted in the window before the current one
+	Previous int64
+}
+
+type GCRAItem struct {
+	// The theoretical arrival time of the next hit in nanoseconds
+	TAT int64
+}
+
+type ConcurrencyItem struct {
+	Limit int64
+	// The slots currently held, oldest first
+	Leases []ConcurrencyLease
+}
+
+type ConcurrencyLease struct {
+	// The time in milliseconds the slots are returned if they were not released
+	ExpireAt int64
+	// The number of slots acquired
+	Count int64
+}
+
 // Store interface allows implementors to off load storage of all or a subset of ratelimits to
 // some persistent store. Methods OnChange() and Remove() should avoid blocking where possible
 // to maximize performance of gubernator.
@@ -64,6 +111,80 @@ type Store interface {
 	Remove(ctx context.Context, key string)
 }
 
+// StoreV2 is a Store which honors context deadlines and returns errors, such that a failing store
+// can be told apart from a rate limit which is missing from the store. Use NewStoreV2Adapter()
+// to provide an implementation of Store where a StoreV2 is expected.
+// Implementations MUST be threadsafe.
+type StoreV2 interface {
+	// Called by gubernator *after* a rate limit item is updated. See Store.OnChange()
+	OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) error
+
+	// Called by gubernator when a rate limit is missing from the cache. Should return false
+	// and a nil error if the rate limit does not exist in the store.
+	Get(ctx context.Context, r *RateLimitReq) (*CacheItem, bool, error)
+
+	// Called by gubernator when an existing rate limit should be removed from the store. See Store.Remove()
+	Remove(ctx context.Context, key string) error
+}
+
+// StoreHealthChecker may be implemented by a StoreV2 to report the health
+// of the store through V1Instance.HealthCheck()
+type StoreHealthChecker interface {
+	// HealthCheck returns an error if the store is unhealthy
+	HealthCheck() error
+}
+
+// StoreBatchWriter may be implemented by a StoreV2 to write many changes in a single call,
+// WriteBehindStore uses it to flush pending changes in batches of WriteBehindConfig.BatchSize
+type StoreBatchWriter interface {
+	// WriteBatch writes or removes every rate limit in the batch. If an error is
+	// returned, none of the changes in the batch are considered written.
+	WriteBatch(ctx context.Context, batch []StoreChange) error
+}
+
+// StoreChange is a single change to a rate limit written by StoreBatchWriter.WriteBatch()
+type StoreChange struct {
+	Key string
+	// The request which changed the rate limit, nil if the rate limit was removed
+	Req *RateLimitReq
+	// The rate limit after the change, nil if the rate limit was removed
+	Item *CacheItem
+}
+
+// NewStoreV2Adapter returns a StoreV2 which calls the provided Store. As a Store does
+// not report errors, only an expired or cancelled context is returned as an error.
+func NewStoreV2Adapter(s Store) StoreV2 {
+	return &storeV2Adapter{store: s}
+}
+
+type storeV2Adapter struct {
+	store Store
+}
+
+func (s *storeV2Adapter) OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.store.OnChange(ctx, r, item)
+	return nil
+}
+
+func (s *storeV2Adapter) Get(ctx context.Context, r *RateLimitReq) (*CacheItem, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	item, ok := s.store.Get(ctx, r)
+	return item, ok, nil
+}
+
+func (s *storeV2Adapter) Remove(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.store.Remove(ctx, key)
+	return nil
+}
+
 // Loader interface allows implementors to store all or a subset of ratelimits into a persistent
 // store during startup and shutdown of the gubernator instance.
 type Loader interface {
@@ -77,6 +198,68 @@ type Loader interface {
 	Save(chan *CacheItem) error
 }
 
+// storeErrorReporter provides a StoreV2 to the algorithms as a Store. Errors returned by the
+// StoreV2 are logged, recorded in the trace span and counted by metricStoreErrorCounter. As the
+// algorithms treat a failed Get() as a miss, the failure is remembered until the worker collects
+// it with takeGetError(), and the rate limit is not written back to the store in the meantime
+// such that the state held by the store is not replaced by a new rate limit.
+// Each worker has its own storeErrorReporter, so it is not safe for concurrent use.
+type storeErrorReporter struct {
+	store StoreV2
+	log   FieldLogger
+	// The keys of the rate limits which failed Get() during the current request
+	failed map[string]error
+}
+
+func (s *storeErrorReporter) OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) {
+	if _, ok := s.failed[item.Key]; ok {
+		return
+	}
+	s.report(ctx, "OnChange", item.Key, s.store.OnChange(ctx, r, item))
+}
+
+func (s *storeErrorReporter) Get(ctx context.Context, r *RateLimitReq) (*CacheItem, bool) {
+	item, ok, err := s.store.Get(ctx, r)
+	if err != nil {
+		s.report(ctx, "Get", r.HashKey(), err)
+		if s.failed == nil {
+			s.failed = make(map[string]error)
+		}
+		s.failed[r.HashKey()] = err
+		return nil, false
+	}
+	return item, ok
+}
+
+func (s *storeErrorReporter) Remove(ctx context.Context, key string) {
+	if _, ok := s.failed[key]; ok {
+		return
+	}
+	s.report(ctx, "Remove", key, s.store.Remove(ctx, key))
+}
+
+// takeGetError returns the error from Get() for the rate limit, if any, and forgets it
+func (s *storeErrorReporter) takeGetError(key string) error {
+	err, ok := s.failed[key]
+	if !ok {
+		return nil
+	}
+	delete(s.failed, key)
+	return err
+}
+
+func (s *storeErrorReporter) report(ctx context.Context, method, key string, err error) {
+	if err == nil {
+		return
+	}
+	trace.SpanFromContext(ctx).RecordError(err)
+	metricStoreErrorCounter.WithLabelValues(method).Inc()
+	// The circuit breaker reports on its own when it opens
+	if !errors.Is(err, ErrStoreCircuitOpen) {
+		s.log.WithError(err).Errorf("Error in Store.%s() for rate limit '%s'", method, key)
+	}
+}
+
 func NewMockStore() *MockStore {
 	ml := &MockStore{
 		Called:     make(map[string]int),
@@ -148,3 +331,76 @@ func (ml *MockLoader) Save(in chan *CacheItem) error {
 	}
 	return nil
 }
+
+// storedItem is the JSON representation of a CacheItem written by the redis and SQL stores
+type storedItem struct {
+	Algorithm     Algorithm          `json:"algorithm"`
+	Key           string             `json:"key"`
+	ExpireAt      int64              `json:"expire_at"`
+	TokenBucket   *TokenBucketItem   `json:"token_bucket,omitempty"`
+	LeakyBucket   *LeakyBucketItem   `json:"leaky_bucket,omitempty"`
+	SlidingWindow *SlidingWindowItem `json:"sliding_window,omitempty"`
+	GCRA          *GCRAItem          `json:"gcra,omitempty"`
+	Concurrency   *ConcurrencyItem   `json:"concurrency,omitempty"`
+
+	ContinuousTokenBucket *ContinuousTokenBucketItem `json:"continuous_token_bucket,omitempty"`
+}
+
+// marshalCacheItem encodes the item as JSON for the stores which keep rate limits in an external service
+func marshalCacheItem(item *CacheItem) ([]byte, error) {
+	si := storedItem{
+		Algorithm: item.Algorithm,
+		Key:       item.Key,
+		ExpireAt:  item.ExpireAt,
+	}
+
+	switch v := item.Value.(type) {
+	case *TokenBucketItem:
+		si.TokenBucket = v
+	case *LeakyBucketItem:
+		si.LeakyBucket = v
+	case *SlidingWindowItem:
+		si.SlidingWindow = v
+	case *GCRAItem:
+		si.GCRA = v
+	case *ConcurrencyItem:
+		si.Concurrency = v
+	case *ContinuousTokenBucketItem:
+		si.ContinuousTokenBucket = v
+	default:
+		return nil, errors.Errorf("unknown cache item value type '%T'", item.Value)
+	}
+	return json.Marshal(si)
+}
+
+// unmarshalCacheItem decodes an item encoded by marshalCacheItem()
+func unmarshalCacheItem(b []byte) (*CacheItem, error) {
+	var si storedItem
+	if err := json.Unmarshal(b, &si); err != nil {
+		return nil, err
+	}
+
+	item := &CacheItem{
+		Algorithm: si.Algorithm,
+		Key:       si.Key,
+		ExpireAt:  si.ExpireAt,
+	}
+
+	switch {
+	case si.TokenBucket != nil:
+		item.Value = si.TokenBucket
+	case si.LeakyBucket != nil:
+		item.Value = si.LeakyBucket
+	case si.SlidingWindow != nil:
+		item.Value = si.SlidingWindow
+	case si.GCRA != nil:
+		item.Value = si.GCRA
+	case si.Concurrency != nil:
+		item.Value = si.Concurrency
+	case si.ContinuousTokenBucket != nil:
+		item.Value = si.ContinuousTokenBucket
+	default:
+		return nil, errors.New("rate limit has no value")
+	}
+	return item, nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type v1Server struct {
@@ -73,6 +74,30 @@ func newV1Server(t *testing.T, address string, conf gubernator.Config) *v1Server
 	}
 }
 
+// hit sends the hits to a rate limit which allows 10 hits per minute, closing its
+// connection to the instance once the response is received.
+func (s *v1Server) hit(t *testing.T, name, key string, hits int64) *gubernator.RateLimitResp {
+	t.Helper()
+	conn, err := grpc.Dial(s.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := gubernator.NewV1Client(conn).GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+		Requests: []*gubernator.RateLimitReq{
+			{
+				Name:      name,
+				UniqueKey: key,
+				Duration:  gubernator.Minute,
+				Limit:     10,
+				Hits:      hits,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "", resp.Responses[0].Error)
+	return resp.Responses[0]
+}
+
 func TestLoader(t *testing.T) {
 	loader := gubernator.NewMockLoader()
 
@@ -186,6 +211,43 @@ func TestStore(t *testing.T) {
 					litem.Duration == req.Duration
 			})
 
+		case gubernator.Algorithm_SLIDING_WINDOW:
+			return mock.MatchedBy(func(item *gubernator.CacheItem) bool {
+				witem, ok := item.Value.(*gubernator.SlidingWindowItem)
+				if !ok {
+					return false
+				}
+
+				return item.Algorithm == req.Algorithm &&
+					item.Key == req.HashKey() &&
+					witem.Limit == req.Limit &&
+					witem.Duration == req.Duration
+			})
+
+		case gubernator.Algorithm_GCRA:
+			return mock.MatchedBy(func(item *gubernator.CacheItem) bool {
+				_, ok := item.Value.(*gubernator.GCRAItem)
+				if !ok {
+					return false
+				}
+
+				return item.Algorithm == req.Algorithm &&
+					item.Key == req.HashKey()
+			})
+
+		case gubernator.Algorithm_CONTINUOUS_TOKEN_BUCKET:
+			return mock.MatchedBy(func(item *gubernator.CacheItem) bool {
+				bitem, ok := item.Value.(*gubernator.ContinuousTokenBucketItem)
+				if !ok {
+					return false
+				}
+
+				return item.Algorithm == req.Algorithm &&
+					item.Key == req.HashKey() &&
+					bitem.Limit == req.Limit &&
+					bitem.Duration == req.Duration
+			})
+
 		default:
 			assert.Fail(t, "Unknown algorithm")
 			return nil
@@ -210,6 +272,27 @@ func TestStore(t *testing.T) {
 				UpdatedAt: gubernator.MillisecondNow(),
 			}
 
+		case gubernator.Algorithm_SLIDING_WINDOW:
+			return &gubernator.SlidingWindowItem{
+				Limit:       req.Limit,
+				Duration:    req.Duration,
+				WindowStart: gubernator.MillisecondNow(),
+			}
+
+		case gubernator.Algorithm_GCRA:
+			return &gubernator.GCRAItem{
+				TAT: clock.Now().UnixNano(),
+			}
+
+		case gubernator.Algorithm_CONTINUOUS_TOKEN_BUCKET:
+			return &gubernator.ContinuousTokenBucketItem{
+				Limit:     req.Limit,
+				Duration:  req.Duration,
+				Burst:     req.Limit,
+				Tokens:    float64(req.Limit),
+				UpdatedAt: gubernator.MillisecondNow(),
+			}
+
 		default:
 			assert.Fail(t, "Unknown algorithm")
 			return nil
@@ -222,6 +305,9 @@ func TestStore(t *testing.T) {
 	}{
 		{"Token bucket", gubernator.Algorithm_TOKEN_BUCKET},
 		{"Leaky bucket", gubernator.Algorithm_LEAKY_BUCKET},
+		{"Sliding window", gubernator.Algorithm_SLIDING_WINDOW},
+		{"GCRA", gubernator.Algorithm_GCRA},
+		{"Continuous token bucket", gubernator.Algorithm_CONTINUOUS_TOKEN_BUCKET},
 	}
 
 	for _, testCase := range testCases {
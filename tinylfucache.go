@@ -0,0 +1,483 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mailgun/holster/v4/setter"
+	"github.com/segmentio/fasthash/fnv1"
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+const (
+	// The smallest number of items held by a single shard of the TinyLFUCache
+	tinyLFUMinShardSize = 256
+	tinyLFUMaxShards    = 16
+)
+
+// TinyLFUCache is a thread-safe cache which uses the W-TinyLFU eviction policy. New items enter a
+// small LRU window, and when evicted from the window they are only admitted to the main cache if
+// they were accessed more often than the item they would replace. Frequencies are estimated by a
+// count-min sketch which is periodically aged, such that items which were popular in the past do
+// not stay in the cache forever. Compared to LRUCache this keeps frequently used rate limits in
+// the cache when a large number of rate limits are only hit once, such as during a scan.
+//
+// The cache is split into shards by key, each guarded by its own mutex. It reports the same
+// metrics as LRUCache through LRUCacheCollector.
+type TinyLFUCache struct {
+	shards     []*tinyLFUShard
+	mask       uint64
+	cacheLen   int64
+	cacheBytes int64
+}
+
+var _ MemoryBoundedCache = &TinyLFUCache{}
+
+// The segment of a shard which holds an entry
+const (
+	tinyLFUWindow = iota
+	tinyLFUProbation
+	tinyLFUProtected
+)
+
+// tinyLFUItemBytes is the typical estimated size of an item, used to size the sketch when
+// the cache is limited by memory
+const tinyLFUItemBytes = 200
+
+type tinyLFUEntry struct {
+	item    *CacheItem
+	segment int
+	// The estimated size of the item when it was last added or accessed
+	bytes int64
+	// The share of the capacity used by the item, which is either 1 or the estimated size
+	weight int64
+}
+
+// tinyLFUShard holds a portion of the cache. Capacities and weights are counted in items,
+// or in estimated bytes once SetMaxBytes() is called.
+type tinyLFUShard struct {
+	mutex    sync.Mutex
+	parent   *TinyLFUCache
+	cache    map[string]*list.Element
+	sketch   *countMinSketch
+	useBytes bool
+
+	// Recently added items which have not yet been admitted to the main cache
+	window       *list.List
+	windowWeight int64
+	windowCap    int64
+	// Items in the main cache which were not accessed since they were admitted
+	probation       *list.List
+	probationWeight int64
+	// Items in the main cache which were accessed since they were admitted
+	protected       *list.List
+	protectedWeight int64
+	protectedCap    int64
+	mainCap         int64
+}
+
+// NewTinyLFUCache creates a new Cache with a maximum size.
+func NewTinyLFUCache(maxSize int) *TinyLFUCache {
+	setter.SetDefault(&maxSize, 50_000)
+
+	shards := 1
+	for shards < tinyLFUMaxShards && maxSize/(shards*2) >= tinyLFUMinShardSize {
+		shards *= 2
+	}
+
+	c := &TinyLFUCache{
+		shards: make([]*tinyLFUShard, shards),
+		mask:   uint64(shards - 1),
+	}
+	for i := range c.shards {
+		c.shards[i] = &tinyLFUShard{
+			parent:    c,
+			cache:     make(map[string]*list.Element),
+			window:    list.New(),
+			probation: list.New(),
+			protected: list.New(),
+		}
+		c.shards[i].setCapacity(splitCapacity(int64(maxSize), shards, i), maxSize/shards)
+	}
+	return c
+}
+
+// splitCapacity returns the share of the capacity held by shard i
+func splitCapacity(capacity int64, shards, i int) int64 {
+	share := capacity / int64(shards)
+	if int64(i) < capacity%int64(shards) {
+		share++
+	}
+	return share
+}
+
+// setCapacity sizes the segments of the shard, and the sketch for the expected number of items
+func (s *tinyLFUShard) setCapacity(capacity int64, items int) {
+	// The window holds 1% of the capacity, and the protected segment 80% of the main cache
+	s.windowCap = capacity / 100
+	if s.windowCap < 1 {
+		s.windowCap = 1
+	}
+	s.mainCap = capacity - s.windowCap
+	if s.mainCap < 1 {
+		s.mainCap = 1
+	}
+	s.protectedCap = s.mainCap * 8 / 10
+	s.sketch = newCountMinSketch(items)
+}
+
+// shard uses a different hash than the sketch, as every key in a shard shares the low bits of the hash
+func (c *TinyLFUCache) shard(key string) *tinyLFUShard {
+	return c.shards[fnv1.HashString64(key)&c.mask]
+}
+
+// Add adds a value to the cache.
+func (c *TinyLFUCache) Add(item *CacheItem) bool {
+	return c.shard(item.Key).add(item)
+}
+
+// GetItem returns the item stored in the cache
+func (c *TinyLFUCache) GetItem(key string) (*CacheItem, bool) {
+	return c.shard(key).getItem(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *TinyLFUCache) Remove(key string) {
+	s := c.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ele, ok := s.cache[key]; ok {
+		s.removeElement(ele)
+	}
+}
+
+// UpdateExpiration updates the expiration time for the key
+func (c *TinyLFUCache) UpdateExpiration(key string, expireAt int64) bool {
+	s := c.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ele, ok := s.cache[key]; ok {
+		ele.Value.(*tinyLFUEntry).item.ExpireAt = expireAt
+		return true
+	}
+	return false
+}
+
+// Each returns the items in the cache at the time Each was called, skipping those which have expired
+// or been invalidated. Unlike LRUCache, it is safe to modify the cache while iterating.
+func (c *TinyLFUCache) Each() chan *CacheItem {
+	var items []*CacheItem
+	now := MillisecondNow()
+	for _, s := range c.shards {
+		s.mutex.Lock()
+		for _, ele := range s.cache {
+			item := ele.Value.(*tinyLFUEntry).item
+			if item.isExpired(now) {
+				continue
+			}
+			items = append(items, item)
+		}
+		s.mutex.Unlock()
+	}
+
+	out := make(chan *CacheItem)
+	go func() {
+		for _, item := range items {
+			out <- item
+		}
+		close(out)
+	}()
+	return out
+}
+
+// Size returns the number of items in the cache.
+func (c *TinyLFUCache) Size() int64 {
+	return atomic.LoadInt64(&c.cacheLen)
+}
+
+// SetMaxBytes limits the estimated memory used by the items in the cache, after which the
+// maximum number of items is ignored. Must be called before any items are added.
+func (c *TinyLFUCache) SetMaxBytes(maxBytes int64) {
+	for i, s := range c.shards {
+		s.mutex.Lock()
+		capacity := splitCapacity(maxBytes, len(c.shards), i)
+		s.useBytes = true
+		s.setCapacity(capacity, int(capacity/tinyLFUItemBytes))
+		s.mutex.Unlock()
+	}
+}
+
+// Bytes returns the estimated memory used by the items in the cache.
+func (c *TinyLFUCache) Bytes() int64 {
+	return atomic.LoadInt64(&c.cacheBytes)
+}
+
+func (c *TinyLFUCache) Close() error {
+	for _, s := range c.shards {
+		s.mutex.Lock()
+		s.cache = make(map[string]*list.Element)
+		s.window.Init()
+		s.probation.Init()
+		s.protected.Init()
+		s.windowWeight, s.probationWeight, s.protectedWeight = 0, 0, 0
+		s.mutex.Unlock()
+	}
+	atomic.StoreInt64(&c.cacheLen, 0)
+	atomic.StoreInt64(&c.cacheBytes, 0)
+	return nil
+}
+
+func (s *tinyLFUShard) add(item *CacheItem) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sketch.Increment(item.Key)
+
+	// If the key already exist, set the new value
+	if ele, ok := s.cache[item.Key]; ok {
+		ele.Value.(*tinyLFUEntry).item = item
+		s.onAccess(ele)
+		return true
+	}
+
+	entry := &tinyLFUEntry{item: item, segment: tinyLFUWindow}
+	s.cache[item.Key] = s.window.PushFront(entry)
+	atomic.AddInt64(&s.parent.cacheLen, 1)
+	s.resize(entry)
+
+	for s.windowWeight > s.windowCap && s.window.Len() > 0 {
+		s.admit(s.window.Back())
+	}
+	return false
+}
+
+func (s *tinyLFUShard) getItem(key string) (*CacheItem, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sketch.Increment(key)
+
+	ele, ok := s.cache[key]
+	if !ok {
+		metricCacheAccess.WithLabelValues("miss").Add(1)
+		return nil, false
+	}
+
+	entry := ele.Value.(*tinyLFUEntry)
+	now := MillisecondNow()
+	// If the entry is invalidated or expired, remove it from the cache
+	if entry.item.isExpired(now) {
+		s.removeElement(ele)
+		metricCacheAccess.WithLabelValues("miss").Add(1)
+		return nil, false
+	}
+
+	metricCacheAccess.WithLabelValues("hit").Add(1)
+	s.onAccess(ele)
+	return entry.item, true
+}
+
+// resize updates the estimated size of an item, which may have grown since it was added
+func (s *tinyLFUShard) resize(entry *tinyLFUEntry) {
+	bytes := estimateCacheItemBytes(entry.item)
+	atomic.AddInt64(&s.parent.cacheBytes, bytes-entry.bytes)
+	entry.bytes = bytes
+
+	weight := int64(1)
+	if s.useBytes {
+		weight = bytes
+	}
+	*s.segmentWeight(entry.segment) += weight - entry.weight
+	entry.weight = weight
+}
+
+func (s *tinyLFUShard) segmentWeight(segment int) *int64 {
+	switch segment {
+	case tinyLFUWindow:
+		return &s.windowWeight
+	case tinyLFUProbation:
+		return &s.probationWeight
+	default:
+		return &s.protectedWeight
+	}
+}
+
+func (s *tinyLFUShard) segmentList(segment int) *list.List {
+	switch segment {
+	case tinyLFUWindow:
+		return s.window
+	case tinyLFUProbation:
+		return s.probation
+	default:
+		return s.protected
+	}
+}
+
+// moveTo pushes the entry to the front of the segment, after it was removed from its previous segment
+func (s *tinyLFUShard) moveTo(entry *tinyLFUEntry, segment int) {
+	*s.segmentWeight(entry.segment) -= entry.weight
+	entry.segment = segment
+	*s.segmentWeight(segment) += entry.weight
+	s.cache[entry.item.Key] = s.segmentList(segment).PushFront(entry)
+}
+
+// onAccess moves the element to the front of its segment, promoting items in the
+// probation segment to the protected segment.
+func (s *tinyLFUShard) onAccess(ele *list.Element) {
+	entry := ele.Value.(*tinyLFUEntry)
+	s.resize(entry)
+	switch entry.segment {
+	case tinyLFUWindow:
+		s.window.MoveToFront(ele)
+	case tinyLFUProtected:
+		s.protected.MoveToFront(ele)
+	case tinyLFUProbation:
+		s.probation.Remove(ele)
+		s.moveTo(entry, tinyLFUProtected)
+
+		// Demote the least recently used protected items back to probation
+		for s.protectedWeight > s.protectedCap && s.protected.Len() > 1 {
+			s.moveTo(s.protected.Remove(s.protected.Back()).(*tinyLFUEntry), tinyLFUProbation)
+		}
+	}
+}
+
+// admit moves the candidate evicted from the window into the main cache if there is room, or if
+// it is used more often than the items which the main cache would evict. Otherwise the candidate
+// is evicted.
+func (s *tinyLFUShard) admit(ele *list.Element) {
+	candidate := s.window.Remove(ele).(*tinyLFUEntry)
+	s.windowWeight -= candidate.weight
+
+	if candidate.weight > s.mainCap {
+		s.evict(candidate)
+		return
+	}
+
+	for s.probationWeight+s.protectedWeight+candidate.weight > s.mainCap {
+		victim := s.probation.Back()
+		if victim == nil {
+			victim = s.protected.Back()
+		}
+		victimEntry := victim.Value.(*tinyLFUEntry)
+
+		if s.sketch.Estimate(candidate.item.Key) <= s.sketch.Estimate(victimEntry.item.Key) {
+			s.evict(candidate)
+			return
+		}
+		s.removeElement(victim)
+		s.evictMetric(victimEntry)
+	}
+
+	candidate.segment = tinyLFUProbation
+	s.probationWeight += candidate.weight
+	s.cache[candidate.item.Key] = s.probation.PushFront(candidate)
+}
+
+// evict removes an entry which has already been removed from its segment
+func (s *tinyLFUShard) evict(entry *tinyLFUEntry) {
+	delete(s.cache, entry.item.Key)
+	atomic.AddInt64(&s.parent.cacheLen, -1)
+	atomic.AddInt64(&s.parent.cacheBytes, -entry.bytes)
+	s.evictMetric(entry)
+}
+
+func (s *tinyLFUShard) evictMetric(entry *tinyLFUEntry) {
+	if MillisecondNow() < entry.item.ExpireAt {
+		metricCacheUnexpiredEvictions.Add(1)
+	}
+}
+
+func (s *tinyLFUShard) removeElement(ele *list.Element) {
+	entry := ele.Value.(*tinyLFUEntry)
+	s.segmentList(entry.segment).Remove(ele)
+	*s.segmentWeight(entry.segment) -= entry.weight
+	delete(s.cache, entry.item.Key)
+	atomic.AddInt64(&s.parent.cacheLen, -1)
+	atomic.AddInt64(&s.parent.cacheBytes, -entry.bytes)
+}
+
+// countMinSketch estimates how often each key was accessed using 4 rows of saturating counters.
+// Once the number of increments reaches 10 times the size of the cache, every counter is halved.
+type countMinSketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+const countMinSketchMax = 15
+
+func newCountMinSketch(size int) *countMinSketch {
+	width := 16
+	for width < size {
+		width *= 2
+	}
+
+	s := &countMinSketch{
+		mask:    uint64(width - 1),
+		resetAt: size * 10,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *countMinSketch) index(hash uint64, row int) uint64 {
+	h1, h2 := hash&0xffffffff, hash>>32
+	return (h1 + uint64(row)*h2) & s.mask
+}
+
+func (s *countMinSketch) Increment(key string) {
+	hash := fnv1a.HashString64(key)
+	for i := range s.rows {
+		idx := s.index(hash, i)
+		if s.rows[i][idx] < countMinSketchMax {
+			s.rows[i][idx]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) Estimate(key string) uint8 {
+	hash := fnv1a.HashString64(key)
+	min := uint8(countMinSketchMax)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(hash, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// reset ages the counters, such that keys which are no longer accessed lose their frequency
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] /= 2
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTinyLFUCache(t *testing.T) {
+	expireAt := clock.Now().Add(1 * time.Hour).UnixMilli()
+
+	t.Run("Happy path", func(t *testing.T) {
+		const iterations = 1000
+		cache := gubernator.NewTinyLFUCache(0)
+
+		for i := 0; i < iterations; i++ {
+			exists := cache.Add(&gubernator.CacheItem{Key: strconv.Itoa(i), Value: i, ExpireAt: expireAt})
+			assert.False(t, exists)
+		}
+		assert.Equal(t, int64(iterations), cache.Size())
+
+		for i := 0; i < iterations; i++ {
+			item, ok := cache.GetItem(strconv.Itoa(i))
+			require.True(t, ok)
+			assert.Equal(t, i, item.Value)
+		}
+
+		var count int
+		for range cache.Each() {
+			count++
+		}
+		assert.Equal(t, iterations, count)
+
+		for i := 0; i < iterations; i++ {
+			cache.Remove(strconv.Itoa(i))
+		}
+		assert.Zero(t, cache.Size())
+	})
+
+	t.Run("Each skips expired and invalidated items", func(t *testing.T) {
+		cache := gubernator.NewTinyLFUCache(0)
+		past := clock.Now().Add(-time.Minute).UnixMilli()
+		cache.Add(&gubernator.CacheItem{Key: "live", ExpireAt: expireAt})
+		cache.Add(&gubernator.CacheItem{Key: "expired", ExpireAt: past})
+		cache.Add(&gubernator.CacheItem{Key: "invalidated", ExpireAt: expireAt, InvalidAt: past})
+
+		var keys []string
+		for item := range cache.Each() {
+			keys = append(keys, item.Key)
+		}
+		assert.Equal(t, []string{"live"}, keys)
+	})
+
+	t.Run("Update an existing key", func(t *testing.T) {
+		cache := gubernator.NewTinyLFUCache(0)
+		const key = "foobar"
+
+		assert.False(t, cache.Add(&gubernator.CacheItem{Key: key, Value: "initial value", ExpireAt: expireAt}))
+		assert.True(t, cache.Add(&gubernator.CacheItem{Key: key, Value: "new value", ExpireAt: expireAt}))
+		assert.True(t, cache.UpdateExpiration(key, expireAt+1))
+		assert.Equal(t, int64(1), cache.Size())
+
+		item, ok := cache.GetItem(key)
+		require.True(t, ok)
+		assert.Equal(t, "new value", item.Value)
+		assert.Equal(t, expireAt+1, item.ExpireAt)
+	})
+
+	t.Run("Expired items are removed", func(t *testing.T) {
+		cache := gubernator.NewTinyLFUCache(0)
+		cache.Add(&gubernator.CacheItem{Key: "expired", ExpireAt: clock.Now().UnixMilli() - 1})
+		cache.Add(&gubernator.CacheItem{Key: "invalid", ExpireAt: expireAt, InvalidAt: clock.Now().UnixMilli() - 1})
+
+		_, ok := cache.GetItem("expired")
+		assert.False(t, ok)
+		_, ok = cache.GetItem("invalid")
+		assert.False(t, ok)
+		assert.Zero(t, cache.Size())
+	})
+
+	t.Run("Size never exceeds the max size", func(t *testing.T) {
+		cache := gubernator.NewTinyLFUCache(1000)
+		for i := 0; i < 10_000; i++ {
+			cache.Add(&gubernator.CacheItem{Key: strconv.Itoa(i), ExpireAt: expireAt})
+			require.LessOrEqual(t, cache.Size(), int64(1000))
+		}
+	})
+
+	t.Run("Frequently used items survive a scan", func(t *testing.T) {
+		const size = 1000
+		for _, tt := range []struct {
+			cache    gubernator.Cache
+			survives bool
+		}{
+			{cache: gubernator.NewTinyLFUCache(size), survives: true},
+			{cache: gubernator.NewLRUCache(size), survives: false},
+		} {
+			// A working set which is accessed repeatedly
+			for n := 0; n < 5; n++ {
+				for i := 0; i < size/2; i++ {
+					key := fmt.Sprintf("hot:%d", i)
+					if _, ok := tt.cache.GetItem(key); !ok {
+						tt.cache.Add(&gubernator.CacheItem{Key: key, ExpireAt: expireAt})
+					}
+				}
+			}
+
+			// Followed by a scan of keys which are only used once
+			for i := 0; i < size*10; i++ {
+				tt.cache.Add(&gubernator.CacheItem{Key: fmt.Sprintf("scan:%d", i), ExpireAt: expireAt})
+			}
+
+			var hits int
+			for i := 0; i < size/2; i++ {
+				if _, ok := tt.cache.GetItem(fmt.Sprintf("hot:%d", i)); ok {
+					hits++
+				}
+			}
+			if tt.survives {
+				assert.Greater(t, hits, size/2*9/10, "%T", tt.cache)
+			} else {
+				assert.Zero(t, hits, "%T", tt.cache)
+			}
+		}
+	})
+
+	t.Run("Concurrent reads and writes", func(t *testing.T) {
+		const iterations = 1000
+		const concurrency = 100
+		cache := gubernator.NewTinyLFUCache(iterations * 10)
+		var wg sync.WaitGroup
+
+		// No mutex is required
+		for thread := 0; thread < concurrency; thread++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					key := strconv.Itoa(i)
+					cache.Add(&gubernator.CacheItem{Key: key, Value: i, ExpireAt: expireAt})
+					item, ok := cache.GetItem(key)
+					if ok {
+						assert.Equal(t, i, item.Value)
+					}
+					if i%10 == 0 {
+						cache.Remove(key)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		var count int64
+		for range cache.Each() {
+			count++
+		}
+		assert.Equal(t, cache.Size(), count)
+	})
+
+	t.Run("Reports metrics through LRUCacheCollector", func(t *testing.T) {
+		cache := gubernator.NewTinyLFUCache(100)
+		collector := gubernator.NewLRUCacheCollector()
+		collector.AddCache(cache)
+
+		evictionsBefore := collectorValue(t, collector, "gubernator_unexpired_evictions_count")
+		hitsBefore := collectorValue(t, collector, "gubernator_cache_access_count", "hit")
+		for i := 0; i < 200; i++ {
+			cache.Add(&gubernator.CacheItem{Key: strconv.Itoa(i), ExpireAt: expireAt})
+		}
+		_, _ = cache.GetItem("199")
+
+		assert.Equal(t, float64(cache.Size()), collectorValue(t, collector, "gubernator_cache_size"))
+		assert.Equal(t, float64(200-cache.Size()),
+			collectorValue(t, collector, "gubernator_unexpired_evictions_count")-evictionsBefore)
+		assert.Equal(t, float64(1), collectorValue(t, collector, "gubernator_cache_access_count", "hit")-hitsBefore)
+	})
+
+	t.Run("Evicts by estimated bytes when SetMaxBytes is called", func(t *testing.T) {
+		const maxBytes = 100_000
+		cache := gubernator.NewTinyLFUCache(0)
+		cache.SetMaxBytes(maxBytes)
+
+		for i := 0; i < 10_000; i++ {
+			cache.Add(&gubernator.CacheItem{
+				Key:      strconv.Itoa(i),
+				Value:    &gubernator.TokenBucketItem{Limit: 100},
+				ExpireAt: expireAt,
+			})
+			require.LessOrEqual(t, cache.Bytes(), int64(maxBytes))
+		}
+		assert.Greater(t, cache.Size(), int64(100))
+		assert.Less(t, cache.Size(), int64(10_000))
+
+		cache.Add(&gubernator.CacheItem{
+			Key:      "large",
+			Value:    &gubernator.ConcurrencyItem{Leases: make([]gubernator.ConcurrencyLease, 0, 1000)},
+			ExpireAt: expireAt,
+		})
+		assert.LessOrEqual(t, cache.Bytes(), int64(maxBytes))
+
+		require.NoError(t, cache.Close())
+		assert.Zero(t, cache.Bytes())
+	})
+}
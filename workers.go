@@ -39,11 +39,14 @@ package gubernator
 import (
 	"context"
 	"io"
+	"reflect"
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/OneOfOne/xxhash"
+	"github.com/mailgun/holster/v4/clock"
 	"github.com/mailgun/holster/v4/errors"
 	"github.com/mailgun/holster/v4/setter"
 	"github.com/prometheus/client_golang/prometheus"
@@ -61,14 +64,26 @@ type WorkerPool struct {
 }
 
 type Worker struct {
-	name                string
-	conf                *Config
-	cache               Cache
-	getRateLimitRequest chan request
-	storeRequest        chan workerStoreRequest
-	loadRequest         chan workerLoadRequest
-	addCacheItemRequest chan workerAddCacheItemRequest
-	getCacheItemRequest chan workerGetCacheItemRequest
+	name                   string
+	conf                   *Config
+	cache                  Cache
+	store                  Store
+	getRateLimitRequest    chan request
+	storeRequest           chan workerStoreRequest
+	loadRequest            chan workerLoadRequest
+	addCacheItemRequest    chan workerAddCacheItemRequest
+	getCacheItemRequest    chan workerGetCacheItemRequest
+	removeCacheItemRequest chan workerRemoveCacheItemRequest
+	eachCacheItemRequest   chan workerEachCacheItemRequest
+	restoreRequest         chan workerRestoreRequest
+
+	// The keys of the items which changed since the last snapshot, only
+	// tracked when Config.CheckpointInterval is set.
+	changed map[string]struct{}
+
+	// Fires when expired items should be swept from the cache, nil when the
+	// cache does not implement SweepableCache or the sweep is disabled.
+	sweepTick clock.Ticker
 }
 
 type workerHasher interface {
@@ -81,12 +96,15 @@ type hasher struct{}
 
 // Method request/response structs.
 type workerStoreRequest struct {
-	ctx      context.Context
-	response chan workerStoreResponse
-	out      chan<- *CacheItem
+	ctx         context.Context
+	response    chan workerStoreResponse
+	changedOnly bool
 }
 
-type workerStoreResponse struct{}
+type workerStoreResponse struct {
+	items   []*CacheItem
+	changed bool
+}
 
 type workerLoadRequest struct {
 	ctx      context.Context
@@ -100,10 +118,13 @@ type workerAddCacheItemRequest struct {
 	ctx      context.Context
 	response chan workerAddCacheItemResponse
 	item     *CacheItem
+	// If set, an unexpired item already in the cache is only replaced when replace() returns true
+	replace func(existing *CacheItem) bool
 }
 
 type workerAddCacheItemResponse struct {
 	exists bool
+	added  bool
 }
 
 type workerGetCacheItemRequest struct {
@@ -117,6 +138,34 @@ type workerGetCacheItemResponse struct {
 	ok   bool
 }
 
+type workerRemoveCacheItemRequest struct {
+	ctx      context.Context
+	response chan workerRemoveCacheItemResponse
+	key      string
+}
+
+type workerRemoveCacheItemResponse struct{}
+
+type workerEachCacheItemRequest struct {
+	ctx      context.Context
+	response chan workerEachCacheItemResponse
+	fn       func(*CacheItem)
+}
+
+type workerEachCacheItemResponse struct{}
+
+type workerRestoreRequest struct {
+	ctx      context.Context
+	response chan workerRestoreResponse
+	request  *RateLimitReq
+	before   *CacheItem
+	after    *CacheItem
+}
+
+type workerRestoreResponse struct {
+	restored bool
+}
+
 var _ io.Closer = &WorkerPool{}
 var _ workerHasher = &hasher{}
 
@@ -162,13 +211,34 @@ func (p *WorkerPool) Close() error {
 // Create a new pool worker instance.
 func (p *WorkerPool) newWorker() *Worker {
 	worker := &Worker{
-		conf:                p.conf,
-		cache:               p.conf.CacheFactory(p.workerCacheSize),
-		getRateLimitRequest: make(chan request),
-		storeRequest:        make(chan workerStoreRequest),
-		loadRequest:         make(chan workerLoadRequest),
-		addCacheItemRequest: make(chan workerAddCacheItemRequest),
-		getCacheItemRequest: make(chan workerGetCacheItemRequest),
+		conf:                   p.conf,
+		cache:                  p.conf.CacheFactory(p.workerCacheSize),
+		getRateLimitRequest:    make(chan request),
+		storeRequest:           make(chan workerStoreRequest),
+		loadRequest:            make(chan workerLoadRequest),
+		addCacheItemRequest:    make(chan workerAddCacheItemRequest),
+		getCacheItemRequest:    make(chan workerGetCacheItemRequest),
+		removeCacheItemRequest: make(chan workerRemoveCacheItemRequest),
+		eachCacheItemRequest:   make(chan workerEachCacheItemRequest),
+		restoreRequest:         make(chan workerRestoreRequest),
+	}
+	if p.conf.CacheMaxBytes > 0 {
+		if cache, ok := worker.cache.(MemoryBoundedCache); ok {
+			cache.SetMaxBytes(p.conf.CacheMaxBytes / int64(p.conf.Workers))
+		} else {
+			p.conf.Logger.Warnf("CacheMaxBytes is ignored; cache '%T' does not implement MemoryBoundedCache", worker.cache)
+		}
+	}
+	if _, ok := worker.cache.(SweepableCache); ok && p.conf.CacheSweepInterval > 0 {
+		worker.sweepTick = clock.NewTicker(p.conf.CacheSweepInterval)
+	}
+	if p.conf.StoreV2 != nil {
+		worker.store = &storeErrorReporter{store: p.conf.StoreV2, log: p.conf.Logger}
+	} else if p.conf.Store != nil {
+		worker.store = p.conf.Store
+	}
+	if p.conf.CheckpointInterval > 0 {
+		worker.changed = make(map[string]struct{})
 	}
 	workerNumber := atomic.AddInt64(&workerCounter, 1) - 1
 	worker.name = strconv.FormatInt(workerNumber, 10)
@@ -188,6 +258,13 @@ func (p *WorkerPool) getWorker(key string) *Worker {
 // A hash ring will distribute requests to an assigned worker by key.
 // See: getWorker()
 func (p *WorkerPool) dispatch(worker *Worker) {
+	// A nil channel never fires when the sweep is disabled
+	var sweep <-chan time.Time
+	if worker.sweepTick != nil {
+		sweep = worker.sweepTick.C()
+		defer worker.sweepTick.Stop()
+	}
+
 	for {
 		// Dispatch requests from each channel.
 		select {
@@ -199,7 +276,14 @@ func (p *WorkerPool) dispatch(worker *Worker) {
 			}
 
 			resp := new(response)
+			if req.reserve {
+				resp.before = cachedCopy(worker.cache, req.request.HashKey())
+			}
 			resp.rl, resp.err = worker.handleGetRateLimit(req.ctx, req.request, worker.cache)
+			if req.reserve {
+				resp.after = cachedCopy(worker.cache, req.request.HashKey())
+			}
+			worker.markChanged(req.request.HashKey())
 			select {
 			case req.resp <- resp:
 				// Success.
@@ -238,6 +322,7 @@ func (p *WorkerPool) dispatch(worker *Worker) {
 			}
 
 			worker.handleAddCacheItem(req, worker.cache)
+			worker.markChanged(req.item.Key)
 			metricCommandCounter.WithLabelValues(worker.name, "AddCacheItem").Inc()
 
 		case req, ok := <-worker.getCacheItemRequest:
@@ -250,6 +335,41 @@ func (p *WorkerPool) dispatch(worker *Worker) {
 			worker.handleGetCacheItem(req, worker.cache)
 			metricCommandCounter.WithLabelValues(worker.name, "GetCacheItem").Inc()
 
+		case req, ok := <-worker.removeCacheItemRequest:
+			if !ok {
+				// Channel closed.  Unexpected, but should be handled.
+				logrus.Error("workerPool worker stopped because channel closed")
+				return
+			}
+
+			worker.handleRemoveCacheItem(req, worker.cache)
+			worker.markChanged(req.key)
+			metricCommandCounter.WithLabelValues(worker.name, "RemoveCacheItem").Inc()
+
+		case req, ok := <-worker.eachCacheItemRequest:
+			if !ok {
+				// Channel closed.  Unexpected, but should be handled.
+				logrus.Error("workerPool worker stopped because channel closed")
+				return
+			}
+
+			worker.handleEachCacheItem(req, worker.cache)
+			metricCommandCounter.WithLabelValues(worker.name, "EachCacheItem").Inc()
+
+		case req, ok := <-worker.restoreRequest:
+			if !ok {
+				// Channel closed.  Unexpected, but should be handled.
+				logrus.Error("workerPool worker stopped because channel closed")
+				return
+			}
+
+			worker.handleRestore(req, worker.cache)
+			worker.markChanged(req.request.HashKey())
+			metricCommandCounter.WithLabelValues(worker.name, "Restore").Inc()
+
+		case <-sweep:
+			worker.handleSweep(worker.cache.(SweepableCache))
+
 		case <-p.done:
 			// Clean up.
 			return
@@ -259,6 +379,25 @@ func (p *WorkerPool) dispatch(worker *Worker) {
 
 // GetRateLimit sends a GetRateLimit request to worker pool.
 func (p *WorkerPool) GetRateLimit(ctx context.Context, rlRequest *RateLimitReq) (retval *RateLimitResp, reterr error) {
+	resp, err := p.getRateLimit(ctx, rlRequest, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.rl, resp.err
+}
+
+// ReserveRateLimit applies the request like GetRateLimit, and also returns copies of the
+// cached item before and after the request was applied, which are nil if the item was not
+// cached. The copies are passed to RestoreCacheItem() to undo the request.
+func (p *WorkerPool) ReserveRateLimit(ctx context.Context, rlRequest *RateLimitReq) (rl *RateLimitResp, before, after *CacheItem, err error) {
+	resp, err := p.getRateLimit(ctx, rlRequest, true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return resp.rl, resp.before, resp.after, resp.err
+}
+
+func (p *WorkerPool) getRateLimit(ctx context.Context, rlRequest *RateLimitReq, reserve bool) (*response, error) {
 	// Delegate request to assigned channel based on request key.
 	worker := p.getWorker(rlRequest.HashKey())
 	queueGauge := metricWorkerQueue.WithLabelValues("GetRateLimit", worker.name)
@@ -268,6 +407,7 @@ func (p *WorkerPool) GetRateLimit(ctx context.Context, rlRequest *RateLimitReq)
 		ctx:     ctx,
 		resp:    make(chan *response, 1),
 		request: rlRequest,
+		reserve: reserve,
 	}
 
 	// Send request.
@@ -282,7 +422,7 @@ func (p *WorkerPool) GetRateLimit(ctx context.Context, rlRequest *RateLimitReq)
 	select {
 	case handlerResponse := <-handlerRequest.resp:
 		// Successfully read response.
-		return handlerResponse.rl, handlerResponse.err
+		return handlerResponse, nil
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
@@ -296,7 +436,7 @@ func (worker *Worker) handleGetRateLimit(ctx context.Context, req *RateLimitReq,
 
 	switch req.Algorithm {
 	case Algorithm_TOKEN_BUCKET:
-		rlResponse, err = tokenBucket(ctx, worker.conf.Store, cache, req)
+		rlResponse, err = tokenBucket(ctx, worker.store, cache, req)
 		if err != nil {
 			msg := "Error in tokenBucket"
 			countError(err, msg)
@@ -305,7 +445,7 @@ func (worker *Worker) handleGetRateLimit(ctx context.Context, req *RateLimitReq,
 		}
 
 	case Algorithm_LEAKY_BUCKET:
-		rlResponse, err = leakyBucket(ctx, worker.conf.Store, cache, req)
+		rlResponse, err = leakyBucket(ctx, worker.store, cache, req)
 		if err != nil {
 			msg := "Error in leakyBucket"
 			countError(err, msg)
@@ -313,12 +453,60 @@ func (worker *Worker) handleGetRateLimit(ctx context.Context, req *RateLimitReq,
 			trace.SpanFromContext(ctx).RecordError(err)
 		}
 
+	case Algorithm_SLIDING_WINDOW:
+		rlResponse, err = slidingWindow(ctx, worker.store, cache, req)
+		if err != nil {
+			msg := "Error in slidingWindow"
+			countError(err, msg)
+			err = errors.Wrap(err, msg)
+			trace.SpanFromContext(ctx).RecordError(err)
+		}
+
+	case Algorithm_GCRA:
+		rlResponse, err = gcra(ctx, worker.store, cache, req)
+		if err != nil {
+			msg := "Error in gcra"
+			countError(err, msg)
+			err = errors.Wrap(err, msg)
+			trace.SpanFromContext(ctx).RecordError(err)
+		}
+
+	case Algorithm_CONCURRENCY:
+		rlResponse, err = concurrency(ctx, worker.store, cache, req)
+		if err != nil {
+			msg := "Error in concurrency"
+			countError(err, msg)
+			err = errors.Wrap(err, msg)
+			trace.SpanFromContext(ctx).RecordError(err)
+		}
+
+	case Algorithm_CONTINUOUS_TOKEN_BUCKET:
+		rlResponse, err = continuousTokenBucket(ctx, worker.store, cache, req)
+		if err != nil {
+			msg := "Error in continuousTokenBucket"
+			countError(err, msg)
+			err = errors.Wrap(err, msg)
+			trace.SpanFromContext(ctx).RecordError(err)
+		}
+
 	default:
 		err = errors.Errorf("Invalid rate limit algorithm '%d'", req.Algorithm)
 		trace.SpanFromContext(ctx).RecordError(err)
 		metricCheckErrorCounter.WithLabelValues("Invalid algorithm").Add(1)
 	}
 
+	// The algorithm treated a failure to read from the store as a miss and created a new
+	// rate limit, which must not be used in place of the state held by the store.
+	if reporter, ok := worker.store.(*storeErrorReporter); ok {
+		if storeErr := reporter.takeGetError(req.HashKey()); storeErr != nil {
+			cache.Remove(req.HashKey())
+			if err == nil {
+				err = errors.Wrap(storeErr, "Error in Store.Get")
+			}
+			return nil, err
+		}
+	}
+
 	return rlResponse, err
 }
 
@@ -446,33 +634,58 @@ MAIN:
 
 // Store atomically stores cache to persistent storage.
 // Save all workers' caches to persistent storage.
-// Workers are locked during this store operation to prevent race conditions.
+// Workers are only locked while their cache is copied, the copy is saved
+// while the workers continue to process requests.
 func (p *WorkerPool) Store(ctx context.Context) (err error) {
+	items, _, err := p.snapshot(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	out := make(chan *CacheItem, 500)
+	go func() {
+		for _, item := range items {
+			out <- item
+		}
+		close(out)
+	}()
+
+	if err = p.conf.Loader.Save(out); err != nil {
+		return errors.Wrap(err, "while calling p.conf.Loader.Save()")
+	}
+
+	return nil
+}
+
+// snapshot returns a copy of the items in every worker's cache. If changedOnly is true, only
+// the items which changed since the previous snapshot are returned. The returned bool is
+// false if no cache has changed since the previous snapshot.
+func (p *WorkerPool) snapshot(ctx context.Context, changedOnly bool) ([]*CacheItem, bool, error) {
 	queueGauge := metricWorkerQueue.WithLabelValues("Store", "")
 	queueGauge.Inc()
 	defer queueGauge.Dec()
+
+	responses := make([]workerStoreResponse, len(p.workers))
 	var wg sync.WaitGroup
-	out := make(chan *CacheItem, 500)
 
-	// Iterate each worker's cache to `out` channel.
-	for _, worker := range p.workers {
+	for i, worker := range p.workers {
 		wg.Add(1)
 
-		go func(ctx context.Context, worker *Worker) {
+		go func(ctx context.Context, i int, worker *Worker) {
 			defer wg.Done()
 
 			respChan := make(chan workerStoreResponse)
 			req := workerStoreRequest{
-				ctx:      ctx,
-				response: respChan,
-				out:      out,
+				ctx:         ctx,
+				response:    respChan,
+				changedOnly: changedOnly,
 			}
 
 			select {
 			case worker.storeRequest <- req:
 				// Successfully sent request.
 				select {
-				case <-respChan:
+				case responses[i] = <-respChan:
 					// Successfully received response.
 					return
 
@@ -487,40 +700,40 @@ func (p *WorkerPool) Store(ctx context.Context) (err error) {
 				trace.SpanFromContext(ctx).RecordError(ctx.Err())
 				return
 			}
-		}(ctx, worker)
+		}(ctx, i, worker)
 	}
-
-	// When all iterators are done, close `out` channel.
-	go func() {
-		wg.Wait()
-		close(out)
-	}()
+	wg.Wait()
 
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return nil, false, ctx.Err()
 	}
 
-	if err = p.conf.Loader.Save(out); err != nil {
-		return errors.Wrap(err, "while calling p.conf.Loader.Save()")
+	var items []*CacheItem
+	var changed bool
+	for _, resp := range responses {
+		items = append(items, resp.items...)
+		changed = changed || resp.changed
 	}
-
-	return nil
+	return items, changed, nil
 }
 
 func (worker *Worker) handleStore(request workerStoreRequest, cache Cache) {
-	for item := range cache.Each() {
-		select {
-		case request.out <- item:
-			// Successfully sent item.
+	response := workerStoreResponse{
+		changed: worker.changed == nil || len(worker.changed) != 0,
+	}
 
-		case <-request.ctx.Done():
-			// Context canceled.
-			trace.SpanFromContext(request.ctx).RecordError(request.ctx.Err())
-			return
+	for item := range cache.Each() {
+		if request.changedOnly && worker.changed != nil {
+			if _, ok := worker.changed[item.Key]; !ok {
+				continue
+			}
 		}
+		response.items = append(response.items, copyCacheItem(item))
 	}
 
-	response := workerStoreResponse{}
+	if worker.changed != nil && len(worker.changed) != 0 {
+		worker.changed = make(map[string]struct{})
+	}
 
 	select {
 	case request.response <- response:
@@ -532,6 +745,41 @@ func (worker *Worker) handleStore(request workerStoreRequest, cache Cache) {
 	}
 }
 
+// markChanged records that the item with the provided key changed since the last snapshot
+func (worker *Worker) markChanged(key string) {
+	if worker.changed != nil {
+		worker.changed[key] = struct{}{}
+	}
+}
+
+// copyCacheItem returns a copy of the item which is safe to read
+// while the worker continues to modify the original.
+func copyCacheItem(item *CacheItem) *CacheItem {
+	c := *item
+	switch v := item.Value.(type) {
+	case *TokenBucketItem:
+		t := *v
+		c.Value = &t
+	case *LeakyBucketItem:
+		t := *v
+		c.Value = &t
+	case *SlidingWindowItem:
+		t := *v
+		c.Value = &t
+	case *GCRAItem:
+		t := *v
+		c.Value = &t
+	case *ContinuousTokenBucketItem:
+		t := *v
+		c.Value = &t
+	case *ConcurrencyItem:
+		t := *v
+		t.Leases = append([]ConcurrencyLease(nil), v.Leases...)
+		c.Value = &t
+	}
+	return &c
+}
+
 // AddCacheItem adds an item to the worker's cache.
 func (p *WorkerPool) AddCacheItem(ctx context.Context, key string, item *CacheItem) (err error) {
 	worker := p.getWorker(key)
@@ -564,10 +812,54 @@ func (p *WorkerPool) AddCacheItem(ctx context.Context, key string, item *CacheIt
 	}
 }
 
+// MergeCacheItem adds the item to the worker's cache unless an unexpired item with the same key
+// is already cached and replace() returns false. replace() is called by the worker which owns
+// the item. Returns true if the item was added.
+func (p *WorkerPool) MergeCacheItem(ctx context.Context, item *CacheItem, replace func(existing *CacheItem) bool) (added bool, err error) {
+	worker := p.getWorker(item.Key)
+	queueGauge := metricWorkerQueue.WithLabelValues("MergeCacheItem", worker.name)
+	queueGauge.Inc()
+	defer queueGauge.Dec()
+	respChan := make(chan workerAddCacheItemResponse)
+	req := workerAddCacheItemRequest{
+		ctx:      ctx,
+		response: respChan,
+		item:     item,
+		replace:  replace,
+	}
+
+	select {
+	case worker.addCacheItemRequest <- req:
+		// Successfully sent request.
+		select {
+		case resp := <-respChan:
+			// Successfully received response.
+			return resp.added, nil
+
+		case <-ctx.Done():
+			// Context canceled.
+			return false, ctx.Err()
+		}
+
+	case <-ctx.Done():
+		// Context canceled.
+		return false, ctx.Err()
+	}
+}
+
 func (worker *Worker) handleAddCacheItem(request workerAddCacheItemRequest, cache Cache) {
+	if request.replace != nil {
+		existing, ok := cache.GetItem(request.item.Key)
+		if ok && existing.ExpireAt > MillisecondNow() && !request.replace(existing) {
+			worker.sendAddCacheItemResponse(request, workerAddCacheItemResponse{exists: true})
+			return
+		}
+	}
 	exists := cache.Add(request.item)
-	response := workerAddCacheItemResponse{exists}
+	worker.sendAddCacheItemResponse(request, workerAddCacheItemResponse{exists: exists, added: true})
+}
 
+func (worker *Worker) sendAddCacheItemResponse(request workerAddCacheItemRequest, response workerAddCacheItemResponse) {
 	select {
 	case request.response <- response:
 		// Successfully sent response.
@@ -578,7 +870,7 @@ func (worker *Worker) handleAddCacheItem(request workerAddCacheItemRequest, cach
 	}
 }
 
-// GetCacheItem gets item from worker's cache.
+// GetCacheItem gets a copy of the item from worker's cache.
 func (p *WorkerPool) GetCacheItem(ctx context.Context, key string) (item *CacheItem, found bool, err error) {
 	worker := p.getWorker(key)
 	queueGauge := metricWorkerQueue.WithLabelValues("GetCacheItem", worker.name)
@@ -612,6 +904,10 @@ func (p *WorkerPool) GetCacheItem(ctx context.Context, key string) (item *CacheI
 
 func (worker *Worker) handleGetCacheItem(request workerGetCacheItemRequest, cache Cache) {
 	item, ok := cache.GetItem(request.key)
+	if ok {
+		// The worker continues to modify the cached item
+		item = copyCacheItem(item)
+	}
 	response := workerGetCacheItemResponse{item, ok}
 
 	select {
@@ -623,3 +919,186 @@ func (worker *Worker) handleGetCacheItem(request workerGetCacheItemRequest, cach
 		trace.SpanFromContext(request.ctx).RecordError(request.ctx.Err())
 	}
 }
+
+// RemoveCacheItem removes an item from the worker's cache.
+func (p *WorkerPool) RemoveCacheItem(ctx context.Context, key string) (err error) {
+	worker := p.getWorker(key)
+	queueGauge := metricWorkerQueue.WithLabelValues("RemoveCacheItem", worker.name)
+	queueGauge.Inc()
+	defer queueGauge.Dec()
+	respChan := make(chan workerRemoveCacheItemResponse)
+	req := workerRemoveCacheItemRequest{
+		ctx:      ctx,
+		response: respChan,
+		key:      key,
+	}
+
+	select {
+	case worker.removeCacheItemRequest <- req:
+		// Successfully sent request.
+		select {
+		case <-respChan:
+			// Successfully received response.
+			return nil
+
+		case <-ctx.Done():
+			// Context canceled.
+			return ctx.Err()
+		}
+
+	case <-ctx.Done():
+		// Context canceled.
+		return ctx.Err()
+	}
+}
+
+func (worker *Worker) handleRemoveCacheItem(request workerRemoveCacheItemRequest, cache Cache) {
+	cache.Remove(request.key)
+	response := workerRemoveCacheItemResponse{}
+
+	select {
+	case request.response <- response:
+		// Successfully sent response.
+
+	case <-request.ctx.Done():
+		// Context canceled.
+		trace.SpanFromContext(request.ctx).RecordError(request.ctx.Err())
+	}
+}
+
+// EachCacheItem calls `fn` with each item in every worker's cache, one worker at a time.
+// `fn` is called by the worker which owns the item, so it may safely read the item, but
+// must not retain it or modify it.
+func (p *WorkerPool) EachCacheItem(ctx context.Context, fn func(*CacheItem)) (err error) {
+	queueGauge := metricWorkerQueue.WithLabelValues("EachCacheItem", "")
+	queueGauge.Inc()
+	defer queueGauge.Dec()
+
+	for _, worker := range p.workers {
+		respChan := make(chan workerEachCacheItemResponse)
+		req := workerEachCacheItemRequest{
+			ctx:      ctx,
+			response: respChan,
+			fn:       fn,
+		}
+
+		select {
+		case worker.eachCacheItemRequest <- req:
+			// Successfully sent request.
+			select {
+			case <-respChan:
+				// Successfully received response.
+
+			case <-ctx.Done():
+				// Context canceled.
+				return ctx.Err()
+			}
+
+		case <-ctx.Done():
+			// Context canceled.
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (worker *Worker) handleEachCacheItem(request workerEachCacheItemRequest, cache Cache) {
+	for item := range cache.Each() {
+		// Drain the iterator even if the context was canceled
+		if request.ctx.Err() == nil {
+			request.fn(item)
+		}
+	}
+	response := workerEachCacheItemResponse{}
+
+	select {
+	case request.response <- response:
+		// Successfully sent response.
+
+	case <-request.ctx.Done():
+		// Context canceled.
+		trace.SpanFromContext(request.ctx).RecordError(request.ctx.Err())
+	}
+}
+
+// RestoreCacheItem undoes a request applied by ReserveRateLimit() by replacing the cached item
+// with `before`, or removing it if `before` is nil. The item is only restored if it is still
+// equal to `after`, such that the changes of other requests since the reserve are never lost.
+// Returns true if the item was restored.
+func (p *WorkerPool) RestoreCacheItem(ctx context.Context, r *RateLimitReq, before, after *CacheItem) (restored bool, err error) {
+	worker := p.getWorker(r.HashKey())
+	queueGauge := metricWorkerQueue.WithLabelValues("Restore", worker.name)
+	queueGauge.Inc()
+	defer queueGauge.Dec()
+	respChan := make(chan workerRestoreResponse)
+	req := workerRestoreRequest{
+		ctx:      ctx,
+		response: respChan,
+		request:  r,
+		before:   before,
+		after:    after,
+	}
+
+	select {
+	case worker.restoreRequest <- req:
+		// Successfully sent request.
+		select {
+		case resp := <-respChan:
+			// Successfully received response.
+			return resp.restored, nil
+
+		case <-ctx.Done():
+			// Context canceled.
+			return false, ctx.Err()
+		}
+
+	case <-ctx.Done():
+		// Context canceled.
+		return false, ctx.Err()
+	}
+}
+
+func (worker *Worker) handleRestore(request workerRestoreRequest, cache Cache) {
+	key := request.request.HashKey()
+	var response workerRestoreResponse
+	// An item which was not cached may have been loaded from the store by the reserve, in
+	// which case removing it would lose the state held by the store.
+	if (request.before != nil || worker.store == nil) && reflect.DeepEqual(cachedCopy(cache, key), request.after) {
+		response.restored = true
+		if request.before != nil {
+			cache.Add(copyCacheItem(request.before))
+			if worker.store != nil {
+				worker.store.OnChange(request.ctx, request.request, request.before)
+			}
+		} else {
+			cache.Remove(key)
+		}
+	}
+
+	select {
+	case request.response <- response:
+		// Successfully sent response.
+
+	case <-request.ctx.Done():
+		// Context canceled.
+		trace.SpanFromContext(request.ctx).RecordError(request.ctx.Err())
+	}
+}
+
+// cachedCopy returns a copy of the cached item, or nil if the item is not cached
+func cachedCopy(cache Cache, key string) *CacheItem {
+	item, ok := cache.GetItem(key)
+	if !ok {
+		return nil
+	}
+	return copyCacheItem(item)
+}
+
+// handleSweep removes a batch of expired items from the cache, such that memory is
+// reclaimed after a burst of traffic without waiting for the items to be evicted.
+func (worker *Worker) handleSweep(cache SweepableCache) {
+	timer := prometheus.NewTimer(metricCacheSweepDuration)
+	removed := cache.Sweep(worker.conf.CacheSweepBatch)
+	timer.ObserveDuration()
+	metricCacheSweepCounter.Add(float64(removed))
+}
@@ -21,8 +21,11 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	guber "github.com/mailgun/gubernator/v2"
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -132,3 +135,40 @@ func TestGubernatorPool(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkerPoolSweep(t *testing.T) {
+	ctx := context.Background()
+	var caches []*guber.LRUCache
+	conf := &guber.Config{
+		CacheFactory: func(maxSize int) guber.Cache {
+			cache := guber.NewLRUCache(maxSize)
+			caches = append(caches, cache)
+			return cache
+		},
+		Workers:            2,
+		CacheSweepInterval: 10 * time.Millisecond,
+		CacheSweepBatch:    10,
+	}
+	require.NoError(t, conf.SetDefaults())
+	pool := guber.NewWorkerPool(conf)
+	defer pool.Close()
+
+	now := clock.Now()
+	for i := 0; i < 100; i++ {
+		expireAt := now.Add(-time.Minute)
+		if i%2 == 0 {
+			expireAt = now.Add(time.Hour)
+		}
+		item := &guber.CacheItem{Key: fmt.Sprintf("Foobar%04d", i), ExpireAt: expireAt.UnixMilli()}
+		require.NoError(t, pool.AddCacheItem(ctx, item.Key, item))
+	}
+
+	// The expired items are removed over several sweeps without being accessed
+	testutil.UntilPass(t, 50, 20*time.Millisecond, func(t testutil.TestingT) {
+		var size int64
+		for _, cache := range caches {
+			size += cache.Size()
+		}
+		assert.Equal(t, int64(50), size)
+	})
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mailgun/holster/v4/clock"
+	"github.com/mailgun/holster/v4/setter"
+	"github.com/mailgun/holster/v4/syncutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type WriteBehindConfig struct {
+	// (Optional) How often pending changes are flushed to the store. Defaults to 100ms
+	FlushInterval time.Duration
+
+	// (Optional) Flush as soon as this many rate limits have pending changes. Defaults to 1,000
+	BatchSize int
+
+	// (Optional) The max number of rate limits with pending changes. Once reached, changes to
+	// rate limits which are not already pending wait for the next flush. Defaults to 10,000
+	MaxPending int
+
+	// (Optional) How long a flush may take before the remaining changes are put back to wait
+	// for the next flush. Defaults to 5s
+	FlushTimeout time.Duration
+
+	// (Optional) A Logger which implements the declared logger interface (typically *logrus.Entry)
+	Logger FieldLogger
+}
+
+// WriteBehindStore sits between the workers and a StoreV2 such that a slow store does not
+// stall the workers. Changes are queued in memory and flushed to the store in batches by
+// a background goroutine. Multiple changes to the same rate limit between flushes are
+// coalesced into a single call to the store. If the store implements StoreBatchWriter, each
+// batch of changes is written in a single call. Changes which the store fails to write are
+// logged and counted as dropped, changes not yet written when the flush times out wait for
+// the next flush.
+type WriteBehindStore struct {
+	conf  WriteBehindConfig
+	store StoreV2
+	wg    syncutil.WaitGroup
+
+	mutex sync.Mutex
+	// Changes waiting for the next flush
+	pending map[string]*writeBehindChange
+	// Changes currently being written to the store
+	flushing map[string]*writeBehindChange
+	// Closed when the current flush completes
+	flushed chan struct{}
+	// Signals the flusher to flush before the next interval
+	flush  chan struct{}
+	closed bool
+
+	metricPending       prometheus.Gauge
+	metricCoalesced     prometheus.Counter
+	metricBlocked       prometheus.Counter
+	metricDropped       prometheus.Counter
+	metricFlushDuration prometheus.Summary
+}
+
+type writeBehindChange struct {
+	req    *RateLimitReq
+	item   *CacheItem
+	remove bool
+}
+
+var _ StoreV2 = &WriteBehindStore{}
+var _ StoreHealthChecker = &WriteBehindStore{}
+var _ prometheus.Collector = &WriteBehindStore{}
+
+// NewWriteBehindStore returns a StoreV2 which writes changes to the provided store in the background.
+// Call Close() to flush any pending changes once the gubernator instance is closed.
+func NewWriteBehindStore(store StoreV2, conf WriteBehindConfig) *WriteBehindStore {
+	setter.SetDefault(&conf.FlushInterval, time.Millisecond*100)
+	setter.SetDefault(&conf.BatchSize, 1_000)
+	setter.SetDefault(&conf.MaxPending, 10_000)
+	setter.SetDefault(&conf.FlushTimeout, time.Second*5)
+	setter.SetDefault(&conf.Logger, logrus.WithField("category", "gubernator"))
+
+	s := &WriteBehindStore{
+		conf:     conf,
+		store:    store,
+		pending:  make(map[string]*writeBehindChange),
+		flushing: make(map[string]*writeBehindChange),
+		flushed:  make(chan struct{}),
+		flush:    make(chan struct{}, 1),
+		metricPending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gubernator_write_behind_pending",
+			Help: "The number of rate limits with changes waiting to be written to the store.",
+		}),
+		metricCoalesced: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gubernator_write_behind_coalesced",
+			Help: "The count of changes which replaced a change already waiting to be written to the store.",
+		}),
+		metricBlocked: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gubernator_write_behind_blocked",
+			Help: "The count of changes which waited for a flush because too many changes were pending.",
+		}),
+		metricDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gubernator_write_behind_dropped",
+			Help: "The count of changes which were never written to the store.",
+		}),
+		metricFlushDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:       "gubernator_write_behind_flush_duration",
+			Help:       "The duration of flushes to the store in seconds.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+		}),
+	}
+	s.run()
+	return s
+}
+
+func (s *WriteBehindStore) run() {
+	tick := clock.NewTicker(s.conf.FlushInterval)
+	s.wg.Until(func(done chan struct{}) bool {
+		select {
+		case <-tick.C():
+			s.flushPending()
+		case <-s.flush:
+			s.flushPending()
+		case <-done:
+			tick.Stop()
+			return false
+		}
+		return true
+	})
+}
+
+// OnChange queues the change to be written to the store. If too many changes are pending, OnChange waits
+// until the next flush completes or the context is cancelled, in which case the change is dropped.
+func (s *WriteBehindStore) OnChange(ctx context.Context, r *RateLimitReq, item *CacheItem) error {
+	return s.queue(ctx, item.Key, &writeBehindChange{req: r, item: copyCacheItem(item)})
+}
+
+// Remove queues the removal of the rate limit from the store
+func (s *WriteBehindStore) Remove(ctx context.Context, key string) error {
+	return s.queue(ctx, key, &writeBehindChange{remove: true})
+}
+
+// Get returns the pending change to the rate limit if there is one, else reads the rate limit from the store
+func (s *WriteBehindStore) Get(ctx context.Context, r *RateLimitReq) (*CacheItem, bool, error) {
+	key := r.HashKey()
+	s.mutex.Lock()
+	c, ok := s.pending[key]
+	if !ok {
+		c, ok = s.flushing[key]
+	}
+	s.mutex.Unlock()
+
+	if ok {
+		if c.remove {
+			return nil, false, nil
+		}
+		return copyCacheItem(c.item), true, nil
+	}
+	return s.store.Get(ctx, r)
+}
+
+// HealthCheck reports the health of the underlying store if it implements StoreHealthChecker
+func (s *WriteBehindStore) HealthCheck() error {
+	if hc, ok := s.store.(StoreHealthChecker); ok {
+		return hc.HealthCheck()
+	}
+	return nil
+}
+
+func (s *WriteBehindStore) queue(ctx context.Context, key string, change *writeBehindChange) error {
+	for {
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			return s.write(ctx, key, change)
+		}
+
+		if _, ok := s.pending[key]; ok {
+			s.pending[key] = change
+			s.mutex.Unlock()
+			s.metricCoalesced.Inc()
+			return nil
+		}
+
+		if len(s.pending) < s.conf.MaxPending {
+			s.pending[key] = change
+			size := len(s.pending)
+			s.mutex.Unlock()
+			s.metricPending.Set(float64(size))
+			if size >= s.conf.BatchSize {
+				s.signalFlush()
+			}
+			return nil
+		}
+
+		// Too many changes are pending, wait for the flusher to catch up
+		flushed := s.flushed
+		s.mutex.Unlock()
+		s.metricBlocked.Inc()
+		s.signalFlush()
+
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			s.metricDropped.Inc()
+			return errors.Wrapf(ctx.Err(), "while waiting to queue change to rate limit '%s'", key)
+		}
+	}
+}
+
+func (s *WriteBehindStore) signalFlush() {
+	select {
+	case s.flush <- struct{}{}:
+	default:
+	}
+}
+
+// flushPending writes every pending change to the store. Changes which were not written before
+// the flush timed out are put back in the pending set, unless a newer change replaced them.
+func (s *WriteBehindStore) flushPending() {
+	s.mutex.Lock()
+	if len(s.pending) == 0 {
+		s.mutex.Unlock()
+		return
+	}
+	s.flushing, s.pending = s.pending, make(map[string]*writeBehindChange)
+	batch := s.flushing
+	s.mutex.Unlock()
+	s.metricPending.Set(0)
+
+	keys := make([]string, 0, len(batch))
+	for key := range batch {
+		keys = append(keys, key)
+	}
+
+	start := clock.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.FlushTimeout)
+	bw, isBatchWriter := s.store.(StoreBatchWriter)
+	var failed, next int
+	var lastErr error
+	for next < len(keys) && ctx.Err() == nil {
+		if isBatchWriter {
+			end := next + s.conf.BatchSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			if err := s.writeBatch(ctx, bw, keys[next:end], batch); err != nil {
+				failed += end - next
+				lastErr = err
+			}
+			next = end
+			continue
+		}
+		if err := s.write(ctx, keys[next], batch[keys[next]]); err != nil {
+			failed++
+			lastErr = err
+		}
+		next++
+	}
+	cancel()
+	s.metricFlushDuration.Observe(clock.Since(start).Seconds())
+
+	s.mutex.Lock()
+	var requeued int
+	for _, key := range keys[next:] {
+		if _, ok := s.pending[key]; ok {
+			continue
+		}
+		// Once closed there is no next flush
+		if s.closed || len(s.pending) >= s.conf.MaxPending {
+			failed++
+			continue
+		}
+		s.pending[key] = batch[key]
+		requeued++
+	}
+	size := len(s.pending)
+	s.flushing = make(map[string]*writeBehindChange)
+	close(s.flushed)
+	s.flushed = make(chan struct{})
+	s.mutex.Unlock()
+	s.metricPending.Set(float64(size))
+
+	if requeued != 0 {
+		s.conf.Logger.Warnf("flush to the store timed out; %d changes wait for the next flush", requeued)
+	}
+	if failed != 0 {
+		if lastErr == nil {
+			lastErr = ctx.Err()
+		}
+		s.metricDropped.Add(float64(failed))
+		s.conf.Logger.WithError(lastErr).Errorf("while flushing %d changes to the store; %d changes dropped",
+			len(batch), failed)
+	}
+}
+
+// writeBatch writes the changes to the rate limits with the provided keys in a single call to the store
+func (s *WriteBehindStore) writeBatch(ctx context.Context, bw StoreBatchWriter, keys []string,
+	batch map[string]*writeBehindChange) error {
+	changes := make([]StoreChange, 0, len(keys))
+	for _, key := range keys {
+		change := batch[key]
+		if change.remove {
+			changes = append(changes, StoreChange{Key: key})
+			continue
+		}
+		changes = append(changes, StoreChange{Key: key, Req: change.req, Item: change.item})
+	}
+	return bw.WriteBatch(ctx, changes)
+}
+
+func (s *WriteBehindStore) write(ctx context.Context, key string, change *writeBehindChange) error {
+	if change.remove {
+		return s.store.Remove(ctx, key)
+	}
+	return s.store.OnChange(ctx, change.req, change.item)
+}
+
+// Close stops the background flusher and writes any pending changes to the store.
+// Changes made after Close() are written to the store immediately.
+func (s *WriteBehindStore) Close() {
+	s.wg.Stop()
+
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+
+	s.flushPending()
+}
+
+// Describe fetches prometheus metrics to be registered
+func (s *WriteBehindStore) Describe(ch chan<- *prometheus.Desc) {
+	s.metricBlocked.Describe(ch)
+	s.metricCoalesced.Describe(ch)
+	s.metricDropped.Describe(ch)
+	s.metricFlushDuration.Describe(ch)
+	s.metricPending.Describe(ch)
+}
+
+// Collect fetches metrics from the store for use by prometheus
+func (s *WriteBehindStore) Collect(ch chan<- prometheus.Metric) {
+	s.metricBlocked.Collect(ch)
+	s.metricCoalesced.Collect(ch)
+	s.metricDropped.Collect(ch)
+	s.metricFlushDuration.Collect(ch)
+	s.metricPending.Collect(ch)
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2018-2022 Mailgun Technologies Inc
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gubernator_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	gubernator "github.com/mailgun/gubernator/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowStore records the changes it receives, each call waits until the gate is open
+type slowStore struct {
+	gate    chan struct{}
+	mutex   sync.Mutex
+	changes []string
+	items   map[string]*gubernator.CacheItem
+}
+
+func newSlowStore() *slowStore {
+	return &slowStore{
+		gate:  make(chan struct{}),
+		items: make(map[string]*gubernator.CacheItem),
+	}
+}
+
+func (s *slowStore) OnChange(ctx context.Context, r *gubernator.RateLimitReq, item *gubernator.CacheItem) {
+	<-s.gate
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.changes = append(s.changes, "OnChange:"+item.Key)
+	s.items[item.Key] = item
+}
+
+func (s *slowStore) Get(ctx context.Context, r *gubernator.RateLimitReq) (*gubernator.CacheItem, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.changes = append(s.changes, "Get:"+r.HashKey())
+	item, ok := s.items[r.HashKey()]
+	return item, ok
+}
+
+func (s *slowStore) Remove(ctx context.Context, key string) {
+	<-s.gate
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.changes = append(s.changes, "Remove:"+key)
+	delete(s.items, key)
+}
+
+func (s *slowStore) Changes() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.changes...)
+}
+
+// batchStore records the size of each batch of changes written with WriteBatch()
+type batchStore struct {
+	mutex   sync.Mutex
+	batches [][]gubernator.StoreChange
+	calls   []string
+}
+
+func (s *batchStore) OnChange(ctx context.Context, r *gubernator.RateLimitReq, item *gubernator.CacheItem) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls = append(s.calls, "OnChange:"+item.Key)
+	return nil
+}
+
+func (s *batchStore) Get(ctx context.Context, r *gubernator.RateLimitReq) (*gubernator.CacheItem, bool, error) {
+	return nil, false, nil
+}
+
+func (s *batchStore) Remove(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls = append(s.calls, "Remove:"+key)
+	return nil
+}
+
+func (s *batchStore) WriteBatch(ctx context.Context, batch []gubernator.StoreChange) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func writeBehindMetric(t *testing.T, s *gubernator.WriteBehindStore, name string) float64 {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(s))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() == name {
+			m := f.GetMetric()[0]
+			if m.GetCounter() != nil {
+				return m.GetCounter().GetValue()
+			}
+			return m.GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+func tokenBucketChange(key string, remaining int64) (*gubernator.RateLimitReq, *gubernator.CacheItem) {
+	req := &gubernator.RateLimitReq{Name: "test_write_behind", UniqueKey: key, Limit: 10}
+	return req, &gubernator.CacheItem{
+		Algorithm: gubernator.Algorithm_TOKEN_BUCKET,
+		Key:       req.HashKey(),
+		ExpireAt:  gubernator.MillisecondNow() + gubernator.Minute,
+		Value:     &gubernator.TokenBucketItem{Limit: 10, Remaining: remaining},
+	}
+}
+
+func TestWriteBehindStoreCoalesce(t *testing.T) {
+	store := newSlowStore()
+	close(store.gate)
+	wb := gubernator.NewWriteBehindStore(gubernator.NewStoreV2Adapter(store), gubernator.WriteBehindConfig{FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	req, item := tokenBucketChange("account:1", 9)
+	wb.OnChange(ctx, req, item)
+
+	// The worker continues to modify the item after OnChange() returns
+	item.Value.(*gubernator.TokenBucketItem).Remaining = 8
+	wb.OnChange(ctx, req, item)
+	item.Value.(*gubernator.TokenBucketItem).Remaining = 0
+
+	// Pending changes are returned without reading from the store
+	pending, ok, err := wb.Get(ctx, req)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, int64(8), pending.Value.(*gubernator.TokenBucketItem).Remaining)
+	assert.Empty(t, store.Changes())
+
+	removeReq, removeItem := tokenBucketChange("account:2", 9)
+	wb.OnChange(ctx, removeReq, removeItem)
+	wb.Remove(ctx, removeItem.Key)
+	_, ok, err = wb.Get(ctx, removeReq)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Equal(t, float64(2), writeBehindMetric(t, wb, "gubernator_write_behind_pending"))
+	assert.Equal(t, float64(2), writeBehindMetric(t, wb, "gubernator_write_behind_coalesced"))
+
+	// Close() flushes the pending changes
+	wb.Close()
+	assert.ElementsMatch(t, []string{"OnChange:" + item.Key, "Remove:" + removeItem.Key}, store.Changes())
+	assert.Equal(t, int64(8), store.items[item.Key].Value.(*gubernator.TokenBucketItem).Remaining)
+
+	// Changes after Close() are written immediately
+	wb.Remove(ctx, item.Key)
+	assert.Contains(t, store.Changes(), "Remove:"+item.Key)
+}
+
+func TestWriteBehindStoreFlush(t *testing.T) {
+	store := newSlowStore()
+	close(store.gate)
+	wb := gubernator.NewWriteBehindStore(gubernator.NewStoreV2Adapter(store), gubernator.WriteBehindConfig{
+		FlushInterval: time.Millisecond * 10,
+	})
+	defer wb.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		req, item := tokenBucketChange(fmt.Sprintf("account:%d", i), 9)
+		wb.OnChange(ctx, req, item)
+	}
+
+	require.Eventually(t, func() bool {
+		return len(store.Changes()) == 10
+	}, time.Second, time.Millisecond*10)
+
+	// Once flushed, reads go to the store
+	req, _ := tokenBucketChange("account:1", 9)
+	_, ok, err := wb.Get(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, store.Changes(), "Get:"+req.HashKey())
+}
+
+func TestWriteBehindStoreBackpressure(t *testing.T) {
+	store := newSlowStore()
+	wb := gubernator.NewWriteBehindStore(gubernator.NewStoreV2Adapter(store), gubernator.WriteBehindConfig{
+		FlushInterval: time.Hour,
+		BatchSize:     1,
+		MaxPending:    1,
+	})
+	ctx := context.Background()
+
+	// The flusher picks up the first change and waits on the store
+	req1, item1 := tokenBucketChange("account:1", 9)
+	wb.OnChange(ctx, req1, item1)
+	require.Eventually(t, func() bool {
+		return writeBehindMetric(t, wb, "gubernator_write_behind_pending") == 0
+	}, time.Second, time.Millisecond*10)
+
+	// The second change fills the pending changes
+	req2, item2 := tokenBucketChange("account:2", 9)
+	wb.OnChange(ctx, req2, item2)
+
+	// The third change waits until the context is cancelled and is dropped
+	req3, item3 := tokenBucketChange("account:3", 9)
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	assert.ErrorIs(t, wb.OnChange(timeout, req3, item3), context.DeadlineExceeded)
+	cancel()
+	assert.Equal(t, float64(1), writeBehindMetric(t, wb, "gubernator_write_behind_blocked"))
+	assert.Equal(t, float64(1), writeBehindMetric(t, wb, "gubernator_write_behind_dropped"))
+
+	// The fourth change waits until the flusher catches up
+	done := make(chan struct{})
+	req4, item4 := tokenBucketChange("account:4", 9)
+	go func() {
+		wb.OnChange(ctx, req4, item4)
+		close(done)
+	}()
+
+	close(store.gate)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OnChange()")
+	}
+
+	wb.Close()
+	assert.ElementsMatch(t, []string{
+		"OnChange:" + item1.Key,
+		"OnChange:" + item2.Key,
+		"OnChange:" + item4.Key,
+	}, store.Changes())
+}
+
+func TestWriteBehindStoreFlushTimeout(t *testing.T) {
+	store := newSlowStore()
+	wb := gubernator.NewWriteBehindStore(gubernator.NewStoreV2Adapter(store), gubernator.WriteBehindConfig{
+		FlushInterval: time.Hour,
+		BatchSize:     2,
+		FlushTimeout:  time.Millisecond * 50,
+	})
+	ctx := context.Background()
+
+	// The flusher picks up both changes and waits on the store to write the first
+	req1, item1 := tokenBucketChange("account:1", 9)
+	wb.OnChange(ctx, req1, item1)
+	req2, item2 := tokenBucketChange("account:2", 9)
+	wb.OnChange(ctx, req2, item2)
+	require.Eventually(t, func() bool {
+		return writeBehindMetric(t, wb, "gubernator_write_behind_pending") == 0
+	}, time.Second, time.Millisecond*10)
+
+	// The flush times out and the change it did not write waits for the next flush
+	time.Sleep(time.Millisecond * 100)
+	close(store.gate)
+	require.Eventually(t, func() bool {
+		return writeBehindMetric(t, wb, "gubernator_write_behind_pending") == 1
+	}, time.Second, time.Millisecond*10)
+	assert.Len(t, store.Changes(), 1)
+	assert.Zero(t, writeBehindMetric(t, wb, "gubernator_write_behind_dropped"))
+
+	wb.Close()
+	assert.ElementsMatch(t, []string{"OnChange:" + item1.Key, "OnChange:" + item2.Key}, store.Changes())
+	assert.Zero(t, writeBehindMetric(t, wb, "gubernator_write_behind_dropped"))
+}
+
+func TestWriteBehindStoreBatch(t *testing.T) {
+	store := &batchStore{}
+	wb := gubernator.NewWriteBehindStore(store, gubernator.WriteBehindConfig{
+		FlushInterval: time.Hour,
+		BatchSize:     2,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		req, item := tokenBucketChange(fmt.Sprintf("account:%d", i), 9)
+		require.NoError(t, wb.OnChange(ctx, req, item))
+	}
+	removeReq, _ := tokenBucketChange("account:4", 9)
+	require.NoError(t, wb.Remove(ctx, removeReq.HashKey()))
+	wb.Close()
+
+	// Every change is written with WriteBatch() in batches of at most BatchSize
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	assert.Empty(t, store.calls)
+	var changes int
+	for _, batch := range store.batches {
+		assert.LessOrEqual(t, len(batch), 2)
+		for _, c := range batch {
+			changes++
+			if c.Key == removeReq.HashKey() {
+				assert.Nil(t, c.Item)
+				continue
+			}
+			assert.NotNil(t, c.Item)
+			assert.NotNil(t, c.Req)
+		}
+	}
+	assert.Equal(t, 5, changes)
+}
+
+func TestWriteBehindStoreServer(t *testing.T) {
+	store := newSlowStore()
+	close(store.gate)
+	wb := gubernator.NewWriteBehindStore(gubernator.NewStoreV2Adapter(store), gubernator.WriteBehindConfig{FlushInterval: time.Hour})
+
+	srv := newV1Server(t, "localhost:0", gubernator.Config{StoreV2: wb})
+	client, err := gubernator.DialV1Server(srv.listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	req := &gubernator.RateLimitReq{
+		Name:      "test_write_behind",
+		UniqueKey: "account:1234",
+		Duration:  gubernator.Minute,
+		Limit:     10,
+		Hits:      1,
+	}
+	for i := 0; i < 5; i++ {
+		resp, err := client.GetRateLimits(context.Background(), &gubernator.GetRateLimitsReq{
+			Requests: []*gubernator.RateLimitReq{req},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "", resp.Responses[0].Error)
+	}
+
+	require.NoError(t, srv.Close())
+	wb.Close()
+
+	// Every hit to the same rate limit is coalesced into a single write
+	assert.Equal(t, []string{"Get:" + req.HashKey(), "OnChange:" + req.HashKey()}, store.Changes())
+	assert.Equal(t, int64(5), store.items[req.HashKey()].Value.(*gubernator.TokenBucketItem).Remaining)
+}